@@ -0,0 +1,27 @@
+package ginka_ecs_go
+
+import "context"
+
+// Actor identifies who made a change and why. It travels in the context
+// given to TxContext and is read by TxObservers such as audit sinks.
+type Actor struct {
+	// Id names the user, service or system making the change.
+	Id string `json:"id"`
+	// Reason is free text, such as a request path or a ticket number.
+	Reason string `json:"reason,omitempty"`
+	// Source is where the change came from, such as a client address.
+	Source string `json:"source,omitempty"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a context carrying actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by ctx, if any.
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
@@ -0,0 +1,193 @@
+// Package audit records who changed which component, when, and from what
+// to what.
+//
+// An Auditor is a ginka_ecs_go.TxObserver. Transactions run with
+// ginka_ecs_go.TxContext, and entities created with ginka_ecs_go.Spawn,
+// under the context returned by Auditor.Context produce one Record per
+// changed component of the audited types, carrying
+// the versions before and after, a JSON merge patch of the payload and the
+// ginka_ecs_go.Actor found in the context. The records of a transaction are
+// handed to a Sink together; FileSink appends them to a JSON-lines file.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// Operations recorded in Record.Op.
+const (
+	OpAdd    = "add"
+	OpUpdate = "update"
+	OpRemove = "remove"
+)
+
+// Record is one audited component change.
+type Record struct {
+	Time          time.Time                  `json:"time"`
+	EntityId      string                     `json:"entity_id"`
+	EntityType    ginka_ecs_go.EntityType    `json:"entity_type"`
+	ComponentType ginka_ecs_go.ComponentType `json:"component_type"`
+	// Component is the registered name of ComponentType, if any.
+	Component  string `json:"component,omitempty"`
+	Op         string `json:"op"`
+	OldVersion uint64 `json:"old_version"`
+	NewVersion uint64 `json:"new_version"`
+	// Diff is a JSON merge patch from the old payload to the new one. It is
+	// the whole new payload for OpAdd and null for OpRemove.
+	Diff  json.RawMessage    `json:"diff"`
+	Actor ginka_ecs_go.Actor `json:"actor"`
+	// Error is the error the transaction returned. Its changes stayed
+	// applied, so they are audited anyway.
+	Error string `json:"error,omitempty"`
+}
+
+// Sink stores audit records.
+type Sink interface {
+	// Write stores the records of one transaction.
+	Write(ctx context.Context, records []Record) error
+}
+
+// Auditor turns transaction changes of selected component types into
+// records for a Sink. Only ginka_ecs_go.TxContext transactions and
+// ginka_ecs_go.Spawn calls under Context are seen; plain Tx calls, the
+// GetForUpdate helpers and Add outside a transaction are not audited, so
+// code changing audited types must use those two. It is safe for
+// concurrent use.
+type Auditor struct {
+	sink     Sink
+	registry *ginka_ecs_go.ComponentRegistry
+	types    map[ginka_ecs_go.ComponentType]struct{}
+
+	mu     sync.Mutex
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewAuditor creates an Auditor writing changes of the given DataComponent
+// types to sink. A nil reg uses ginka_ecs_go.DefaultComponentRegistry.
+func NewAuditor(sink Sink, reg *ginka_ecs_go.ComponentRegistry, types ...ginka_ecs_go.ComponentType) *Auditor {
+	if reg == nil {
+		reg = ginka_ecs_go.DefaultComponentRegistry
+	}
+	a := &Auditor{
+		sink:     sink,
+		registry: reg,
+		types:    make(map[ginka_ecs_go.ComponentType]struct{}, len(types)),
+		now:      time.Now,
+	}
+	for _, t := range types {
+		a.types[t] = struct{}{}
+	}
+	return a
+}
+
+// SetLogger sets the logger sink failures are reported to.
+// Nil uses ginka_ecs_go.DefaultLogger.
+func (a *Auditor) SetLogger(l *slog.Logger) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.logger = l
+}
+
+// SetClock replaces the clock stamping records. Nil uses time.Now.
+func (a *Auditor) SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.now = now
+}
+
+// Context returns a context under which TxContext transactions are audited.
+// Pair it with ginka_ecs_go.WithActor to record who made the changes.
+func (a *Auditor) Context(ctx context.Context) context.Context {
+	return ginka_ecs_go.WithTxObserver(ctx, a.registry, a)
+}
+
+// ObserveTx writes a record for every audited component in change.
+// Sink failures are logged, since the transaction has already happened.
+func (a *Auditor) ObserveTx(ctx context.Context, change *ginka_ecs_go.TxChange) {
+	a.mu.Lock()
+	now, logger := a.now, a.logger
+	a.mu.Unlock()
+	if logger == nil {
+		logger = ginka_ecs_go.DefaultLogger()
+	}
+
+	actor, _ := ginka_ecs_go.ActorFromContext(ctx)
+	var records []Record
+	for _, cc := range change.Components {
+		if _, ok := a.types[cc.Type]; !ok {
+			continue
+		}
+		rec, err := a.record(change, cc)
+		if err != nil {
+			logger.ErrorContext(ctx, "audit diff failed",
+				slog.String(ginka_ecs_go.LogKeyEntityId, change.EntityId),
+				ginka_ecs_go.LogComponentType(change.Registry, cc.Type),
+				slog.Any("error", err))
+			continue
+		}
+		rec.Time = now()
+		rec.Actor = actor
+		if change.Err != nil {
+			rec.Error = change.Err.Error()
+		}
+		records = append(records, rec)
+	}
+	if len(records) == 0 {
+		return
+	}
+	if err := a.sink.Write(ctx, records); err != nil {
+		logger.ErrorContext(ctx, "audit write failed",
+			slog.String(ginka_ecs_go.LogKeyEntityId, change.EntityId),
+			slog.Int("records", len(records)),
+			slog.Any("error", err))
+	}
+}
+
+func (a *Auditor) record(change *ginka_ecs_go.TxChange, cc ginka_ecs_go.ComponentChange) (Record, error) {
+	rec := Record{
+		EntityId:      change.EntityId,
+		EntityType:    change.EntityType,
+		ComponentType: cc.Type,
+	}
+	if spec, ok := change.Registry.Lookup(cc.Type); ok {
+		rec.Component = spec.Name
+	}
+	var before, after []byte
+	var err error
+	if cc.Before != nil {
+		rec.OldVersion = cc.Before.Version
+		if before, err = cc.Before.JSON(change.Registry); err != nil {
+			return rec, err
+		}
+	}
+	if cc.After != nil {
+		rec.NewVersion = cc.After.Version
+		if after, err = cc.After.JSON(change.Registry); err != nil {
+			return rec, err
+		}
+	}
+	switch {
+	case cc.Added():
+		rec.Op, rec.Diff = OpAdd, after
+	case cc.Removed():
+		rec.Op, rec.Diff = OpRemove, json.RawMessage("null")
+	default:
+		rec.Op = OpUpdate
+		if rec.Diff, err = ginka_ecs_go.CreateMergePatch(before, after); err != nil {
+			return rec, err
+		}
+	}
+	return rec, nil
+}
+
+var _ ginka_ecs_go.TxObserver = (*Auditor)(nil)
@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+	"github.com/Shigure42/ginka-ecs-go/ecstest"
+)
+
+const (
+	testWalletType ginka_ecs_go.ComponentType = 1
+	testBadgeType  ginka_ecs_go.ComponentType = 2
+)
+
+type testWallet struct {
+	ginka_ecs_go.DataComponentCore
+	Gold int64 `json:"gold"`
+}
+
+func (c *testWallet) StorageKey() string {
+	return "wallet"
+}
+
+func newTestWallet(gold int64) *testWallet {
+	return &testWallet{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testWalletType), Gold: gold}
+}
+
+type testBadge struct {
+	ginka_ecs_go.DataComponentCore
+	Title string `json:"title"`
+}
+
+func (c *testBadge) StorageKey() string {
+	return "badge"
+}
+
+// newTestWorld builds a world registering wallets, stored with
+// BinaryCodec, and badges.
+func newTestWorld(t *testing.T) *ecstest.World {
+	return ecstest.NewWorld(t).Components(
+		ginka_ecs_go.ComponentSpec{Name: "wallet", Type: testWalletType, New: func() ginka_ecs_go.Component { return newTestWallet(0) }, Codec: ginka_ecs_go.BinaryCodec},
+		ginka_ecs_go.ComponentSpec{Name: "badge", Type: testBadgeType, New: func() ginka_ecs_go.Component {
+			return &testBadge{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testBadgeType)}
+		}},
+	).Build()
+}
+
+type failingSink struct{}
+
+func (failingSink) Write(ctx context.Context, records []Record) error {
+	return errors.New("disk full")
+}
+
+func TestAuditor_WritesChangesOfAuditedTypes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := OpenFileSink(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	w := newTestWorld(t)
+	auditor := NewAuditor(sink, w.Registry, testWalletType)
+	stamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	auditor.SetClock(func() time.Time { return stamp })
+
+	ent, _ := w.Entities.Create(context.Background(), "p1", "p1", 1)
+	_ = ent.Add(newTestWallet(10))
+	_ = ent.Add(&testBadge{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testBadgeType)})
+
+	ctx := ginka_ecs_go.WithActor(auditor.Context(context.Background()), ginka_ecs_go.Actor{Id: "gm-7", Reason: "refund #42"})
+	err = ginka_ecs_go.TxContext(ctx, ent, func(ctx context.Context, tx ginka_ecs_go.DataEntity) error {
+		wallet, _ := ginka_ecs_go.GetForUpdate[*testWallet](tx, testWalletType)
+		wallet.Gold = 25
+		badge, _ := ginka_ecs_go.GetForUpdate[*testBadge](tx, testBadgeType)
+		badge.Title = "refunded"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("tx: %v", err)
+	}
+	_ = ginka_ecs_go.TxContext(auditor.Context(context.Background()), ent, func(ctx context.Context, tx ginka_ecs_go.DataEntity) error {
+		tx.RemoveComponent(testWalletType)
+		return nil
+	})
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := sink.Write(context.Background(), []Record{{}}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("write after close err = %v", err)
+	}
+
+	records, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records = %+v", records)
+	}
+	update := records[0]
+	if update.EntityId != "p1" || update.Component != "wallet" || update.Op != OpUpdate ||
+		update.OldVersion != 0 || update.NewVersion != 1 || !update.Time.Equal(stamp) ||
+		update.Actor != (ginka_ecs_go.Actor{Id: "gm-7", Reason: "refund #42"}) {
+		t.Fatalf("update = %+v", update)
+	}
+	if !strings.Contains(string(update.Diff), `"gold":25`) || strings.Contains(string(update.Diff), "Type") {
+		t.Fatalf("diff = %s", update.Diff)
+	}
+	remove := records[1]
+	if remove.Op != OpRemove || remove.OldVersion != 1 || string(remove.Diff) != "null" || remove.Actor.Id != "" {
+		t.Fatalf("remove = %+v", remove)
+	}
+}
+
+func TestAuditor_LogsSinkFailures(t *testing.T) {
+	var logs bytes.Buffer
+	w := newTestWorld(t)
+	auditor := NewAuditor(failingSink{}, w.Registry, testWalletType)
+	auditor.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	ent, _ := w.Entities.Create(context.Background(), "p1", "p1", 1)
+	err := ginka_ecs_go.TxContext(auditor.Context(context.Background()), ent, func(ctx context.Context, tx ginka_ecs_go.DataEntity) error {
+		return tx.Add(newTestWallet(5))
+	})
+	if err != nil {
+		t.Fatalf("tx: %v", err)
+	}
+	if out := logs.String(); !strings.Contains(out, "audit write failed") || !strings.Contains(out, "disk full") {
+		t.Fatalf("logs = %s", out)
+	}
+}
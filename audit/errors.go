@@ -0,0 +1,10 @@
+package audit
+
+import "errors"
+
+var (
+	// ErrClosed indicates a write to a closed sink.
+	ErrClosed = errors.New("audit sink closed")
+	// ErrBadRecord indicates an audit file line that cannot be decoded.
+	ErrBadRecord = errors.New("malformed audit record")
+)
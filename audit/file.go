@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// FileSink appends records to a file, one JSON object per line. Each
+// transaction's records go out in a single write. It is safe for
+// concurrent use.
+type FileSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	sync   bool
+	closed bool
+}
+
+// OpenFileSink opens path for appending, creating it if needed.
+// Existing records are never rewritten.
+func OpenFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit file: %w", err)
+	}
+	return &FileSink{file: f}, nil
+}
+
+// SetSync makes every Write fsync the file before returning.
+func (s *FileSink) SetSync(sync bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sync = sync
+}
+
+// Write appends records to the file.
+func (s *FileSink) Write(ctx context.Context, records []Record) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("encode audit record: %w", err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("write audit file: %w", err)
+	}
+	if s.sync {
+		if err := s.file.Sync(); err != nil {
+			return fmt.Errorf("sync audit file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the file. Later writes fail with ErrClosed.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.file.Close()
+}
+
+// ReadRecords decodes records written by a FileSink.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return records, fmt.Errorf("audit line %d: %v: %w", line, err, ErrBadRecord)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return records, fmt.Errorf("read audit records: %w", err)
+	}
+	return records, nil
+}
+
+// ReadFile decodes every record of the audit file at path.
+func ReadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open audit file: %w", err)
+	}
+	defer f.Close()
+	return ReadRecords(f)
+}
+
+var _ Sink = (*FileSink)(nil)
@@ -0,0 +1,369 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// binaryCodec is a compact positional encoding of the exported fields of a value.
+//
+// Wire format, by kind:
+//   - bool: one byte
+//   - signed integers: zigzag varint
+//   - unsigned integers: uvarint
+//   - float32/float64: 4/8 bytes little-endian IEEE 754
+//   - string, []byte: uvarint length followed by the bytes
+//   - slice, map: uvarint (length+1), 0 for nil; map entries are sorted by encoded key
+//   - array: elements only
+//   - pointer: 0 for nil, 1 followed by the value
+//   - struct: exported fields in declaration order
+//   - encoding.BinaryMarshaler: uvarint length followed by MarshalBinary output
+//
+// Field names are not written, so the encoding depends on the field order of
+// the Go type. Use schema versions to evolve it. Integers decoded into a
+// narrower type that cannot hold them fail instead of being truncated.
+type binaryCodec struct{}
+
+var (
+	errBinaryShortBuffer    = errors.New("binary codec: short buffer")
+	errBinaryTrailingBytes  = errors.New("binary codec: trailing bytes")
+	errBinaryNonPointerDest = errors.New("binary codec: destination must be a non-nil pointer")
+	errBinaryOverflow       = errors.New("binary codec: value overflows destination")
+)
+
+var binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+
+func (binaryCodec) Name() string {
+	return "binary"
+}
+
+func (binaryCodec) Marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("binary codec: nil value")
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, fmt.Errorf("binary codec: nil value")
+	}
+	return appendBinaryValue(nil, rv)
+}
+
+func (binaryCodec) Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errBinaryNonPointerDest
+	}
+	rest, err := readBinaryValue(data, rv.Elem())
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errBinaryTrailingBytes
+	}
+	return nil
+}
+
+func appendBinaryValue(buf []byte, v reflect.Value) ([]byte, error) {
+	if m, ok := binaryMarshalerOf(v); ok {
+		data, err := m.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(data)))
+		return append(buf, data...), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendVarint(buf, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return binary.AppendUvarint(buf, v.Uint()), nil
+	case reflect.Float32:
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v.Float())), nil
+	case reflect.String:
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		return append(buf, v.String()...), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return binary.AppendUvarint(buf, 0), nil
+		}
+		buf = binary.AppendUvarint(buf, uint64(v.Len())+1)
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return append(buf, v.Bytes()...), nil
+		}
+		return appendBinaryElems(buf, v)
+	case reflect.Array:
+		return appendBinaryElems(buf, v)
+	case reflect.Map:
+		return appendBinaryMap(buf, v)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return append(buf, 0), nil
+		}
+		return appendBinaryValue(append(buf, 1), v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		var err error
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if buf, err = appendBinaryValue(buf, v.Field(i)); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, fmt.Errorf("binary codec: unsupported kind %s", v.Kind())
+	}
+}
+
+func appendBinaryElems(buf []byte, v reflect.Value) ([]byte, error) {
+	var err error
+	for i := 0; i < v.Len(); i++ {
+		if buf, err = appendBinaryValue(buf, v.Index(i)); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func appendBinaryMap(buf []byte, v reflect.Value) ([]byte, error) {
+	if v.IsNil() {
+		return binary.AppendUvarint(buf, 0), nil
+	}
+	type entry struct {
+		key []byte
+		val reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := appendBinaryValue(nil, iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{key: key, val: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	buf = binary.AppendUvarint(buf, uint64(len(entries))+1)
+	var err error
+	for _, e := range entries {
+		buf = append(buf, e.key...)
+		if buf, err = appendBinaryValue(buf, e.val); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func readBinaryValue(data []byte, v reflect.Value) ([]byte, error) {
+	if u, ok := binaryUnmarshalerOf(v); ok {
+		n, rest, err := readBinaryUvarint(data)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(rest)) < n {
+			return nil, errBinaryShortBuffer
+		}
+		if err := u.UnmarshalBinary(rest[:n]); err != nil {
+			return nil, err
+		}
+		return rest[n:], nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if len(data) < 1 {
+			return nil, errBinaryShortBuffer
+		}
+		v.SetBool(data[0] != 0)
+		return data[1:], nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, errBinaryShortBuffer
+		}
+		if v.OverflowInt(x) {
+			return nil, fmt.Errorf("%w: %d into %s", errBinaryOverflow, x, v.Type())
+		}
+		v.SetInt(x)
+		return data[n:], nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, rest, err := readBinaryUvarint(data)
+		if err != nil {
+			return nil, err
+		}
+		if v.OverflowUint(x) {
+			return nil, fmt.Errorf("%w: %d into %s", errBinaryOverflow, x, v.Type())
+		}
+		v.SetUint(x)
+		return rest, nil
+	case reflect.Float32:
+		if len(data) < 4 {
+			return nil, errBinaryShortBuffer
+		}
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(data))))
+		return data[4:], nil
+	case reflect.Float64:
+		if len(data) < 8 {
+			return nil, errBinaryShortBuffer
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)))
+		return data[8:], nil
+	case reflect.String:
+		n, rest, err := readBinaryUvarint(data)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(rest)) < n {
+			return nil, errBinaryShortBuffer
+		}
+		v.SetString(string(rest[:n]))
+		return rest[n:], nil
+	case reflect.Slice:
+		n, rest, err := readBinaryUvarint(data)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return rest, nil
+		}
+		n--
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if uint64(len(rest)) < n {
+				return nil, errBinaryShortBuffer
+			}
+			v.SetBytes(append([]byte(nil), rest[:n]...))
+			return rest[n:], nil
+		}
+		if n > uint64(len(rest)) {
+			return nil, errBinaryShortBuffer
+		}
+		v.Set(reflect.MakeSlice(v.Type(), int(n), int(n)))
+		return readBinaryElems(rest, v)
+	case reflect.Array:
+		return readBinaryElems(data, v)
+	case reflect.Map:
+		n, rest, err := readBinaryUvarint(data)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return rest, nil
+		}
+		n--
+		if n > uint64(len(rest)) {
+			return nil, errBinaryShortBuffer
+		}
+		m := reflect.MakeMapWithSize(v.Type(), int(n))
+		for i := uint64(0); i < n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			if rest, err = readBinaryValue(rest, key); err != nil {
+				return nil, err
+			}
+			val := reflect.New(v.Type().Elem()).Elem()
+			if rest, err = readBinaryValue(rest, val); err != nil {
+				return nil, err
+			}
+			m.SetMapIndex(key, val)
+		}
+		v.Set(m)
+		return rest, nil
+	case reflect.Pointer:
+		if len(data) < 1 {
+			return nil, errBinaryShortBuffer
+		}
+		if data[0] == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return data[1:], nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return readBinaryValue(data[1:], v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		var err error
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if data, err = readBinaryValue(data, v.Field(i)); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("binary codec: unsupported kind %s", v.Kind())
+	}
+}
+
+func readBinaryElems(data []byte, v reflect.Value) ([]byte, error) {
+	var err error
+	for i := 0; i < v.Len(); i++ {
+		if data, err = readBinaryValue(data, v.Index(i)); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func readBinaryUvarint(data []byte) (uint64, []byte, error) {
+	x, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, errBinaryShortBuffer
+	}
+	return x, data[n:], nil
+}
+
+func binaryMarshalerOf(v reflect.Value) (encoding.BinaryMarshaler, bool) {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		return nil, false
+	}
+	if v.Type().Implements(binaryMarshalerType) {
+		m, ok := v.Interface().(encoding.BinaryMarshaler)
+		return m, ok
+	}
+	if !reflect.PointerTo(v.Type()).Implements(binaryMarshalerType) {
+		return nil, false
+	}
+	if !v.CanAddr() {
+		tmp := reflect.New(v.Type())
+		tmp.Elem().Set(v)
+		v = tmp.Elem()
+	}
+	m, ok := v.Addr().Interface().(encoding.BinaryMarshaler)
+	return m, ok
+}
+
+func binaryUnmarshalerOf(v reflect.Value) (encoding.BinaryUnmarshaler, bool) {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface || !v.CanAddr() {
+		return nil, false
+	}
+	if reflect.PointerTo(v.Type()).Implements(binaryUnmarshalerType) {
+		u, ok := v.Addr().Interface().(encoding.BinaryUnmarshaler)
+		return u, ok
+	}
+	return nil, false
+}
@@ -0,0 +1,91 @@
+package ginka_ecs_go
+
+import "sync/atomic"
+
+// Tick is a process-wide, monotonically increasing change counter.
+// Every recorded component change takes the next tick, so "changed since t"
+// is a plain comparison and needs no world clock.
+type Tick uint64
+
+var changeTick atomic.Uint64
+
+// CurrentTick returns the latest tick issued.
+func CurrentTick() Tick {
+	return Tick(changeTick.Load())
+}
+
+func nextTick() Tick {
+	return Tick(changeTick.Add(1))
+}
+
+// ComponentTicks records when a component was attached and last changed.
+// Change ticks are independent of the persistence dirty set: ClearDirty does
+// not reset them.
+type ComponentTicks struct {
+	Added   Tick
+	Changed Tick
+}
+
+// ChangeTracker is implemented by entities that record component change ticks.
+// EntityCore and DataEntityCore implement it; Add records both ticks,
+// GetForUpdate and MarkChanged record a change.
+type ChangeTracker interface {
+	// ComponentTicks returns the ticks of component type t.
+	ComponentTicks(t ComponentType) (ComponentTicks, bool)
+	// MarkChanged records a change of component type t without touching the dirty set.
+	// Returns false if the entity has no such component.
+	MarkChanged(t ComponentType) bool
+}
+
+// StateTracker is implemented by entities that record when their state last
+// changed. EntityCore and DataEntityCore implement it: adding or removing a
+// component, recording a component change, and changing tags or the enabled
+// flag all take a new tick. Direct writes to a component's fields, tags or
+// enabled flag are only seen after GetForUpdate or MarkChanged.
+type StateTracker interface {
+	// StateTick returns the tick of the entity's latest recorded change.
+	StateTick() Tick
+}
+
+// ChangeCursor remembers the tick at which a system last ran.
+// The zero value has never run, so the first run sees every component.
+type ChangeCursor struct {
+	last atomic.Uint64
+}
+
+// Begin returns the tick of the previous run and records the current tick
+// as the start of this run. Changes that race with the run may be observed
+// both now and on the next run, never by neither.
+func (c *ChangeCursor) Begin() Tick {
+	return Tick(c.last.Swap(changeTick.Load()))
+}
+
+// Last returns the tick recorded by the most recent Begin.
+func (c *ChangeCursor) Last() Tick {
+	return Tick(c.last.Load())
+}
+
+// Changed matches entities whose component of type t changed after since.
+// Adding a component counts as a change.
+func Changed(t ComponentType, since Tick) Filter {
+	return func(ent Entity) bool {
+		ticks, ok := componentTicks(ent, t)
+		return ok && ticks.Changed > since
+	}
+}
+
+// Added matches entities whose component of type t was attached after since.
+func Added(t ComponentType, since Tick) Filter {
+	return func(ent Entity) bool {
+		ticks, ok := componentTicks(ent, t)
+		return ok && ticks.Added > since
+	}
+}
+
+func componentTicks(ent Entity, t ComponentType) (ComponentTicks, bool) {
+	tracker, ok := ent.(ChangeTracker)
+	if !ok {
+		return ComponentTicks{}, false
+	}
+	return tracker.ComponentTicks(t)
+}
@@ -0,0 +1,89 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestChangeTicks_IndependentOfDirty(t *testing.T) {
+	ent := NewDataEntityCore("1", "entity", 1)
+	before := CurrentTick()
+	if err := ent.Add(newTestDataComponent()); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	ticks, ok := ent.ComponentTicks(testDataComponentType)
+	if !ok || ticks.Added <= before || ticks.Changed != ticks.Added {
+		t.Fatalf("unexpected ticks after add: %+v", ticks)
+	}
+
+	if _, ok := ent.GetForUpdate(testDataComponentType); !ok {
+		t.Fatalf("get for update failed")
+	}
+	ent.ClearDirty()
+	updated, _ := ent.ComponentTicks(testDataComponentType)
+	if updated.Changed <= ticks.Changed || updated.Added != ticks.Added {
+		t.Fatalf("unexpected ticks after update: %+v", updated)
+	}
+
+	ent.RemoveComponent(testDataComponentType)
+	if _, ok := ent.ComponentTicks(testDataComponentType); ok {
+		t.Fatalf("expected ticks removed with component")
+	}
+}
+
+func TestChangedFilter_RelativeToCursor(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	for _, id := range []string{"a", "b", "c"} {
+		ent, err := m.Create(ctx, id, id, 1)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := ent.Add(newTestDataComponent()); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	var cursor ChangeCursor
+	run := func() []string {
+		since := cursor.Begin()
+		var got []string
+		err := ForEachMatching[DataEntity](ctx, m, []Filter{Changed(testDataComponentType, since)}, func(ent DataEntity) error {
+			got = append(got, ent.Id())
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("for each: %v", err)
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	if got := run(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Fatalf("first run = %v", got)
+	}
+	if got := run(); got != nil {
+		t.Fatalf("second run = %v", got)
+	}
+	if _, ok := GetForUpdate[*testDataComponent](m.MustGet("b"), testDataComponentType); !ok {
+		t.Fatalf("get for update failed")
+	}
+	if got := run(); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("third run = %v", got)
+	}
+
+	since := CurrentTick()
+	if err := m.MustGet("c").Add(newTestLabelComponent("x")); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	var added []string
+	_ = ForEachMatching[DataEntity](ctx, m, []Filter{Added(testLabelComponentType, since)}, func(ent DataEntity) error {
+		added = append(added, ent.Id())
+		return nil
+	})
+	if !reflect.DeepEqual(added, []string{"c"}) {
+		t.Fatalf("added = %v", added)
+	}
+}
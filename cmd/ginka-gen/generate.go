@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"text/template"
+)
+
+const defaultRegistry = "ginka_ecs_go.DefaultComponentRegistry"
+
+var codecExprs = map[string]string{
+	"json":   "ginka_ecs_go.JSONCodec",
+	"gob":    "ginka_ecs_go.GobCodec",
+	"binary": "ginka_ecs_go.BinaryCodec",
+}
+
+type templateData struct {
+	Package    string
+	Registry   string
+	Init       bool
+	Maps       bool
+	Slices     bool
+	Components []*component
+}
+
+// generate renders the generated file for pkg and gofmts it.
+func generate(pkg *pkgInfo, registry string) ([]byte, error) {
+	data := templateData{
+		Package:    pkg.Name,
+		Registry:   registry,
+		Init:       registry == defaultRegistry,
+		Components: pkg.Components,
+	}
+	for _, c := range pkg.Components {
+		data.Maps = data.Maps || len(c.Maps) > 0
+		data.Slices = data.Slices || len(c.Slices) > 0
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated source: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+var fileTemplate = template.Must(template.New("file").Funcs(template.FuncMap{
+	"codec": func(name string) string { return codecExprs[name] },
+}).Parse(`// Code generated by ginka-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .Maps}}
+	"maps"
+{{- end}}
+{{- if .Slices}}
+	"slices"
+{{- end}}
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+const (
+{{- range .Components}}
+	ComponentType{{.Base}} ginka_ecs_go.ComponentType = {{.Id}}
+{{- end}}
+)
+
+// registerComponents registers the generated component specs in reg.
+func registerComponents(reg *ginka_ecs_go.ComponentRegistry) error {
+	specs := []ginka_ecs_go.ComponentSpec{
+{{- range .Components}}
+		{
+			Name: {{printf "%q" .Name}},
+			Type: ComponentType{{.Base}},
+			New:  func() ginka_ecs_go.Component { return new{{.Struct}}() },
+{{- if .Codec}}
+			Codec: {{codec .Codec}},
+{{- end}}
+{{- if .Schema}}
+			SchemaVersion: {{.Schema}},
+{{- end}}
+		},
+{{- end}}
+	}
+	for _, spec := range specs {
+		if err := reg.Register(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+{{if .Init}}
+func init() {
+	if err := registerComponents({{.Registry}}); err != nil {
+		panic(err)
+	}
+}
+{{end}}
+{{- range .Components}}
+{{- $c := .}}
+func new{{.Struct}}() *{{.Struct}} {
+	c := &{{.Struct}}{
+		{{.Core}}: ginka_ecs_go.New{{.Core}}(ComponentType{{.Base}}),
+	}
+{{- if .Defaults}}
+	c.defaults()
+{{- end}}
+	return c
+}
+
+// New{{.Struct}} creates a {{.Name}} component.
+func New{{.Struct}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}} {{$p.Type}}{{end}}) *{{.Struct}} {
+	c := new{{.Struct}}()
+{{- range .Params}}
+	c.{{.Field}} = {{.Name}}
+{{- end}}
+	return c
+}
+{{if eq .Core "DataComponentCore"}}
+func (c *{{.Struct}}) StorageKey() string {
+	return {{printf "%q" .Key}}
+}
+
+func (c *{{.Struct}}) Marshal() ([]byte, error) {
+	return {{$.Registry}}.EncodeComponent(c)
+}
+
+func (c *{{.Struct}}) Unmarshal(data []byte) error {
+	return {{$.Registry}}.DecodeInto(c, data)
+}
+{{end}}
+func (c *{{.Struct}}) Clone() ginka_ecs_go.Component {
+	out := *c
+	out.SetTags(c.Tags()...)
+{{- if .Tracked}}
+	out.ClearChangedFields()
+{{- end}}
+{{- range .Maps}}
+	out.{{.}} = maps.Clone(c.{{.}})
+{{- end}}
+{{- range .Slices}}
+	out.{{.}} = slices.Clone(c.{{.}})
+{{- end}}
+	return &out
+}
+
+// Get{{.Base}} returns the {{.Name}} component of ent.
+func Get{{.Base}}(ent ginka_ecs_go.Entity) (*{{.Struct}}, bool) {
+	return ginka_ecs_go.Get[*{{.Struct}}](ent, ComponentType{{.Base}})
+}
+{{if eq .Core "DataComponentCore"}}
+// Get{{.Base}}ForUpdate returns the {{.Name}} component of ent and marks it dirty.
+func Get{{.Base}}ForUpdate(ent ginka_ecs_go.DataEntity) (*{{.Struct}}, bool, error) {
+	return ginka_ecs_go.GetForUpdateE[*{{.Struct}}](ent, ComponentType{{.Base}})
+}
+{{end}}
+{{- end}}
+var (
+{{- range .Components}}
+{{- if eq .Core "DataComponentCore"}}
+	_ ginka_ecs_go.DataComponent = (*{{.Struct}})(nil)
+{{- else}}
+	_ ginka_ecs_go.Component = (*{{.Struct}})(nil)
+{{- end}}
+	_ ginka_ecs_go.Cloner = (*{{.Struct}})(nil)
+{{- end}}
+)
+`))
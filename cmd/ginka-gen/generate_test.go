@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSource = `package game
+
+import ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+
+//ginka:component name=bag id=7 key=inventory schema=3 codec=binary new=Owner,Items
+type BagComponent struct {
+	ginka_ecs_go.DataComponentCore
+	ginka_ecs_go.FieldTracker
+	Owner string         ` + "`json:\"owner\"`" + `
+	Items map[string]int ` + "`json:\"items\"`" + `
+	Path  []int          ` + "`json:\"path\"`" + `
+	Slots [4]int         ` + "`json:\"slots\"`" + `
+	Bonus Bonus          ` + "`json:\"bonus\"`" + `
+	Pos   Point          ` + "`json:\"pos\"`" + `
+	Since time.Time      ` + "`json:\"since\"`" + `
+}
+
+type Bonus map[string]int64
+
+type Point struct{ X, Y int }
+
+// Marker is a runtime-only component.
+//
+//ginka:component name=marker id=3
+type Marker struct {
+	ginka_ecs_go.ComponentCore
+}
+
+func (m *Marker) defaults() {}
+`
+
+func TestGenerate_ComponentBoilerplate(t *testing.T) {
+	pkg, err := parseSources(map[string][]byte{"game.go": []byte(testSource)})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(pkg.Components) != 2 || pkg.Components[0].Struct != "Marker" {
+		t.Fatalf("components should be sorted by id: %+v", pkg.Components)
+	}
+	src, err := generate(pkg, defaultRegistry)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "gen.go", src, 0); err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, src)
+	}
+
+	// Collapse gofmt alignment so expectations don't depend on it.
+	out := strings.Join(strings.Fields(string(src)), " ")
+	for _, want := range []string{
+		"ComponentTypeMarker ginka_ecs_go.ComponentType = 3",
+		"ComponentTypeBag ginka_ecs_go.ComponentType = 7",
+		"func NewBagComponent(owner string, items map[string]int) *BagComponent",
+		`return "inventory"`,
+		"Codec: ginka_ecs_go.BinaryCodec",
+		"SchemaVersion: 3",
+		"out.Items = maps.Clone(c.Items)",
+		"out.Path = slices.Clone(c.Path)",
+		"out.Bonus = maps.Clone(c.Bonus)",
+		"out.ClearChangedFields()",
+		"func GetBagForUpdate(ent ginka_ecs_go.DataEntity) (*BagComponent, bool, error)",
+		"registerComponents(ginka_ecs_go.DefaultComponentRegistry)",
+		"c.defaults()",
+		"_ ginka_ecs_go.Component = (*Marker)(nil)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generated source missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"out.Slots =", "out.Pos =", "out.Since =", "func (c *Marker) StorageKey", "GetMarkerForUpdate"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("generated source should not contain %q", unwanted)
+		}
+	}
+}
+
+func TestParseSources_Errors(t *testing.T) {
+	cases := map[string]string{
+		"duplicate id": `package game
+//ginka:component name=a id=1
+type A struct{ ginka_ecs_go.DataComponentCore }
+//ginka:component name=b id=1
+type B struct{ ginka_ecs_go.DataComponentCore }
+`,
+		"missing id": `package game
+//ginka:component name=a
+type A struct{ ginka_ecs_go.DataComponentCore }
+`,
+		"no core": `package game
+//ginka:component name=a id=1
+type A struct{ X int }
+`,
+		"unknown field": `package game
+//ginka:component name=a id=1 new=Y
+type A struct{ ginka_ecs_go.DataComponentCore }
+`,
+		"unknown codec": `package game
+//ginka:component name=a id=1 codec=xml
+type A struct{ ginka_ecs_go.DataComponentCore }
+`,
+		"no directives": `package game
+type A struct{}
+`,
+		"pointer field": `package game
+//ginka:component name=a id=1
+type A struct {
+	ginka_ecs_go.DataComponentCore
+	Owner *Player
+}
+type Player struct{ Name string }
+`,
+		"nested slice": `package game
+//ginka:component name=a id=1
+type A struct {
+	ginka_ecs_go.DataComponentCore
+	Grid [][]int
+}
+`,
+		"map of slices": `package game
+//ginka:component name=a id=1
+type A struct {
+	ginka_ecs_go.DataComponentCore
+	Loot map[string][]int
+}
+`,
+		"unexported interface": `package game
+//ginka:component name=a id=1
+type A struct {
+	ginka_ecs_go.DataComponentCore
+	cache any
+}
+`,
+		"struct with slice": `package game
+//ginka:component name=a id=1
+type A struct {
+	ginka_ecs_go.DataComponentCore
+	Stats Stats
+}
+type Stats struct{ History []int }
+`,
+		"foreign type": `package game
+//ginka:component name=a id=1
+type A struct {
+	ginka_ecs_go.DataComponentCore
+	Buf bytes.Buffer
+}
+`,
+	}
+	for name, src := range cases {
+		if _, err := parseSources(map[string][]byte{"a.go": []byte(src)}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestGenerate_DemoIsUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "examples", "server_demo")
+	pkg, err := parsePackage(dir, "components_gen.go")
+	if err != nil {
+		t.Fatalf("parse demo: %v", err)
+	}
+	src, err := generate(pkg, "componentRegistry")
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	current, err := os.ReadFile(filepath.Join(dir, "components_gen.go"))
+	if err != nil {
+		t.Fatalf("read generated file: %v", err)
+	}
+	if !bytes.Equal(src, current) {
+		t.Fatalf("examples/server_demo/components_gen.go is stale; run go generate ./examples/server_demo")
+	}
+}
@@ -0,0 +1,66 @@
+// Command ginka-gen generates component boilerplate from annotated structs.
+//
+// A component is declared with a directive in the struct's doc comment:
+//
+//	//ginka:component name=wallet id=2 new=Gold
+//	type WalletComponent struct {
+//		ginka_ecs_go.DataComponentCore
+//		Gold int64 `json:"gold"`
+//	}
+//
+// Directive options:
+//
+//	name    registry name (required)
+//	id      ComponentType value (required, unique)
+//	key     storage key (default: name)
+//	schema  schema version (default: 1)
+//	codec   json, gob or binary (default: the registry default)
+//	new     comma-separated fields taken as constructor parameters
+//
+// For each component the tool generates a ComponentType constant named
+// ComponentType<Base>, where Base is the struct name without a "Component"
+// suffix, a New<Struct> constructor, StorageKey, Marshal/Unmarshal through
+// the registry, Clone, and Get<Base>/Get<Base>ForUpdate accessors. It also
+// generates registerComponents, which registers every spec in a registry.
+// Clone deep-copies: fields must be values, arrays or structs of values, or
+// maps and slices of values, which are cloned. Other fields, such as
+// pointers, interfaces or nested slices, would be shared by the clones and
+// make generation fail.
+// If the struct has a defaults method, the constructor calls it before
+// assigning parameters.
+//
+// Usage, typically from a go:generate directive:
+//
+//	ginka-gen [-dir .] [-out components_gen.go] [-registry componentRegistry]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "package directory to scan")
+	out := flag.String("out", "components_gen.go", "output file name, relative to -dir")
+	registry := flag.String("registry", defaultRegistry, "registry expression used by Marshal and Unmarshal")
+	flag.Parse()
+
+	if err := run(*dir, *out, *registry); err != nil {
+		fmt.Fprintln(os.Stderr, "ginka-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, out string, registry string) error {
+	pkg, err := parsePackage(dir, out)
+	if err != nil {
+		return err
+	}
+	src, err := generate(pkg, registry)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, out), src, 0o644)
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const directive = "//ginka:component"
+
+type component struct {
+	Struct   string
+	Base     string
+	Name     string
+	Id       int
+	Key      string
+	Schema   uint32
+	Codec    string
+	Core     string
+	Params   []param
+	Defaults bool
+	Tracked  bool
+	Maps     []string
+	Slices   []string
+
+	fields []field
+}
+
+// field is a struct field other than the embedded component core and
+// FieldTracker, checked by resolveClone.
+type field struct {
+	Name string
+	Type ast.Expr
+}
+
+type param struct {
+	Field string
+	Name  string
+	Type  string
+}
+
+type pkgInfo struct {
+	Name       string
+	Components []*component
+}
+
+// parsePackage scans the non-test Go files in dir, skipping the output file.
+func parsePackage(dir string, out string) (*pkgInfo, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sources := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		base := filepath.Base(path)
+		if strings.HasSuffix(base, "_test.go") || base == filepath.Base(out) {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sources[base] = data
+	}
+	return parseSources(sources)
+}
+
+func parseSources(sources map[string][]byte) (*pkgInfo, error) {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	pkg := &pkgInfo{}
+	defaults := make(map[string]bool)
+	types := make(map[string]ast.Expr)
+	for _, name := range names {
+		file, err := parser.ParseFile(fset, name, sources[name], parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.Name == "" {
+			pkg.Name = file.Name.Name
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Name.Name == "defaults" && decl.Recv != nil && len(decl.Recv.List) == 1 {
+					defaults[receiverName(decl.Recv.List[0].Type)] = true
+				}
+			case *ast.GenDecl:
+				if decl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					ts := spec.(*ast.TypeSpec)
+					types[ts.Name.Name] = ts.Type
+					doc := ts.Doc
+					if doc == nil && len(decl.Specs) == 1 {
+						doc = decl.Doc
+					}
+					opts, ok := findDirective(doc)
+					if !ok {
+						continue
+					}
+					c, err := parseComponent(fset, ts, opts)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", fset.Position(ts.Pos()), err)
+					}
+					pkg.Components = append(pkg.Components, c)
+				}
+			}
+		}
+	}
+	if len(pkg.Components) == 0 {
+		return nil, fmt.Errorf("no %s directives found", directive)
+	}
+
+	ids := make(map[int]string)
+	byName := make(map[string]string)
+	for _, c := range pkg.Components {
+		c.Defaults = defaults[c.Struct]
+		if err := resolveClone(c, types); err != nil {
+			return nil, err
+		}
+		if other, ok := ids[c.Id]; ok {
+			return nil, fmt.Errorf("%s: id %d already used by %s", c.Struct, c.Id, other)
+		}
+		if other, ok := byName[c.Name]; ok {
+			return nil, fmt.Errorf("%s: name %q already used by %s", c.Struct, c.Name, other)
+		}
+		ids[c.Id] = c.Struct
+		byName[c.Name] = c.Struct
+	}
+	sort.Slice(pkg.Components, func(i, j int) bool {
+		return pkg.Components[i].Id < pkg.Components[j].Id
+	})
+	return pkg, nil
+}
+
+func findDirective(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, c := range doc.List {
+		if c.Text == directive {
+			return "", true
+		}
+		if strings.HasPrefix(c.Text, directive+" ") {
+			return strings.TrimSpace(strings.TrimPrefix(c.Text, directive)), true
+		}
+	}
+	return "", false
+}
+
+func parseComponent(fset *token.FileSet, ts *ast.TypeSpec, opts string) (*component, error) {
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s must annotate a struct", ts.Name.Name, directive)
+	}
+	c := &component{
+		Struct: ts.Name.Name,
+		Base:   strings.TrimSuffix(ts.Name.Name, "Component"),
+		Id:     -1,
+	}
+	if c.Base == "" {
+		c.Base = ts.Name.Name
+	}
+
+	var newFields []string
+	for _, opt := range strings.Fields(opts) {
+		key, value, ok := strings.Cut(opt, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%s: malformed option %q", c.Struct, opt)
+		}
+		switch key {
+		case "name":
+			c.Name = value
+		case "id":
+			id, err := strconv.Atoi(value)
+			if err != nil || id < 0 {
+				return nil, fmt.Errorf("%s: invalid id %q", c.Struct, value)
+			}
+			c.Id = id
+		case "key":
+			c.Key = value
+		case "schema":
+			schema, err := strconv.ParseUint(value, 10, 32)
+			if err != nil || schema == 0 {
+				return nil, fmt.Errorf("%s: invalid schema %q", c.Struct, value)
+			}
+			c.Schema = uint32(schema)
+		case "codec":
+			if _, ok := codecExprs[value]; !ok {
+				return nil, fmt.Errorf("%s: unknown codec %q", c.Struct, value)
+			}
+			c.Codec = value
+		case "new":
+			newFields = strings.Split(value, ",")
+		default:
+			return nil, fmt.Errorf("%s: unknown option %q", c.Struct, key)
+		}
+	}
+	if c.Name == "" {
+		return nil, fmt.Errorf("%s: missing name", c.Struct)
+	}
+	if c.Id < 0 {
+		return nil, fmt.Errorf("%s: missing id", c.Struct)
+	}
+	if c.Key == "" {
+		c.Key = c.Name
+	}
+
+	fields := make(map[string]ast.Expr)
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			switch name := embeddedName(f.Type); name {
+			case "DataComponentCore":
+				c.Core = "DataComponentCore"
+			case "ComponentCore":
+				if c.Core == "" {
+					c.Core = "ComponentCore"
+				}
+			case "FieldTracker":
+				c.Tracked = true
+			default:
+				c.fields = append(c.fields, field{Name: name, Type: f.Type})
+			}
+			continue
+		}
+		for _, name := range f.Names {
+			c.fields = append(c.fields, field{Name: name.Name, Type: f.Type})
+			if name.IsExported() {
+				fields[name.Name] = f.Type
+			}
+		}
+	}
+	if c.Core == "" {
+		return nil, fmt.Errorf("%s: must embed DataComponentCore or ComponentCore", c.Struct)
+	}
+
+	for _, field := range newFields {
+		typ, ok := fields[field]
+		if !ok {
+			return nil, fmt.Errorf("%s: constructor field %q not found", c.Struct, field)
+		}
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, typ); err != nil {
+			return nil, err
+		}
+		c.Params = append(c.Params, param{Field: field, Name: paramName(field), Type: buf.String()})
+	}
+	return c, nil
+}
+
+// valueSelectors are types from other packages that Clone may copy by
+// value. time.Time shares its *Location, which is never mutated.
+var valueSelectors = map[string]bool{
+	"time.Time":                  true,
+	"time.Duration":              true,
+	"ginka_ecs_go.ComponentType": true,
+	"ginka_ecs_go.EntityType":    true,
+	"ginka_ecs_go.Tag":           true,
+}
+
+var predeclaredValues = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true, "uintptr": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// resolveClone decides how the generated Clone copies each field of c.
+// Value fields are copied by the struct copy, and maps and slices of values
+// are cloned with maps.Clone and slices.Clone. Any other field (pointers,
+// interfaces, channels, funcs, nested maps or slices, types from other
+// packages) would be shared between the clones, so it is rejected.
+func resolveClone(c *component, types map[string]ast.Expr) error {
+	for _, f := range c.fields {
+		typ := f.Type
+		if ident, ok := typ.(*ast.Ident); ok && types[ident.Name] != nil {
+			typ = types[ident.Name]
+		}
+		var err error
+		switch typ := typ.(type) {
+		case *ast.MapType:
+			if err = valueType(typ.Key, types, nil); err == nil {
+				err = valueType(typ.Value, types, nil)
+			}
+			c.Maps = append(c.Maps, f.Name)
+		case *ast.ArrayType:
+			err = valueType(typ.Elt, types, nil)
+			if typ.Len == nil {
+				c.Slices = append(c.Slices, f.Name)
+			}
+		default:
+			err = valueType(f.Type, types, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: field %s cannot be deep-copied by Clone: %w", c.Struct, f.Name, err)
+		}
+	}
+	return nil
+}
+
+// valueType reports an error unless copying a value of type expr copies
+// all of its data.
+func valueType(expr ast.Expr, types map[string]ast.Expr, seen map[string]bool) error {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if predeclaredValues[expr.Name] {
+			return nil
+		}
+		underlying, ok := types[expr.Name]
+		if !ok {
+			return fmt.Errorf("%s is not a value type", expr.Name)
+		}
+		if seen[expr.Name] {
+			return nil
+		}
+		if seen == nil {
+			seen = make(map[string]bool)
+		}
+		seen[expr.Name] = true
+		if err := valueType(underlying, types, seen); err != nil {
+			return fmt.Errorf("%s: %w", expr.Name, err)
+		}
+		return nil
+	case *ast.SelectorExpr:
+		if pkg, ok := expr.X.(*ast.Ident); ok && valueSelectors[pkg.Name+"."+expr.Sel.Name] {
+			return nil
+		}
+		return fmt.Errorf("%s is from another package", exprString(expr))
+	case *ast.ParenExpr:
+		return valueType(expr.X, types, seen)
+	case *ast.ArrayType:
+		if expr.Len == nil {
+			return fmt.Errorf("%s shares its backing array", exprString(expr))
+		}
+		return valueType(expr.Elt, types, seen)
+	case *ast.StructType:
+		for _, f := range expr.Fields.List {
+			if err := valueType(f.Type, types, seen); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is a reference type", exprString(expr))
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.StarExpr:
+		return embeddedName(expr.X)
+	}
+	return ""
+}
+
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func paramName(field string) string {
+	runes := []rune(field)
+	runes[0] = unicode.ToLower(runes[0])
+	name := string(runes)
+	if token.IsKeyword(name) {
+		name += "Value"
+	}
+	return name
+}
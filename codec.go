@@ -0,0 +1,198 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Codec encodes and decodes component payloads.
+type Codec interface {
+	// Name identifies the codec in payload headers. It must be unique.
+	Name() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSONCodec encodes with encoding/json.
+	JSONCodec Codec = jsonCodec{}
+	// GobCodec encodes the exported fields of a value with encoding/gob.
+	GobCodec Codec = gobCodec{}
+	// BinaryCodec is a compact, positional, varint/length-prefixed encoding.
+	// See binary_codec.go for the wire format.
+	BinaryCodec Codec = binaryCodec{}
+)
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		JSONCodec.Name():   JSONCodec,
+		GobCodec.Name():    GobCodec,
+		BinaryCodec.Name(): BinaryCodec,
+	}
+)
+
+// RegisterCodec makes c available for decoding payloads whose header names it.
+func RegisterCodec(c Codec) error {
+	if isNil(c) || c.Name() == "" {
+		return fmt.Errorf("register codec: invalid codec")
+	}
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if _, ok := codecs[c.Name()]; ok {
+		return fmt.Errorf("register codec %s: already registered", c.Name())
+	}
+	codecs[c.Name()] = c
+	return nil
+}
+
+// LookupCodec returns the codec registered under name.
+func LookupCodec(name string) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[name]
+	return c, ok
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// gobCodec encodes a shadow struct holding only the exported fields of v.
+//
+// Components embed EnabledFlag, TagSet and similar types without exported
+// fields, which encoding/gob rejects. Giving those types GobEncode methods
+// does not help: the methods are promoted and the whole component would be
+// encoded through them. The shadow mirrors what encoding/json sees instead.
+type gobCodec struct{}
+
+func (gobCodec) Name() string {
+	return "gob"
+}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	rv := reflect.ValueOf(v)
+	if shadow, ok := gobShadowOf(rv); ok {
+		out := reflect.New(shadow.typ).Elem()
+		shadow.toShadow(rv.Elem(), out)
+		if err := gob.NewEncoder(&buf).EncodeValue(out); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if shadow, ok := gobShadowOf(rv); ok {
+		in := reflect.New(shadow.typ)
+		if err := gob.NewDecoder(bytes.NewReader(data)).DecodeValue(in); err != nil {
+			return err
+		}
+		shadow.fromShadow(in.Elem(), rv.Elem())
+		return nil
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type gobShadow struct {
+	typ    reflect.Type
+	fields []gobShadowField
+}
+
+type gobShadowField struct {
+	src    int
+	dst    int
+	nested *gobShadow
+}
+
+var gobShadows sync.Map // reflect.Type -> *gobShadow
+
+var (
+	gobEncoderType      = reflect.TypeOf((*gob.GobEncoder)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+)
+
+// gobShadowOf returns the shadow for a non-nil pointer to a struct.
+func gobShadowOf(rv reflect.Value) (*gobShadow, bool) {
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || !needsGobShadow(rv.Type().Elem()) {
+		return nil, false
+	}
+	return gobShadowFor(rv.Type().Elem()), true
+}
+
+func needsGobShadow(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	pt := reflect.PointerTo(t)
+	return !pt.Implements(gobEncoderType) && !pt.Implements(binaryMarshalerType)
+}
+
+func gobShadowFor(t reflect.Type) *gobShadow {
+	if cached, ok := gobShadows.Load(t); ok {
+		return cached.(*gobShadow)
+	}
+	shadow := &gobShadow{}
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		field := gobShadowField{src: i, dst: len(fields)}
+		typ := f.Type
+		if needsGobShadow(typ) {
+			field.nested = gobShadowFor(typ)
+			if len(field.nested.fields) == 0 {
+				continue
+			}
+			typ = field.nested.typ
+		}
+		fields = append(fields, reflect.StructField{Name: f.Name, Type: typ})
+		shadow.fields = append(shadow.fields, field)
+	}
+	shadow.typ = reflect.StructOf(fields)
+	actual, _ := gobShadows.LoadOrStore(t, shadow)
+	return actual.(*gobShadow)
+}
+
+func (s *gobShadow) toShadow(src reflect.Value, dst reflect.Value) {
+	for _, f := range s.fields {
+		if f.nested != nil {
+			f.nested.toShadow(src.Field(f.src), dst.Field(f.dst))
+			continue
+		}
+		dst.Field(f.dst).Set(src.Field(f.src))
+	}
+}
+
+func (s *gobShadow) fromShadow(src reflect.Value, dst reflect.Value) {
+	for _, f := range s.fields {
+		if f.nested != nil {
+			f.nested.fromShadow(src.Field(f.dst), dst.Field(f.src))
+			continue
+		}
+		dst.Field(f.src).Set(src.Field(f.dst))
+	}
+}
@@ -0,0 +1,186 @@
+package ginka_ecs_go
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testCodecComponentType ComponentType = 10005
+
+type testCodecComponent struct {
+	DataComponentCore
+	FieldTracker
+	Name   string                      `json:"name"`
+	Score  int64                       `json:"score"`
+	Ratio  float64                     `json:"ratio"`
+	Items  map[string]int              `json:"items"`
+	Path   []int32                     `json:"path"`
+	Owner  EntityRef[DataEntity]       `json:"owner"`
+	Nested *testCodecNested            `json:"nested"`
+	Extra  map[string]*testCodecNested `json:"extra"`
+}
+
+type testCodecNested struct {
+	Flag bool   `json:"flag"`
+	Data []byte `json:"data"`
+}
+
+func newTestCodecComponent() *testCodecComponent {
+	c := &testCodecComponent{
+		DataComponentCore: NewDataComponentCore(testCodecComponentType),
+		Name:              "aki",
+		Score:             -42,
+		Ratio:             0.25,
+		Items:             map[string]int{"sword": 1, "potion": 5},
+		Path:              []int32{3, -1, 7},
+		Owner:             UnboundEntityRef[DataEntity]("1001"),
+		Nested:            &testCodecNested{Flag: true, Data: []byte{1, 2, 3}},
+		Extra:             map[string]*testCodecNested{"a": {Data: []byte("x")}},
+	}
+	c.SetVersion(7)
+	return c
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	for _, codec := range []Codec{JSONCodec, GobCodec, BinaryCodec} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			want := newTestCodecComponent()
+			data, err := EncodePayload(codec, want)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			got := &testCodecComponent{DataComponentCore: NewDataComponentCore(testCodecComponentType)}
+			header, err := DecodePayload(data, got)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if header.Codec != codec.Name() {
+				t.Fatalf("header codec = %q, want %q", header.Codec, codec.Name())
+			}
+			if got.Version() != 7 || got.Name != want.Name || got.Score != want.Score || got.Ratio != want.Ratio {
+				t.Fatalf("scalars = %+v", got)
+			}
+			if !reflect.DeepEqual(got.Items, want.Items) || !reflect.DeepEqual(got.Path, want.Path) {
+				t.Fatalf("collections = %v %v", got.Items, got.Path)
+			}
+			if !reflect.DeepEqual(got.Nested, want.Nested) || !reflect.DeepEqual(got.Extra, want.Extra) {
+				t.Fatalf("nested = %+v %+v", got.Nested, got.Extra)
+			}
+			if got.Owner.Id() != "1001" || got.Owner.Bound() {
+				t.Fatalf("owner = %v", got.Owner)
+			}
+			if got.ComponentType() != testCodecComponentType || !got.Enabled() {
+				t.Fatalf("core state changed: type %d enabled %v", got.ComponentType(), got.Enabled())
+			}
+		})
+	}
+}
+
+func TestBinaryCodec_RejectsNarrowingOverflow(t *testing.T) {
+	type wide struct {
+		Signed   int64
+		Unsigned uint64
+	}
+	type narrow struct {
+		Signed   int8
+		Unsigned uint16
+	}
+	cases := []wide{{Signed: 300}, {Signed: -129}, {Unsigned: 1 << 16}}
+	for _, in := range cases {
+		data, err := BinaryCodec.Marshal(in)
+		if err != nil {
+			t.Fatalf("marshal %+v: %v", in, err)
+		}
+		var out narrow
+		if err := BinaryCodec.Unmarshal(data, &out); !errors.Is(err, errBinaryOverflow) {
+			t.Errorf("unmarshal %+v into %T: expected overflow, got %v (%+v)", in, out, err, out)
+		}
+	}
+
+	data, _ := BinaryCodec.Marshal(wide{Signed: -128, Unsigned: 1<<16 - 1})
+	var out narrow
+	if err := BinaryCodec.Unmarshal(data, &out); err != nil || out.Signed != -128 || out.Unsigned != 1<<16-1 {
+		t.Fatalf("values in range: %+v, %v", out, err)
+	}
+}
+
+func TestBinaryCodec_DeterministicMaps(t *testing.T) {
+	c := newTestCodecComponent()
+	for i := 0; i < 50; i++ {
+		c.Items[string(rune('a'+i%26))+string(rune('a'+i/26))] = i
+	}
+	first, err := BinaryCodec.Marshal(c)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		again, _ := BinaryCodec.Marshal(c)
+		if string(again) != string(first) {
+			t.Fatalf("binary encoding is not deterministic")
+		}
+	}
+	json, _ := JSONCodec.Marshal(c)
+	if len(first) >= len(json) {
+		t.Fatalf("binary payload (%d bytes) should be smaller than json (%d bytes)", len(first), len(json))
+	}
+}
+
+func TestDecodePayload_LegacyJSONAndErrors(t *testing.T) {
+	var got testCodecNested
+	header, err := DecodePayload([]byte(`{"flag":true}`), &got)
+	if err != nil || header.Codec != "json" || !got.Flag {
+		t.Fatalf("legacy json: header %+v, value %+v, err %v", header, got, err)
+	}
+
+	if _, err := DecodePayload(JoinPayload(PayloadHeader{Codec: "nope"}, nil), &got); !errors.Is(err, ErrInvalidPayload) {
+		t.Fatalf("expected ErrInvalidPayload for unknown codec, got %v", err)
+	}
+	if _, err := DecodePayload([]byte("GK\x7f"), &got); !errors.Is(err, ErrInvalidPayload) {
+		t.Fatalf("expected ErrInvalidPayload for truncated header, got %v", err)
+	}
+}
+
+func TestSplitPayload_SkipsUnknownFields(t *testing.T) {
+	fields := appendPayloadField(nil, 99, []byte("future"))
+	fields = appendPayloadField(fields, payloadFieldCodec, []byte("binary"))
+	data := append([]byte("GK"), byte(len(fields)))
+	data = append(data, fields...)
+	data = append(data, "body"...)
+
+	header, body, err := SplitPayload(data)
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	if header.Codec != "binary" || string(body) != "body" {
+		t.Fatalf("header %+v body %q", header, body)
+	}
+}
+
+func TestComponentRegistry_CodecFor(t *testing.T) {
+	reg := NewComponentRegistry()
+	_ = reg.Register(ComponentSpec{Name: "codec", Type: testCodecComponentType, New: func() Component {
+		return &testCodecComponent{DataComponentCore: NewDataComponentCore(testCodecComponentType)}
+	}, Codec: BinaryCodec})
+	_ = reg.Register(ComponentSpec{Name: "label", Type: testLabelComponentType, New: func() Component { return newTestLabelComponent("") }})
+
+	if reg.CodecFor(testLabelComponentType) != JSONCodec {
+		t.Fatalf("expected json default")
+	}
+	reg.SetDefaultCodec(GobCodec)
+	if reg.CodecFor(testLabelComponentType) != GobCodec || reg.CodecFor(testCodecComponentType) != BinaryCodec {
+		t.Fatalf("unexpected codecs")
+	}
+
+	data, err := reg.EncodeComponent(newTestCodecComponent())
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	c, err := reg.DecodeComponent(testCodecComponentType, data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if c.(*testCodecComponent).Name != "aki" {
+		t.Fatalf("decoded %+v", c)
+	}
+}
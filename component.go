@@ -3,6 +3,19 @@ package ginka_ecs_go
 // ComponentType is just an int to identify component types.
 type ComponentType int
 
+// Built-in component types. Types defined by this module and its
+// subpackages are negative so they never collide with application types,
+// and all of them are reserved here so they never collide with each other.
+const (
+	// SpatialPositionType is the type of spatial.Position.
+	SpatialPositionType ComponentType = -1
+	// TimersComponentType is the type of TimersComponent.
+	TimersComponentType ComponentType = -2
+	// StressComponentType is the type of the counter component attached
+	// by ecstest.Stress.
+	StressComponentType ComponentType = -1000
+)
+
 // Component is attached to an Entity and carries data.
 type Component interface {
 	Activatable
@@ -0,0 +1,60 @@
+package ginka_ecs_go
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ComponentManifest records the ComponentType bound to each component name.
+// Stores persist it next to their data so a later process can detect ids
+// that changed meaning, e.g. after reordering constants.
+type ComponentManifest map[string]ComponentType
+
+// Manifest returns the registry's current name/type bindings.
+func (r *ComponentRegistry) Manifest() ComponentManifest {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(ComponentManifest, len(r.byName))
+	for name, spec := range r.byName {
+		out[name] = spec.Type
+	}
+	return out
+}
+
+// CheckManifest compares stored bindings with the registry.
+// Every stored name registered under a different type, and every stored type
+// registered under a different name, is reported as ErrComponentTypeMismatch.
+// Stored names that are no longer registered are ignored.
+func (r *ComponentRegistry) CheckManifest(stored ComponentManifest) error {
+	names := make([]string, 0, len(stored))
+	for name := range stored {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		t := stored[name]
+		if spec, ok := r.LookupName(name); ok && spec.Type != t {
+			errs = append(errs, fmt.Errorf("component %q: stored as type %d, registered as type %d: %w", name, t, spec.Type, ErrComponentTypeMismatch))
+			continue
+		}
+		if spec, ok := r.Lookup(t); ok && spec.Name != name {
+			errs = append(errs, fmt.Errorf("component type %d: stored as %q, registered as %q: %w", t, name, spec.Name, ErrComponentTypeMismatch))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Merge returns a copy of m with the bindings of other added or replaced.
+func (m ComponentManifest) Merge(other ComponentManifest) ComponentManifest {
+	out := make(ComponentManifest, len(m)+len(other))
+	for name, t := range m {
+		out[name] = t
+	}
+	for name, t := range other {
+		out[name] = t
+	}
+	return out
+}
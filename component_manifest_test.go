@@ -0,0 +1,55 @@
+package ginka_ecs_go
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestComponentRegistry_CheckManifest(t *testing.T) {
+	reg := newTestComponentRegistry(t)
+	manifest := reg.Manifest()
+	if manifest["test"] != testDataComponentType || manifest["label"] != testLabelComponentType {
+		t.Fatalf("manifest = %v", manifest)
+	}
+	if err := reg.CheckManifest(manifest); err != nil {
+		t.Fatalf("own manifest: %v", err)
+	}
+
+	// Removed types are not an error.
+	if err := reg.CheckManifest(ComponentManifest{"retired": 99}); err != nil {
+		t.Fatalf("retired type: %v", err)
+	}
+
+	swapped := ComponentManifest{"test": testLabelComponentType, "label": testDataComponentType}
+	err := reg.CheckManifest(swapped)
+	if !errors.Is(err, ErrComponentTypeMismatch) {
+		t.Fatalf("expected ErrComponentTypeMismatch, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"label"`) || !strings.Contains(err.Error(), `"test"`) {
+		t.Fatalf("expected both names reported, got %v", err)
+	}
+
+	// A retired name whose id was reused by another type.
+	if err := reg.CheckManifest(ComponentManifest{"old": testLabelComponentType}); !errors.Is(err, ErrComponentTypeMismatch) {
+		t.Fatalf("expected reused id to be reported, got %v", err)
+	}
+}
+
+func TestComponentRegistry_DecodeRejectsRenamedType(t *testing.T) {
+	reg := newTestComponentRegistry(t)
+	data, err := reg.EncodeComponent(newTestLabelComponent("x"))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	header, _, _ := SplitPayload(data)
+	if header.Name != "label" {
+		t.Fatalf("header name = %q", header.Name)
+	}
+
+	other := NewComponentRegistry()
+	_ = other.Register(ComponentSpec{Name: "tag", Type: testLabelComponentType, New: func() Component { return newTestLabelComponent("") }})
+	if _, err := other.DecodeComponent(testLabelComponentType, data); !errors.Is(err, ErrComponentTypeMismatch) {
+		t.Fatalf("expected ErrComponentTypeMismatch, got %v", err)
+	}
+}
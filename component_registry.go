@@ -0,0 +1,233 @@
+package ginka_ecs_go
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ComponentSpec describes a registered component type.
+type ComponentSpec struct {
+	// Name is the component type's name (e.g. "wallet").
+	Name string
+	// Type is the ComponentType constant.
+	Type ComponentType
+	// New allocates an empty component of this type.
+	New func() Component
+	// Codec encodes persisted payloads of this type.
+	// Nil uses the registry's default codec.
+	Codec Codec
+	// SchemaVersion is the current schema version of the type's payloads.
+	// Zero is treated as 1. Older payloads are upgraded with registered
+	// migrations when decoded.
+	SchemaVersion uint32
+}
+
+// ComponentRegistry maps component names to ComponentTypes and constructors.
+// It is used wherever components are built from a description rather than
+// Go code (prefabs, loaders, tooling).
+type ComponentRegistry struct {
+	mu           sync.RWMutex
+	byType       map[ComponentType]ComponentSpec
+	byName       map[string]ComponentSpec
+	defaultCodec Codec
+	migrations   map[migrationKey]Migration
+}
+
+// DefaultComponentRegistry is the registry used when none is given explicitly.
+var DefaultComponentRegistry = NewComponentRegistry()
+
+// NewComponentRegistry creates an empty registry.
+func NewComponentRegistry() *ComponentRegistry {
+	return &ComponentRegistry{
+		byType:     make(map[ComponentType]ComponentSpec),
+		byName:     make(map[string]ComponentSpec),
+		migrations: make(map[migrationKey]Migration),
+	}
+}
+
+// RegisterComponent registers spec in DefaultComponentRegistry.
+func RegisterComponent(spec ComponentSpec) error {
+	return DefaultComponentRegistry.Register(spec)
+}
+
+// MustRegisterComponent registers spec in DefaultComponentRegistry, panicking on error.
+// It is intended for package init functions.
+func MustRegisterComponent(spec ComponentSpec) {
+	if err := RegisterComponent(spec); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds spec to the registry.
+// It returns ErrDuplicateComponentType if the type or the name is already registered.
+func (r *ComponentRegistry) Register(spec ComponentSpec) error {
+	if spec.Name == "" {
+		return fmt.Errorf("register component %d: empty name", spec.Type)
+	}
+	if spec.New == nil {
+		return fmt.Errorf("register component %s: nil constructor", spec.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.byType[spec.Type]; ok {
+		return fmt.Errorf("register component %s: type %d already registered as %s: %w", spec.Name, spec.Type, existing.Name, ErrDuplicateComponentType)
+	}
+	if existing, ok := r.byName[spec.Name]; ok {
+		return fmt.Errorf("register component %s: name already registered for type %d: %w", spec.Name, existing.Type, ErrDuplicateComponentType)
+	}
+	r.byType[spec.Type] = spec
+	r.byName[spec.Name] = spec
+	return nil
+}
+
+// Lookup returns the spec registered for t.
+func (r *ComponentRegistry) Lookup(t ComponentType) (ComponentSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.byType[t]
+	return spec, ok
+}
+
+// LookupName returns the spec registered under name.
+func (r *ComponentRegistry) LookupName(name string) (ComponentSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.byName[name]
+	return spec, ok
+}
+
+// New allocates an empty component registered for t.
+func (r *ComponentRegistry) New(t ComponentType) (Component, error) {
+	spec, ok := r.Lookup(t)
+	if !ok {
+		return nil, fmt.Errorf("new component %d: %w", t, ErrUnknownComponentType)
+	}
+	return spec.build()
+}
+
+// NewByName allocates an empty component registered under name.
+func (r *ComponentRegistry) NewByName(name string) (Component, error) {
+	spec, ok := r.LookupName(name)
+	if !ok {
+		return nil, fmt.Errorf("new component %s: %w", name, ErrUnknownComponentType)
+	}
+	return spec.build()
+}
+
+// SetDefaultCodec sets the codec used for types registered without one.
+// Nil restores JSONCodec.
+func (r *ComponentRegistry) SetDefaultCodec(c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaultCodec = c
+}
+
+// CodecFor returns the codec used to persist components of type t.
+func (r *ComponentRegistry) CodecFor(t ComponentType) Codec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if spec, ok := r.byType[t]; ok && !isNil(spec.Codec) {
+		return spec.Codec
+	}
+	if !isNil(r.defaultCodec) {
+		return r.defaultCodec
+	}
+	return JSONCodec
+}
+
+// SchemaVersion returns the current schema version of type t.
+// Unregistered types report 0.
+func (r *ComponentRegistry) SchemaVersion(t ComponentType) uint32 {
+	spec, ok := r.Lookup(t)
+	if !ok {
+		return 0
+	}
+	return spec.schemaVersion()
+}
+
+// EncodeComponent encodes c with the codec registered for its type.
+// The payload header records the codec, the schema version and the
+// registered name of the type.
+func (r *ComponentRegistry) EncodeComponent(c Component) ([]byte, error) {
+	if isNil(c) {
+		return nil, ErrNilComponent
+	}
+	t := c.ComponentType()
+	codec := r.CodecFor(t)
+	body, err := codec.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	header := PayloadHeader{Codec: codec.Name()}
+	if spec, ok := r.Lookup(t); ok {
+		header.Schema = spec.schemaVersion()
+		header.Name = spec.Name
+	}
+	return JoinPayload(header, body), nil
+}
+
+// DecodeComponent allocates a component of type t and decodes data into it
+// with the codec named in the payload header. Payloads written with an older
+// schema version are migrated first.
+func (r *ComponentRegistry) DecodeComponent(t ComponentType, data []byte) (Component, error) {
+	c, err := r.New(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.DecodeInto(c, data); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DecodeInto decodes data into c, migrating it to the current schema version
+// of c's type first. Payloads recording a different type name are rejected
+// with ErrComponentTypeMismatch.
+func (r *ComponentRegistry) DecodeInto(c Component, data []byte) error {
+	if isNil(c) {
+		return ErrNilComponent
+	}
+	t := c.ComponentType()
+	data, _, err := r.MigratePayload(t, data)
+	if err != nil {
+		return err
+	}
+	if _, err := DecodePayload(data, c); err != nil {
+		return fmt.Errorf("decode component %d: %w", t, err)
+	}
+	return nil
+}
+
+// Specs returns all registered specs sorted by ComponentType.
+func (r *ComponentRegistry) Specs() []ComponentSpec {
+	r.mu.RLock()
+	out := make([]ComponentSpec, 0, len(r.byType))
+	for _, spec := range r.byType {
+		out = append(out, spec)
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Type < out[j].Type
+	})
+	return out
+}
+
+func (s ComponentSpec) schemaVersion() uint32 {
+	if s.SchemaVersion == 0 {
+		return 1
+	}
+	return s.SchemaVersion
+}
+
+func (s ComponentSpec) build() (Component, error) {
+	c := s.New()
+	if isNil(c) {
+		return nil, fmt.Errorf("new component %s: %w", s.Name, ErrNilComponent)
+	}
+	if c.ComponentType() != s.Type {
+		return nil, fmt.Errorf("new component %s: constructor returned type %d, want %d", s.Name, c.ComponentType(), s.Type)
+	}
+	return c, nil
+}
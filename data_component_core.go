@@ -1,5 +1,8 @@
 package ginka_ecs_go
 
+// versionFieldName is the encoded name of DataComponentCore.VersionValue.
+const versionFieldName = "version"
+
 // DataComponentCore is a basic DataComponent implementation.
 // Embed this to get Enabled/tag behavior and component version tracking.
 type DataComponentCore struct {
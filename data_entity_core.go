@@ -2,6 +2,7 @@ package ginka_ecs_go
 
 import (
 	"fmt"
+	"time"
 )
 
 // DataEntityCore is a DataEntity implementation with dirty tracking.
@@ -22,16 +23,51 @@ func NewDataEntityCore(id string, name string, typ EntityType, tags ...Tag) *Dat
 }
 
 // Tx executes fn with an exclusive lock for consistent updates.
+// Lock wait and hold times are reported to DefaultMetrics.
 func (e *DataEntityCore) Tx(fn func(tx DataEntity) error) error {
+	return e.runTx(dataEntityTx{entity: e}, fn)
+}
+
+// journaledTx runs fn like Tx and returns what it changed, or nil if it
+// changed nothing.
+func (e *DataEntityCore) journaledTx(reg *ComponentRegistry, fn func(tx DataEntity) error) (*TxChange, error) {
+	if fn == nil {
+		return nil, fmt.Errorf("data entity tx: nil fn")
+	}
+	j := newTxJournal(reg)
+	var change *TxChange
+	err := e.runTx(dataEntityTx{entity: e, journal: j}, func(tx DataEntity) error {
+		err := fn(tx)
+		change = j.finish(e, err)
+		return err
+	})
+	return change, err
+}
+
+func (e *DataEntityCore) runTx(tx dataEntityTx, fn func(tx DataEntity) error) error {
 	if fn == nil {
 		return fmt.Errorf("data entity tx: nil fn")
 	}
+	metrics := DefaultMetrics()
+	if isNopMetrics(metrics) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		return fn(tx)
+	}
+
+	start := time.Now()
 	e.mu.Lock()
-	defer e.mu.Unlock()
-	return fn(dataEntityTx{entity: e})
+	acquired := time.Now()
+	defer func() {
+		e.mu.Unlock()
+		metrics.ObserveHistogram(MetricTxWaitSeconds, acquired.Sub(start).Seconds())
+		metrics.ObserveHistogram(MetricTxHoldSeconds, time.Since(acquired).Seconds())
+	}()
+	return fn(tx)
 }
 
 // GetForUpdate retrieves a component and marks it dirty if it is a DataComponent.
+// It records a change tick for any component type.
 func (e *DataEntityCore) GetForUpdate(t ComponentType) (Component, bool) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -80,6 +116,7 @@ func (e *DataEntityCore) getForUpdateUnlocked(t ComponentType) (Component, bool)
 	if !ok {
 		return nil, false
 	}
+	e.markChangedUnlocked(t)
 	dc, ok := c.(DataComponent)
 	if !ok {
 		return c, true
@@ -146,6 +183,8 @@ func (e *DataEntityCore) clearDirtyUnlocked(types ...ComponentType) {
 
 type dataEntityTx struct {
 	entity *DataEntityCore
+	// journal, if set, records state before the transaction changes it.
+	journal *txJournal
 }
 
 func (t dataEntityTx) Id() string {
@@ -165,6 +204,9 @@ func (t dataEntityTx) Enabled() bool {
 }
 
 func (t dataEntityTx) SetEnabled(enabled bool) {
+	if t.journal != nil {
+		t.journal.saveEnabled(t.entity)
+	}
 	t.entity.setEnabledUnlocked(enabled)
 }
 
@@ -177,10 +219,16 @@ func (t dataEntityTx) HasTag(tag Tag) bool {
 }
 
 func (t dataEntityTx) AddTag(tag Tag) bool {
+	if t.journal != nil {
+		t.journal.saveTags(t.entity)
+	}
 	return t.entity.addTagUnlocked(tag)
 }
 
 func (t dataEntityTx) RemoveTag(tag Tag) bool {
+	if t.journal != nil {
+		t.journal.saveTags(t.entity)
+	}
 	return t.entity.removeTagUnlocked(tag)
 }
 
@@ -202,10 +250,16 @@ func (t dataEntityTx) MustGet(ct ComponentType) Component {
 }
 
 func (t dataEntityTx) Add(c Component) error {
+	if t.journal != nil && !isNil(c) {
+		t.journal.saveComponent(t.entity, c.ComponentType())
+	}
 	return t.entity.addComponentUnlocked(c)
 }
 
 func (t dataEntityTx) RemoveComponent(ct ComponentType) bool {
+	if t.journal != nil {
+		t.journal.saveComponent(t.entity, ct)
+	}
 	if !t.entity.removeComponentUnlocked(ct) {
 		return false
 	}
@@ -214,6 +268,11 @@ func (t dataEntityTx) RemoveComponent(ct ComponentType) bool {
 }
 
 func (t dataEntityTx) RemoveComponents(types []ComponentType) int {
+	if t.journal != nil {
+		for _, ct := range types {
+			t.journal.saveComponent(t.entity, ct)
+		}
+	}
 	removed := t.entity.removeComponentsUnlocked(types)
 	if removed == 0 {
 		return 0
@@ -235,9 +294,24 @@ func (t dataEntityTx) ClearDirty(types ...ComponentType) {
 }
 
 func (t dataEntityTx) GetForUpdate(ct ComponentType) (Component, bool) {
+	if t.journal != nil {
+		t.journal.saveComponent(t.entity, ct)
+	}
 	return t.entity.getForUpdateUnlocked(ct)
 }
 
+func (t dataEntityTx) ComponentTicks(ct ComponentType) (ComponentTicks, bool) {
+	return t.entity.componentTicksUnlocked(ct)
+}
+
+func (t dataEntityTx) MarkChanged(ct ComponentType) bool {
+	return t.entity.markChangedUnlocked(ct)
+}
+
+func (t dataEntityTx) StateTick() Tick {
+	return t.entity.stateTick
+}
+
 func (t dataEntityTx) Tx(fn func(tx DataEntity) error) error {
 	return fmt.Errorf("data entity tx: nested tx not supported")
 }
@@ -245,3 +319,5 @@ func (t dataEntityTx) Tx(fn func(tx DataEntity) error) error {
 // Must satisfy DataEntity.
 var _ DataEntity = (*DataEntityCore)(nil)
 var _ Entity = (*DataEntityCore)(nil)
+var _ ChangeTracker = dataEntityTx{}
+var _ StateTracker = dataEntityTx{}
@@ -0,0 +1,10 @@
+package debug
+
+import "errors"
+
+var (
+	// ErrWorldExists indicates a world with the same name is already registered.
+	ErrWorldExists = errors.New("world already registered")
+	// ErrEditsDisabled indicates an edit was requested while no edit guard is installed.
+	ErrEditsDisabled = errors.New("edits are disabled")
+)
@@ -0,0 +1,481 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// WorldInfo is one entry of GET /worlds.
+type WorldInfo struct {
+	Name     string `json:"name"`
+	Running  bool   `json:"running"`
+	Entities int    `json:"entities"`
+}
+
+// EntitySummary is one entry of an EntityPage.
+type EntitySummary struct {
+	Id         string                  `json:"id"`
+	Name       string                  `json:"name"`
+	Type       ginka_ecs_go.EntityType `json:"type"`
+	Enabled    bool                    `json:"enabled"`
+	Tags       []ginka_ecs_go.Tag      `json:"tags,omitempty"`
+	Components []string                `json:"components,omitempty"`
+}
+
+// EntityPage is the response of GET /worlds/{world}/entities.
+// Next is the after parameter of the following page; empty on the last page.
+type EntityPage struct {
+	Entities []EntitySummary `json:"entities"`
+	Next     string          `json:"next,omitempty"`
+}
+
+// EntityDetail is the response of GET /worlds/{world}/entities/{id}.
+type EntityDetail struct {
+	EntitySummary
+	// Dirty lists the names of components with unflushed changes.
+	Dirty      []string        `json:"dirty,omitempty"`
+	Components []ComponentView `json:"components"`
+}
+
+// ComponentView is a component rendered through its codec.
+type ComponentView struct {
+	Name    string                     `json:"name"`
+	Type    ginka_ecs_go.ComponentType `json:"type"`
+	Version uint64                     `json:"version,omitempty"`
+	Dirty   bool                       `json:"dirty,omitempty"`
+	Codec   string                     `json:"codec,omitempty"`
+	Schema  uint32                     `json:"schema,omitempty"`
+	// Size is the encoded body size in bytes.
+	Size int `json:"size"`
+	// Data is the component as JSON after a round trip through its codec.
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// EntityEdit is the body of PATCH /worlds/{world}/entities/{id}.
+type EntityEdit struct {
+	Enabled    *bool              `json:"enabled,omitempty"`
+	AddTags    []ginka_ecs_go.Tag `json:"add_tags,omitempty"`
+	RemoveTags []ginka_ecs_go.Tag `json:"remove_tags,omitempty"`
+}
+
+// Routes returns the inspector's handler.
+func (ins *Inspector) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/worlds", ins.handleWorlds)
+	mux.HandleFunc("/worlds/", ins.handleWorld)
+	return mux
+}
+
+func (ins *Inspector) handleWorlds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	out := make([]WorldInfo, 0)
+	for _, name := range ins.worldNames() {
+		src, ok := ins.lookup(name)
+		if !ok {
+			continue
+		}
+		out = append(out, WorldInfo{Name: name, Running: src.world().IsRunning(), Entities: src.len()})
+	}
+	writeJSON(w, out)
+}
+
+// handleWorld dispatches /worlds/{world}/entities[/{id}[/components/{name}]].
+func (ins *Inspector) handleWorld(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/worlds/"), "/")
+	if len(parts) < 2 || parts[1] != "entities" {
+		http.NotFound(w, r)
+		return
+	}
+	src, ok := ins.lookup(parts[0])
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown world %q", parts[0]), http.StatusNotFound)
+		return
+	}
+	switch {
+	case len(parts) == 2:
+		ins.handleList(w, r, src)
+	case len(parts) == 3 && parts[2] != "":
+		ins.handleEntity(w, r, src, parts[2])
+	case len(parts) == 5 && parts[3] == "components":
+		ins.handleComponent(w, r, src, parts[2], parts[4])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (ins *Inspector) handleList(w http.ResponseWriter, r *http.Request, src source) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	filters, err := ins.parseFilters(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit := DefaultPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, MaxPageSize)
+	}
+	after := q.Get("after")
+
+	var ids []string
+	err = src.forEach(r.Context(), func(ent ginka_ecs_go.DataEntity) error {
+		if ent.Id() > after && ginka_ecs_go.Matches(ent, filters...) {
+			ids = append(ids, ent.Id())
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("list entities: %v", err), http.StatusInternalServerError)
+		return
+	}
+	sort.Strings(ids)
+
+	page := EntityPage{Entities: make([]EntitySummary, 0, min(len(ids), limit))}
+	for i, id := range ids {
+		if len(page.Entities) == limit {
+			page.Next = ids[i-1]
+			break
+		}
+		ent, ok := src.get(id)
+		if !ok {
+			continue
+		}
+		_ = ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+			page.Entities = append(page.Entities, ins.summarize(tx))
+			return nil
+		})
+	}
+	writeJSON(w, page)
+}
+
+// parseFilters turns type, tag, with, without and enabled parameters into filters.
+func (ins *Inspector) parseFilters(q map[string][]string) ([]ginka_ecs_go.Filter, error) {
+	var filters []ginka_ecs_go.Filter
+	if v := first(q["type"]); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type %q", v)
+		}
+		typ := ginka_ecs_go.EntityType(n)
+		filters = append(filters, func(ent ginka_ecs_go.Entity) bool { return ent.Type() == typ })
+	}
+	if tags := q["tag"]; len(tags) > 0 {
+		converted := make([]ginka_ecs_go.Tag, 0, len(tags))
+		for _, tag := range tags {
+			converted = append(converted, ginka_ecs_go.Tag(tag))
+		}
+		filters = append(filters, ginka_ecs_go.WithTags(converted...))
+	}
+	for _, param := range []string{"with", "without"} {
+		v := first(q[param])
+		if v == "" {
+			continue
+		}
+		var types []ginka_ecs_go.ComponentType
+		for _, name := range strings.Split(v, ",") {
+			spec, ok := ins.registry.LookupName(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown component %q", name)
+			}
+			types = append(types, spec.Type)
+		}
+		if param == "with" {
+			filters = append(filters, ginka_ecs_go.WithComponents(types...))
+		} else {
+			filters = append(filters, ginka_ecs_go.WithoutComponents(types...))
+		}
+	}
+	if v := first(q["enabled"]); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid enabled %q", v)
+		}
+		if enabled {
+			filters = append(filters, ginka_ecs_go.EnabledOnly())
+		} else {
+			filters = append(filters, ginka_ecs_go.Not(ginka_ecs_go.EnabledOnly()))
+		}
+	}
+	return filters, nil
+}
+
+func (ins *Inspector) handleEntity(w http.ResponseWriter, r *http.Request, src source, id string) {
+	ent, ok := src.get(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown entity %q", id), http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPatch:
+		if !ins.guardEdit(w, r) {
+			return
+		}
+		var edit EntityEdit
+		if err := json.NewDecoder(r.Body).Decode(&edit); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		err := ginka_ecs_go.TxContext(r.Context(), ent, func(ctx context.Context, tx ginka_ecs_go.DataEntity) error {
+			if edit.Enabled != nil {
+				tx.SetEnabled(*edit.Enabled)
+			}
+			for _, tag := range edit.AddTags {
+				tx.AddTag(tag)
+			}
+			for _, tag := range edit.RemoveTags {
+				tx.RemoveTag(tag)
+			}
+			return nil
+		})
+		if err != nil {
+			writeEditError(w, err)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, ins.detail(ent))
+}
+
+func (ins *Inspector) handleComponent(w http.ResponseWriter, r *http.Request, src source, id string, name string) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !ins.guardEdit(w, r) {
+		return
+	}
+	ent, ok := src.get(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown entity %q", id), http.StatusNotFound)
+		return
+	}
+	spec, ok := ins.registry.LookupName(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown component %q", name), http.StatusNotFound)
+		return
+	}
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "read body", http.StatusBadRequest)
+		return
+	}
+
+	err = ginka_ecs_go.TxContext(r.Context(), ent, func(ctx context.Context, tx ginka_ecs_go.DataEntity) error {
+		return ins.patchComponent(tx, spec, patch)
+	})
+	if err != nil {
+		writeEditError(w, err)
+		return
+	}
+	writeJSON(w, ins.detail(ent))
+}
+
+// writeEditError reports a failed edit transaction.
+func writeEditError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ginka_ecs_go.ErrComponentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+// patchComponent applies a JSON merge patch to the component. The result
+// is decoded into a scratch instance first, so a bad patch leaves the
+// component untouched and deleted fields and map entries really go, and
+// then copied into the existing component. The component keeps its
+// identity, enabled flag, tags and Added tick; the edit bumps its version,
+// marks it dirty and reports the patched fields to a FieldTracker.
+func (ins *Inspector) patchComponent(tx ginka_ecs_go.DataEntity, spec ginka_ecs_go.ComponentSpec, patch []byte) error {
+	c, ok := tx.Get(spec.Type)
+	if !ok {
+		return fmt.Errorf("patch %s: %w", spec.Name, ginka_ecs_go.ErrComponentNotFound)
+	}
+	current, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("patch %s: %w", spec.Name, err)
+	}
+	merged, err := ginka_ecs_go.ApplyMergePatch(current, patch)
+	if err != nil {
+		return fmt.Errorf("patch %s: %w", spec.Name, err)
+	}
+	scratch, err := ins.registry.New(spec.Type)
+	if err != nil {
+		return fmt.Errorf("patch %s: %w", spec.Name, err)
+	}
+	dst, src := reflect.ValueOf(c), reflect.ValueOf(scratch)
+	if dst.Kind() != reflect.Pointer || dst.Type() != src.Type() {
+		return fmt.Errorf("patch %s: component is %T, registry creates %T", spec.Name, c, scratch)
+	}
+	if err := json.Unmarshal(merged, scratch); err != nil {
+		return fmt.Errorf("patch %s: %w", spec.Name, err)
+	}
+	keepCore(c, scratch)
+	if err := markPatchedFields(c, scratch, current); err != nil {
+		return fmt.Errorf("patch %s: %w", spec.Name, err)
+	}
+
+	tx.GetForUpdate(spec.Type)
+	keepCore(c, scratch) // picks up the version GetForUpdate bumped
+
+	dst.Elem().Set(src.Elem())
+	return nil
+}
+
+// keepCore gives scratch the version, enabled flag and tags of c, which a
+// patch does not edit.
+func keepCore(c ginka_ecs_go.Component, scratch ginka_ecs_go.Component) {
+	if dc, ok := scratch.(ginka_ecs_go.DataComponent); ok {
+		var version uint64
+		if cur, ok := c.(ginka_ecs_go.DataComponent); ok {
+			version = cur.Version()
+		}
+		dc.SetVersion(version)
+	}
+	scratch.SetEnabled(c.Enabled())
+	for _, tag := range scratch.Tags() {
+		scratch.RemoveTag(tag)
+	}
+	for _, tag := range c.Tags() {
+		scratch.AddTag(tag)
+	}
+}
+
+// markPatchedFields carries the changed fields old has not flushed yet over
+// to fresh and adds the top-level fields that differ from before, so delta
+// persistence writes them. Components without MarkFieldChanged are skipped.
+func markPatchedFields(old ginka_ecs_go.Component, fresh ginka_ecs_go.Component, before []byte) error {
+	marker, ok := fresh.(interface{ MarkFieldChanged(names ...string) })
+	if !ok {
+		return nil
+	}
+	if reporter, ok := old.(ginka_ecs_go.FieldChangeReporter); ok {
+		marker.MarkFieldChanged(reporter.ChangedFields()...)
+	}
+	after, err := json.Marshal(fresh)
+	if err != nil {
+		return err
+	}
+	diff, err := ginka_ecs_go.CreateMergePatch(before, after)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(diff, &fields); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	marker.MarkFieldChanged(names...)
+	return nil
+}
+
+func (ins *Inspector) guardEdit(w http.ResponseWriter, r *http.Request) bool {
+	if err := ins.authorize(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (ins *Inspector) summarize(tx ginka_ecs_go.DataEntity) EntitySummary {
+	out := EntitySummary{Id: tx.Id(), Name: tx.Name(), Type: tx.Type(), Enabled: tx.Enabled(), Tags: tx.Tags()}
+	for _, c := range tx.AllComponents() {
+		out.Components = append(out.Components, ins.componentName(c.ComponentType()))
+	}
+	return out
+}
+
+func (ins *Inspector) detail(ent ginka_ecs_go.DataEntity) EntityDetail {
+	var out EntityDetail
+	_ = ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+		out.EntitySummary = ins.summarize(tx)
+		dirty := make(map[ginka_ecs_go.ComponentType]bool)
+		for _, t := range tx.DirtyTypes() {
+			dirty[t] = true
+			out.Dirty = append(out.Dirty, ins.componentName(t))
+		}
+		sort.Strings(out.Dirty)
+		out.Components = make([]ComponentView, 0, len(out.EntitySummary.Components))
+		for _, c := range tx.AllComponents() {
+			out.Components = append(out.Components, ins.render(c, dirty[c.ComponentType()]))
+		}
+		return nil
+	})
+	return out
+}
+
+// render encodes c with its registered codec and shows the decoded result as JSON.
+func (ins *Inspector) render(c ginka_ecs_go.Component, dirty bool) ComponentView {
+	t := c.ComponentType()
+	view := ComponentView{Name: ins.componentName(t), Type: t, Dirty: dirty}
+	if dc, ok := c.(ginka_ecs_go.DataComponent); ok {
+		view.Version = dc.Version()
+	}
+	payload, err := ins.registry.EncodeComponent(c)
+	if err != nil {
+		view.Error = err.Error()
+		return view
+	}
+	header, body, err := ginka_ecs_go.SplitPayload(payload)
+	if err != nil {
+		view.Error = err.Error()
+		return view
+	}
+	view.Codec, view.Schema, view.Size = header.Codec, header.Schema, len(body)
+	if header.Codec == ginka_ecs_go.JSONCodec.Name() {
+		view.Data = body
+		return view
+	}
+	decoded, err := ins.registry.DecodeComponent(t, payload)
+	if err != nil {
+		view.Error = err.Error()
+		return view
+	}
+	if view.Data, err = json.Marshal(decoded); err != nil {
+		view.Error = err.Error()
+	}
+	return view
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, fmt.Sprintf("encode response: %v", err), http.StatusInternalServerError)
+	}
+}
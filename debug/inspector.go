@@ -0,0 +1,149 @@
+// Package debug serves a read-mostly HTTP view into running worlds.
+//
+// An Inspector lists registered worlds with their entity counts, pages
+// through entities with query filters and shows an entity's tags, enabled
+// flag, dirty types and components rendered through their codec. Edits are
+// disabled unless an edit guard is installed.
+package debug
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+const (
+	// DefaultPageSize is the number of entities listed when no limit is given.
+	DefaultPageSize = 50
+	// MaxPageSize caps the limit query parameter.
+	MaxPageSize = 500
+)
+
+// EditGuard authorizes an edit request. A non-nil error rejects it.
+type EditGuard func(r *http.Request) error
+
+// Inspector serves the debug endpoints. Mount Routes under a prefix with
+// http.StripPrefix; paths below are relative to it.
+//
+//	GET   /worlds
+//	GET   /worlds/{world}/entities?type=&tag=&with=&without=&enabled=&after=&limit=
+//	GET   /worlds/{world}/entities/{id}
+//	PATCH /worlds/{world}/entities/{id}                     {"enabled":..,"add_tags":[..],"remove_tags":[..]}
+//	PATCH /worlds/{world}/entities/{id}/components/{name}   JSON merge patch
+type Inspector struct {
+	registry *ginka_ecs_go.ComponentRegistry
+
+	mu     sync.RWMutex
+	worlds map[string]source
+	guard  EditGuard
+}
+
+// source adapts an EntityManager of any entity type to the inspector.
+type source interface {
+	world() ginka_ecs_go.World
+	len() int
+	get(id string) (ginka_ecs_go.DataEntity, bool)
+	forEach(ctx context.Context, fn func(ent ginka_ecs_go.DataEntity) error) error
+}
+
+type managerSource[T ginka_ecs_go.DataEntity] struct {
+	w ginka_ecs_go.World
+	m ginka_ecs_go.EntityManager[T]
+}
+
+func (s managerSource[T]) world() ginka_ecs_go.World { return s.w }
+func (s managerSource[T]) len() int                  { return s.m.Len() }
+
+func (s managerSource[T]) get(id string) (ginka_ecs_go.DataEntity, bool) {
+	ent, ok := s.m.Get(id)
+	if !ok {
+		return nil, false
+	}
+	return ent, true
+}
+
+func (s managerSource[T]) forEach(ctx context.Context, fn func(ent ginka_ecs_go.DataEntity) error) error {
+	return s.m.ForEach(ctx, func(ent T) error {
+		return fn(ent)
+	})
+}
+
+// NewInspector creates an Inspector naming and rendering components with reg.
+// Nil uses DefaultComponentRegistry.
+func NewInspector(reg *ginka_ecs_go.ComponentRegistry) *Inspector {
+	if reg == nil {
+		reg = ginka_ecs_go.DefaultComponentRegistry
+	}
+	return &Inspector{registry: reg, worlds: make(map[string]source)}
+}
+
+// Register exposes the entities in m under the name of w.
+func Register[T ginka_ecs_go.DataEntity](ins *Inspector, w ginka_ecs_go.World, m ginka_ecs_go.EntityManager[T]) error {
+	name := w.GetName()
+	ins.mu.Lock()
+	defer ins.mu.Unlock()
+	if _, ok := ins.worlds[name]; ok {
+		return fmt.Errorf("register world %s: %w", name, ErrWorldExists)
+	}
+	ins.worlds[name] = managerSource[T]{w: w, m: m}
+	return nil
+}
+
+// Unregister removes the world named name, returning true if it was registered.
+func (ins *Inspector) Unregister(name string) bool {
+	ins.mu.Lock()
+	defer ins.mu.Unlock()
+	if _, ok := ins.worlds[name]; !ok {
+		return false
+	}
+	delete(ins.worlds, name)
+	return true
+}
+
+// SetEditGuard enables edits authorized by guard. Nil disables edits.
+func (ins *Inspector) SetEditGuard(guard EditGuard) {
+	ins.mu.Lock()
+	defer ins.mu.Unlock()
+	ins.guard = guard
+}
+
+func (ins *Inspector) lookup(name string) (source, bool) {
+	ins.mu.RLock()
+	defer ins.mu.RUnlock()
+	src, ok := ins.worlds[name]
+	return src, ok
+}
+
+func (ins *Inspector) authorize(r *http.Request) error {
+	ins.mu.RLock()
+	guard := ins.guard
+	ins.mu.RUnlock()
+	if guard == nil {
+		return ErrEditsDisabled
+	}
+	return guard(r)
+}
+
+// componentName returns the registered name of t, or "#<t>" for
+// unregistered types.
+func (ins *Inspector) componentName(t ginka_ecs_go.ComponentType) string {
+	if spec, ok := ins.registry.Lookup(t); ok {
+		return spec.Name
+	}
+	return fmt.Sprintf("#%d", t)
+}
+
+func (ins *Inspector) worldNames() []string {
+	ins.mu.RLock()
+	defer ins.mu.RUnlock()
+	names := make([]string, 0, len(ins.worlds))
+	for name := range ins.worlds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
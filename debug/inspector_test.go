@@ -0,0 +1,235 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+const (
+	testStatsType ginka_ecs_go.ComponentType = iota + 1
+	testMarkerType
+)
+
+type testStats struct {
+	ginka_ecs_go.DataComponentCore
+	ginka_ecs_go.FieldTracker
+	HP    int            `json:"hp"`
+	Title string         `json:"title,omitempty"`
+	Bonus map[string]int `json:"bonus,omitempty"`
+}
+
+func (c *testStats) StorageKey() string { return "stats" }
+
+type testMarker struct {
+	ginka_ecs_go.DataComponentCore
+	Note string `json:"note"`
+}
+
+func (c *testMarker) StorageKey() string { return "marker" }
+
+type fixture struct {
+	t   *testing.T
+	ins *Inspector
+	m   *ginka_ecs_go.MapEntityManager[ginka_ecs_go.DataEntity]
+	srv *httptest.Server
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	reg := ginka_ecs_go.NewComponentRegistry()
+	_ = reg.Register(ginka_ecs_go.ComponentSpec{Name: "stats", Type: testStatsType, New: func() ginka_ecs_go.Component {
+		return &testStats{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testStatsType)}
+	}})
+	_ = reg.Register(ginka_ecs_go.ComponentSpec{Name: "marker", Type: testMarkerType, Codec: ginka_ecs_go.BinaryCodec, New: func() ginka_ecs_go.Component {
+		return &testMarker{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testMarkerType)}
+	}})
+
+	m := ginka_ecs_go.NewEntityManager(func(id string, name string, typ ginka_ecs_go.EntityType, tags ...ginka_ecs_go.Tag) (ginka_ecs_go.DataEntity, error) {
+		return ginka_ecs_go.NewDataEntityCore(id, name, typ, tags...), nil
+	}, 4)
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("p%d", i)
+		ent, _ := m.Create(ctx, id, "player "+id, 1, "player")
+		_ = ent.Add(&testStats{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testStatsType), HP: 10 * i})
+	}
+	npc, _ := m.Create(ctx, "n1", "npc", 2)
+	_ = npc.Add(&testMarker{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testMarkerType), Note: "quest giver"})
+	npc.SetEnabled(false)
+
+	ins := NewInspector(reg)
+	if err := Register[ginka_ecs_go.DataEntity](ins, ginka_ecs_go.NewCoreWorld("battle"), m); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	srv := httptest.NewServer(http.StripPrefix("/debug", ins.Routes()))
+	t.Cleanup(srv.Close)
+	return &fixture{t: t, ins: ins, m: m, srv: srv}
+}
+
+func (f *fixture) do(method string, path string, body string, out any) int {
+	f.t.Helper()
+	req, err := http.NewRequest(method, f.srv.URL+"/debug"+path, bytes.NewBufferString(body))
+	if err != nil {
+		f.t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("X-Debug-Token", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		f.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			f.t.Fatalf("decode %s: %v", path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func summaryIds(page EntityPage) []string {
+	var out []string
+	for _, ent := range page.Entities {
+		out = append(out, ent.Id)
+	}
+	return out
+}
+
+func TestInspector_ListsWorldsAndPagesEntities(t *testing.T) {
+	f := newFixture(t)
+
+	var worlds []WorldInfo
+	f.do(http.MethodGet, "/worlds", "", &worlds)
+	if !reflect.DeepEqual(worlds, []WorldInfo{{Name: "battle", Entities: 6}}) {
+		t.Fatalf("worlds = %+v", worlds)
+	}
+
+	var page EntityPage
+	f.do(http.MethodGet, "/worlds/battle/entities?tag=player&limit=3", "", &page)
+	if !reflect.DeepEqual(summaryIds(page), []string{"p0", "p1", "p2"}) || page.Next != "p2" {
+		t.Fatalf("first page = %+v", page)
+	}
+	after := page.Next
+	page = EntityPage{}
+	f.do(http.MethodGet, "/worlds/battle/entities?tag=player&limit=3&after="+after, "", &page)
+	if !reflect.DeepEqual(summaryIds(page), []string{"p3", "p4"}) || page.Next != "" {
+		t.Fatalf("second page = %+v", page)
+	}
+
+	f.do(http.MethodGet, "/worlds/battle/entities?with=marker&enabled=false&type=2", "", &page)
+	if !reflect.DeepEqual(summaryIds(page), []string{"n1"}) || !reflect.DeepEqual(page.Entities[0].Components, []string{"marker"}) {
+		t.Fatalf("npc page = %+v", page)
+	}
+
+	if code := f.do(http.MethodGet, "/worlds/battle/entities?with=missing", "", nil); code != http.StatusBadRequest {
+		t.Fatalf("unknown component status = %d", code)
+	}
+	if code := f.do(http.MethodGet, "/worlds/other/entities", "", nil); code != http.StatusNotFound {
+		t.Fatalf("unknown world status = %d", code)
+	}
+}
+
+func TestInspector_RendersComponentsThroughCodec(t *testing.T) {
+	f := newFixture(t)
+
+	var detail EntityDetail
+	f.do(http.MethodGet, "/worlds/battle/entities/n1", "", &detail)
+	if detail.Enabled || len(detail.Components) != 1 {
+		t.Fatalf("detail = %+v", detail)
+	}
+	view := detail.Components[0]
+	if view.Codec != "binary" || view.Name != "marker" || view.Size == 0 {
+		t.Fatalf("component view = %+v", view)
+	}
+	var data struct {
+		Note string `json:"note"`
+	}
+	if err := json.Unmarshal(view.Data, &data); err != nil || data.Note != "quest giver" {
+		t.Fatalf("rendered data %s: %v", view.Data, err)
+	}
+}
+
+func TestInspector_GuardedEdits(t *testing.T) {
+	f := newFixture(t)
+
+	if code := f.do(http.MethodPatch, "/worlds/battle/entities/p1/components/stats", `{"hp":99}`, nil); code != http.StatusForbidden {
+		t.Fatalf("edit without guard status = %d", code)
+	}
+	f.ins.SetEditGuard(func(r *http.Request) error {
+		if r.Header.Get("X-Debug-Token") != "secret" {
+			return errors.New("bad token")
+		}
+		return nil
+	})
+
+	p1 := f.m.MustGet("p1")
+	before, _ := ginka_ecs_go.Get[*testStats](p1, testStatsType)
+	added, _ := p1.(ginka_ecs_go.ChangeTracker).ComponentTicks(testStatsType)
+	var detail EntityDetail
+	if code := f.do(http.MethodPatch, "/worlds/battle/entities/p1/components/stats", `{"hp":99,"version":1000}`, &detail); code != http.StatusOK {
+		t.Fatalf("patch status = %d", code)
+	}
+	stats, _ := ginka_ecs_go.Get[*testStats](p1, testStatsType)
+	if stats.HP != 99 || stats.Version() != 1 {
+		t.Fatalf("stats after patch = %+v", stats)
+	}
+	// The edit lands in the existing component, which stays added at its
+	// original tick.
+	ticks, _ := p1.(ginka_ecs_go.ChangeTracker).ComponentTicks(testStatsType)
+	if stats != before || ticks.Added != added.Added || ticks.Changed == added.Changed {
+		t.Fatalf("patch replaced the component: ticks %+v, were %+v", ticks, added)
+	}
+	if !reflect.DeepEqual(detail.Dirty, []string{"stats"}) {
+		t.Fatalf("dirty = %v", detail.Dirty)
+	}
+
+	if code := f.do(http.MethodPatch, "/worlds/battle/entities/p1/components/stats", `{"hp":"many"}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("invalid patch status = %d", code)
+	}
+	if stats.HP != 99 || stats.Version() != 1 {
+		t.Fatalf("invalid patch modified the component: %+v", stats)
+	}
+
+	// Deleted fields and map entries are dropped rather than left in place.
+	stats.Title, stats.Bonus = "veteran", map[string]int{"str": 1, "dex": 2}
+	stats.AddTag("buffed")
+	if code := f.do(http.MethodPatch, "/worlds/battle/entities/p1/components/stats", `{"title":null,"bonus":{"str":5,"dex":null}}`, nil); code != http.StatusOK {
+		t.Fatalf("delete patch status = %d", code)
+	}
+	stats, _ = ginka_ecs_go.Get[*testStats](f.m.MustGet("p1"), testStatsType)
+	if stats.Title != "" || !reflect.DeepEqual(stats.Bonus, map[string]int{"str": 5}) || stats.HP != 99 || stats.Version() != 2 {
+		t.Fatalf("stats after delete patch = %+v", stats)
+	}
+	if !stats.HasTag("buffed") || !reflect.DeepEqual(stats.ChangedFields(), []string{"hp", "bonus", "title"}) {
+		t.Fatalf("tags %v, changed fields %v", stats.Tags(), stats.ChangedFields())
+	}
+
+	f.do(http.MethodPatch, "/worlds/battle/entities/n1", `{"enabled":true,"add_tags":["boss"]}`, &detail)
+	if !detail.Enabled || !reflect.DeepEqual(detail.Tags, []ginka_ecs_go.Tag{"boss"}) {
+		t.Fatalf("entity edit = %+v", detail)
+	}
+
+	if err := f.m.Add(context.Background(), &frozenEntity{ginka_ecs_go.NewDataEntityCore("f1", "frozen", 2)}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if code := f.do(http.MethodPatch, "/worlds/battle/entities/f1", `{"enabled":false}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("failed entity edit status = %d", code)
+	}
+}
+
+// frozenEntity refuses every transaction.
+type frozenEntity struct {
+	*ginka_ecs_go.DataEntityCore
+}
+
+func (e *frozenEntity) Tx(fn func(tx ginka_ecs_go.DataEntity) error) error {
+	return errors.New("frozen")
+}
@@ -0,0 +1,179 @@
+package ginka_ecs_go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DumpEntities writes every entity of m to w in a canonical, human-readable
+// text form meant for golden files and diffs: entities sorted by id, tags
+// sorted, components sorted by ComponentType and shown as indented JSON
+// with sorted keys after a round trip through their registered codec.
+// A nil reg uses DefaultComponentRegistry.
+//
+// The output of two worlds holding equal state is byte-identical.
+func DumpEntities[T Entity](ctx context.Context, w io.Writer, m EntityManager[T], reg *ComponentRegistry) error {
+	var ents []T
+	if err := m.ForEach(ctx, func(ent T) error {
+		ents = append(ents, ent)
+		return nil
+	}); err != nil {
+		return err
+	}
+	sort.Slice(ents, func(i, j int) bool { return ents[i].Id() < ents[j].Id() })
+
+	bw := bufio.NewWriter(w)
+	for i, ent := range ents {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if i > 0 {
+			bw.WriteByte('\n')
+		}
+		if err := dumpEntity(bw, ent, reg); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// DumpEntity writes ent to w in the form used by DumpEntities.
+// DataEntities are read inside Tx.
+func DumpEntity(w io.Writer, ent Entity, reg *ComponentRegistry) error {
+	bw := bufio.NewWriter(w)
+	if err := dumpEntity(bw, ent, reg); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func dumpEntity(w *bufio.Writer, ent Entity, reg *ComponentRegistry) error {
+	if reg == nil {
+		reg = DefaultComponentRegistry
+	}
+	write := func(e Entity) error {
+		fmt.Fprintf(w, "entity %s\n", dumpString(e.Id()))
+		fmt.Fprintf(w, "  name: %s\n", dumpString(e.Name()))
+		fmt.Fprintf(w, "  type: %d\n", e.Type())
+		fmt.Fprintf(w, "  enabled: %t\n", e.Enabled())
+		if tags := dumpTags(e.Tags()); tags != "" {
+			fmt.Fprintf(w, "  tags: %s\n", tags)
+		}
+		components := e.AllComponents()
+		sort.Slice(components, func(i, j int) bool {
+			return components[i].ComponentType() < components[j].ComponentType()
+		})
+		for _, c := range components {
+			if err := dumpComponent(w, c, reg); err != nil {
+				return fmt.Errorf("dump entity %s: %w", e.Id(), err)
+			}
+		}
+		return nil
+	}
+	if de, ok := ent.(DataEntity); ok {
+		return de.Tx(func(tx DataEntity) error { return write(tx) })
+	}
+	return write(ent)
+}
+
+func dumpComponent(w *bufio.Writer, c Component, reg *ComponentRegistry) error {
+	t := c.ComponentType()
+	payload, err := encodeComponent(reg, c)
+	if err != nil {
+		return fmt.Errorf("component %d: %w", t, err)
+	}
+	header, body, err := payloadJSON(reg, t, payload)
+	if err != nil {
+		return fmt.Errorf("component %d: %w", t, err)
+	}
+	data, err := canonicalJSON(body)
+	if err != nil {
+		return fmt.Errorf("component %d: %w", t, err)
+	}
+
+	name := strconv.Itoa(int(t))
+	if header.Name != "" {
+		name = header.Name + " " + name
+	}
+	fmt.Fprintf(w, "  component %s (%s", name, header.Codec)
+	if header.Schema > 1 {
+		fmt.Fprintf(w, ", schema %d", header.Schema)
+	}
+	if !c.Enabled() {
+		w.WriteString(", disabled")
+	}
+	if tags := dumpTags(c.Tags()); tags != "" {
+		fmt.Fprintf(w, ", tags %s", tags)
+	}
+	w.WriteString(")\n")
+	for _, line := range strings.Split(string(data), "\n") {
+		w.WriteString("    ")
+		w.WriteString(line)
+		w.WriteByte('\n')
+	}
+	return nil
+}
+
+// payloadJSON returns the header of payload and its body as JSON. Bodies
+// written by other codecs are decoded with reg and marshalled to JSON.
+func payloadJSON(reg *ComponentRegistry, t ComponentType, payload []byte) (PayloadHeader, []byte, error) {
+	header, body, err := SplitPayload(payload)
+	if err != nil {
+		return header, nil, err
+	}
+	if header.Codec == JSONCodec.Name() {
+		return header, body, nil
+	}
+	decoded, err := reg.DecodeComponent(t, payload)
+	if err != nil {
+		return header, nil, err
+	}
+	body, err = json.Marshal(decoded)
+	return header, body, err
+}
+
+// canonicalJSON re-indents a JSON document with sorted object keys,
+// keeping numbers exactly as written.
+func canonicalJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func dumpTags(tags []Tag) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = dumpString(string(tag))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// dumpString quotes s unless it is non-empty plain text.
+func dumpString(s string) string {
+	if s == "" || strings.ContainsAny(s, " ,\"\\\n\t") || strconv.Quote(s) != `"`+s+`"` {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestDumpEntities_Canonical(t *testing.T) {
+	reg := NewComponentRegistry()
+	_ = reg.Register(ComponentSpec{Name: "label", Type: testLabelComponentType, New: func() Component { return newTestLabelComponent("") }, Codec: BinaryCodec})
+	ctx := context.Background()
+
+	build := func(ids ...string) *MapEntityManager[DataEntity] {
+		m := newTestDataEntityManager()
+		for _, id := range ids {
+			ent, err := m.Create(ctx, id, "Player "+id, 1, "vip", "player")
+			if err != nil {
+				t.Fatalf("create: %v", err)
+			}
+			label := newTestLabelComponent("hero")
+			label.SetVersion(3)
+			_ = ent.Add(label)
+			_ = ent.Add(newTestDataComponent())
+		}
+		return m
+	}
+	var a, b bytes.Buffer
+	if err := DumpEntities[DataEntity](ctx, &a, build("p2", "p1"), reg); err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	if err := DumpEntities[DataEntity](ctx, &b, build("p1", "p2"), reg); err != nil {
+		t.Fatalf("dump: %v", err)
+	}
+	if a.String() != b.String() {
+		t.Fatalf("dumps differ:\n%s\n---\n%s", a.String(), b.String())
+	}
+
+	want := `entity p1
+  name: "Player p1"
+  type: 1
+  enabled: true
+  tags: player, vip
+  component 10001 (json)
+    {
+      "Type": 10001,
+      "version": 0
+    }
+  component label 10003 (binary)
+    {
+      "Type": 10003,
+      "label": "hero",
+      "version": 3
+    }
+`
+	got := a.String()
+	if len(got) < len(want) || got[:len(want)] != want {
+		t.Fatalf("dump =\n%s", got)
+	}
+}
@@ -0,0 +1,174 @@
+package ecstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// versionField is the encoded name of DataComponentCore.VersionValue.
+const versionField = "version"
+
+// AssertHasComponent reports an error unless ent has a component of type ct.
+func AssertHasComponent(t testing.TB, ent ginka_ecs_go.Entity, ct ginka_ecs_go.ComponentType) bool {
+	t.Helper()
+	if !ent.Has(ct) {
+		t.Errorf("entity %s: missing component %d", ent.Id(), ct)
+		return false
+	}
+	return true
+}
+
+// AssertNoComponent reports an error if ent has a component of type ct.
+func AssertNoComponent(t testing.TB, ent ginka_ecs_go.Entity, ct ginka_ecs_go.ComponentType) bool {
+	t.Helper()
+	if ent.Has(ct) {
+		t.Errorf("entity %s: unexpected component %d", ent.Id(), ct)
+		return false
+	}
+	return true
+}
+
+// AssertDirty reports an error unless every type in types is dirty on ent.
+func AssertDirty(t testing.TB, ent ginka_ecs_go.DataEntity, types ...ginka_ecs_go.ComponentType) bool {
+	t.Helper()
+	dirty := make(map[ginka_ecs_go.ComponentType]bool)
+	for _, ct := range ent.DirtyTypes() {
+		dirty[ct] = true
+	}
+	ok := true
+	for _, ct := range types {
+		if !dirty[ct] {
+			t.Errorf("entity %s: component %d is not dirty (dirty: %v)", ent.Id(), ct, ent.DirtyTypes())
+			ok = false
+		}
+	}
+	return ok
+}
+
+// AssertClean reports an error if any component of ent is dirty.
+func AssertClean(t testing.TB, ent ginka_ecs_go.DataEntity) bool {
+	t.Helper()
+	if dirty := ent.DirtyTypes(); len(dirty) > 0 {
+		t.Errorf("entity %s: dirty components %v", ent.Id(), dirty)
+		return false
+	}
+	return true
+}
+
+// AssertComponentEqual reports an error unless ent has a component of
+// want's type whose JSON encoding equals want's, ignoring the version.
+// Differing fields are listed in the failure.
+func AssertComponentEqual(t testing.TB, ent ginka_ecs_go.Entity, want ginka_ecs_go.Component) bool {
+	t.Helper()
+	ct := want.ComponentType()
+	var got []byte
+	var found bool
+	var err error
+	read := func(e ginka_ecs_go.Entity) error {
+		c, ok := e.Get(ct)
+		if !ok {
+			return nil
+		}
+		found = true
+		got, err = json.Marshal(c)
+		return nil
+	}
+	if de, ok := ent.(ginka_ecs_go.DataEntity); ok {
+		_ = de.Tx(func(tx ginka_ecs_go.DataEntity) error { return read(tx) })
+	} else {
+		_ = read(ent)
+	}
+	if !found {
+		t.Errorf("entity %s: missing component %d", ent.Id(), ct)
+		return false
+	}
+	if err != nil {
+		t.Errorf("entity %s: component %d: encode: %v", ent.Id(), ct, err)
+		return false
+	}
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		t.Errorf("entity %s: component %d: encode expected: %v", ent.Id(), ct, err)
+		return false
+	}
+	diff, err := diffJSON(got, wantJSON)
+	if err != nil {
+		t.Errorf("entity %s: component %d: %v", ent.Id(), ct, err)
+		return false
+	}
+	if diff != "" {
+		t.Errorf("entity %s: component %d differs:\n%s", ent.Id(), ct, diff)
+		return false
+	}
+	return true
+}
+
+// diffJSON lists the top-level fields that differ between two JSON
+// objects, one "field: got X, want Y" line each, ignoring the version.
+// Non-object documents are compared whole.
+func diffJSON(got, want []byte) (string, error) {
+	var g, w map[string]json.RawMessage
+	if json.Unmarshal(got, &g) != nil || json.Unmarshal(want, &w) != nil {
+		if string(got) == string(want) {
+			return "", nil
+		}
+		return fmt.Sprintf("  got %s, want %s", got, want), nil
+	}
+	delete(g, versionField)
+	delete(w, versionField)
+
+	keys := make([]string, 0, len(g)+len(w))
+	for k := range g {
+		keys = append(keys, k)
+	}
+	for k := range w {
+		if _, ok := g[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		gv, gok := g[k]
+		wv, wok := w[k]
+		same, err := equalJSON(gv, wv)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", k, err)
+		}
+		if gok && wok && same {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: got %s, want %s\n", k, jsonOrMissing(gv, gok), jsonOrMissing(wv, wok))
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// equalJSON compares two JSON values independently of key order and spacing.
+func equalJSON(a, b json.RawMessage) (bool, error) {
+	if a == nil || b == nil {
+		return a == nil && b == nil, nil
+	}
+	var av, bv any
+	if err := json.Unmarshal(a, &av); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		return false, err
+	}
+	ac, _ := json.Marshal(av)
+	bc, _ := json.Marshal(bv)
+	return string(ac) == string(bc), nil
+}
+
+func jsonOrMissing(v json.RawMessage, ok bool) string {
+	if !ok {
+		return "<missing>"
+	}
+	return string(v)
+}
@@ -0,0 +1,138 @@
+package ecstest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+const testWalletType ginka_ecs_go.ComponentType = 1
+
+type testWallet struct {
+	ginka_ecs_go.DataComponentCore
+	Gold  int64  `json:"gold"`
+	Label string `json:"label"`
+}
+
+func (c *testWallet) StorageKey() string {
+	return "wallet"
+}
+
+func newTestWallet() *testWallet {
+	return &testWallet{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testWalletType)}
+}
+
+var walletSpec = ginka_ecs_go.ComponentSpec{
+	Name: "wallet",
+	Type: testWalletType,
+	New:  func() ginka_ecs_go.Component { return newTestWallet() },
+}
+
+// recordingTB captures assertion failures instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestWorld_FixturesAssertionsAndFlush(t *testing.T) {
+	w := NewWorld(t).
+		Components(walletSpec).
+		PrefabJSON([]byte(`{"name":"player","entity_type":1,"tags":["player"],"mark_dirty":true,"components":[{"type":"wallet","data":{"gold":10,"label":"starter"}}]}`)).
+		Started().
+		Build()
+	if !w.IsRunning() {
+		t.Fatalf("world is not running")
+	}
+
+	ents := w.SpawnFixtures([]byte(`[
+		{"id":"p1","prefab":"player"},
+		{"id":"p2","name":"Rich","prefab":"player","tags":["vip"],"disabled":true,"components":[{"type":"wallet","data":{"gold":500}}]}
+	]`))
+	if len(ents) != 2 {
+		t.Fatalf("spawned %d", len(ents))
+	}
+	p2 := w.Entity("p2")
+	if p2.Name() != "Rich" || !p2.HasTag("vip") || !p2.HasTag("player") || p2.Enabled() {
+		t.Fatalf("p2 = %s tags %v enabled %v", p2.Name(), p2.Tags(), p2.Enabled())
+	}
+
+	want := newTestWallet()
+	want.Gold, want.Label = 500, "starter"
+	AssertHasComponent(t, p2, testWalletType)
+	AssertComponentEqual(t, p2, want)
+	AssertDirty(t, p2, testWalletType)
+
+	rec := &recordingTB{TB: t}
+	want.Gold = 10
+	if AssertComponentEqual(rec, p2, want) || len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "gold: got 500, want 10") {
+		t.Fatalf("mismatch report = %q", rec.errors)
+	}
+	if AssertNoComponent(rec, p2, testWalletType) || AssertClean(rec, p2) || len(rec.errors) != 3 {
+		t.Fatalf("failures = %q", rec.errors)
+	}
+
+	stats := w.Flush()
+	if stats.Full != 2 || w.Store.Len() != 2 || w.Store.Saves() != 2 {
+		t.Fatalf("flush = %+v, stored %d", stats, w.Store.Len())
+	}
+	AssertClean(t, p2)
+	stored, ok := w.Store.Record("p2", "wallet")
+	if !ok || stored.Name != "wallet" {
+		t.Fatalf("stored = %+v", stored)
+	}
+	decoded, err := w.Registry.DecodeComponent(testWalletType, stored.Payload)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if decoded.(*testWallet).Gold != 500 {
+		t.Fatalf("stored gold = %d", decoded.(*testWallet).Gold)
+	}
+}
+
+func TestMemoryStore_Patches(t *testing.T) {
+	w := NewWorld(t).Components(walletSpec).DeltaPayloads(true).Build()
+	ent, err := w.Entities.Create(context.Background(), "p1", "p1", 1)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_ = ent.Add(newTestWallet())
+	ent.GetForUpdate(testWalletType)
+	w.Flush()
+	_ = ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+		c, _ := ginka_ecs_go.GetForUpdate[*testWallet](tx, testWalletType)
+		c.Gold = 7
+		return nil
+	})
+	w.Flush()
+	if w.Store.Saves() != 1 || w.Store.Patches() != 1 {
+		t.Fatalf("saves %d patches %d", w.Store.Saves(), w.Store.Patches())
+	}
+	var loaded []ginka_ecs_go.ComponentRecord
+	_ = w.Store.LoadComponents(context.Background(), func(rec ginka_ecs_go.ComponentRecord) error {
+		loaded = append(loaded, rec)
+		return nil
+	})
+	decoded, err := w.Registry.DecodeComponent(testWalletType, loaded[0].Payload)
+	if err != nil || decoded.(*testWallet).Gold != 7 {
+		t.Fatalf("decoded = %+v, %v", decoded, err)
+	}
+}
+
+func TestStress_MapEntityManager(t *testing.T) {
+	m := ginka_ecs_go.NewEntityManager(func(id string, name string, typ ginka_ecs_go.EntityType, tags ...ginka_ecs_go.Tag) (ginka_ecs_go.DataEntity, error) {
+		return ginka_ecs_go.NewDataEntityCore(id, name, typ, tags...), nil
+	}, 4)
+	report := Stress[ginka_ecs_go.DataEntity](t, m, StressConfig{Seed: 42})
+	if report.Creates == 0 || report.Removes == 0 || report.Txs == 0 || report.ForEachs == 0 {
+		t.Fatalf("report = %+v", report)
+	}
+}
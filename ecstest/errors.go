@@ -0,0 +1,10 @@
+package ecstest
+
+import "errors"
+
+var (
+	// ErrNotStored indicates a patch was sent for a component with no saved payload.
+	ErrNotStored = errors.New("component not stored")
+	// ErrUnknownPrefab indicates a fixture names a prefab the world does not have.
+	ErrUnknownPrefab = errors.New("unknown prefab")
+)
@@ -0,0 +1,123 @@
+package ecstest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// Fixture describes one entity spawned from a prefab.
+type Fixture struct {
+	Id string `json:"id"`
+	// Name defaults to Id.
+	Name   string `json:"name,omitempty"`
+	Prefab string `json:"prefab"`
+	// Tags are added to the prefab's tags.
+	Tags     []ginka_ecs_go.Tag `json:"tags,omitempty"`
+	Disabled bool               `json:"disabled,omitempty"`
+	// Components override prefab components of the same type. Data is
+	// decoded over the prefab's component, so only the listed fields
+	// change; components the prefab lacks are added.
+	Components []ginka_ecs_go.PrefabComponentSpec `json:"components,omitempty"`
+}
+
+// ParseFixtures decodes a JSON array of Fixtures. Unknown fields are rejected.
+func ParseFixtures(data []byte) ([]Fixture, error) {
+	var fixtures []Fixture
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fixtures); err != nil {
+		return nil, fmt.Errorf("parse fixtures: %w", err)
+	}
+	return fixtures, nil
+}
+
+// SpawnFixtures spawns fixtures into m from the named prefabs, building
+// component overrides with reg, and returns the entities in order.
+// Any error fails the test.
+func SpawnFixtures[T ginka_ecs_go.Entity](t testing.TB, m ginka_ecs_go.EntityManager[T], reg *ginka_ecs_go.ComponentRegistry, prefabs map[string]*ginka_ecs_go.Prefab, fixtures []Fixture) []T {
+	t.Helper()
+	out := make([]T, 0, len(fixtures))
+	for _, f := range fixtures {
+		ent, err := spawnFixture(m, reg, prefabs, f)
+		if err != nil {
+			t.Fatalf("ecstest: %v", err)
+		}
+		out = append(out, ent)
+	}
+	return out
+}
+
+func spawnFixture[T ginka_ecs_go.Entity](m ginka_ecs_go.EntityManager[T], reg *ginka_ecs_go.ComponentRegistry, prefabs map[string]*ginka_ecs_go.Prefab, f Fixture) (T, error) {
+	var zero T
+	if reg == nil {
+		reg = ginka_ecs_go.DefaultComponentRegistry
+	}
+	prefab, ok := prefabs[f.Prefab]
+	if !ok {
+		return zero, fmt.Errorf("fixture %s: prefab %s: %w", f.Id, f.Prefab, ErrUnknownPrefab)
+	}
+	name := f.Name
+	if name == "" {
+		name = f.Id
+	}
+
+	overrides := make([]ginka_ecs_go.Component, 0, len(f.Components))
+	for _, cs := range f.Components {
+		c, err := fixtureComponent(reg, prefab, cs)
+		if err != nil {
+			return zero, fmt.Errorf("fixture %s: %w", f.Id, err)
+		}
+		overrides = append(overrides, c)
+	}
+
+	// Extra tags go on a copy of the prefab so Spawn sets them before the
+	// entity is registered.
+	p := *prefab
+	p.Tags = append(append([]ginka_ecs_go.Tag(nil), prefab.Tags...), f.Tags...)
+	ent, err := ginka_ecs_go.Spawn(context.Background(), m, &p, f.Id, name, overrides...)
+	if err != nil {
+		return zero, fmt.Errorf("fixture %s: %w", f.Id, err)
+	}
+	if f.Disabled {
+		ent.SetEnabled(false)
+	}
+	return ent, nil
+}
+
+// fixtureComponent decodes cs over the prefab's component of the same type,
+// or over an empty one if the prefab has none.
+func fixtureComponent(reg *ginka_ecs_go.ComponentRegistry, prefab *ginka_ecs_go.Prefab, cs ginka_ecs_go.PrefabComponentSpec) (ginka_ecs_go.Component, error) {
+	spec, ok := reg.LookupName(cs.Type)
+	if !ok {
+		return nil, fmt.Errorf("component %s: %w", cs.Type, ginka_ecs_go.ErrUnknownComponentType)
+	}
+	var base ginka_ecs_go.Component
+	for _, factory := range prefab.Components {
+		c, err := factory()
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", cs.Type, err)
+		}
+		if c.ComponentType() == spec.Type {
+			base = c
+			break
+		}
+	}
+	if base == nil {
+		c, err := reg.New(spec.Type)
+		if err != nil {
+			return nil, err
+		}
+		base = c
+	}
+	if len(cs.Data) > 0 {
+		if err := json.Unmarshal(cs.Data, base); err != nil {
+			return nil, fmt.Errorf("component %s: %w", cs.Type, err)
+		}
+	}
+	return base, nil
+}
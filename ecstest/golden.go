@@ -0,0 +1,208 @@
+package ecstest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// UpdateEnv names the environment variable that makes golden assertions
+// rewrite their files instead of comparing:
+//
+//	ECSTEST_UPDATE=1 go test ./...
+//
+// ecstest registers no flags, so it cannot clash with a test binary's own.
+// A boolean -update flag the test package defines is honoured as well;
+// without that declaration go test -update fails:
+//
+//	var _ = flag.Bool("update", false, "rewrite golden files")
+const UpdateEnv = "ECSTEST_UPDATE"
+
+// Updating reports whether golden files are being rewritten, either
+// because UpdateEnv is set to a true value or because the test binary
+// defines an -update flag that is set.
+func Updating() bool {
+	if v, err := strconv.ParseBool(os.Getenv(UpdateEnv)); err == nil && v {
+		return true
+	}
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	v, ok := getter.Get().(bool)
+	return ok && v
+}
+
+// GoldenDir is the directory golden files are read from, relative to the
+// package under test.
+const GoldenDir = "testdata"
+
+// goldenContext is the number of unchanged lines shown around a difference.
+const goldenContext = 3
+
+// AssertGolden compares got with testdata/<name>.golden and reports a line
+// diff on mismatch. While Updating it writes got to the file instead.
+func AssertGolden(t testing.TB, name string, got []byte) bool {
+	t.Helper()
+	path := filepath.Join(GoldenDir, name+".golden")
+	if Updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("ecstest: golden %s: %v", path, err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("ecstest: golden %s: %v", path, err)
+		}
+		return true
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("ecstest: golden %s: %v (set ECSTEST_UPDATE=1 to create it)", path, err)
+		return false
+	}
+	if bytes.Equal(got, want) {
+		return true
+	}
+	t.Errorf("ecstest: %s differs (set ECSTEST_UPDATE=1 to accept):\n%s", path, LineDiff(string(want), string(got)))
+	return false
+}
+
+// AssertWorldGolden dumps every entity of m with ginka_ecs_go.DumpEntities
+// and compares the text with testdata/<name>.golden.
+func AssertWorldGolden[T ginka_ecs_go.Entity](t testing.TB, name string, m ginka_ecs_go.EntityManager[T], reg *ginka_ecs_go.ComponentRegistry) bool {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := ginka_ecs_go.DumpEntities(context.Background(), &buf, m, reg); err != nil {
+		t.Fatalf("ecstest: dump: %v", err)
+	}
+	return AssertGolden(t, name, buf.Bytes())
+}
+
+// AssertGolden compares the world's entities with testdata/<name>.golden.
+func (w *World) AssertGolden(name string) bool {
+	w.t.Helper()
+	return AssertWorldGolden[ginka_ecs_go.DataEntity](w.t, name, w.Entities, w.Registry)
+}
+
+// LineDiff returns a unified-style diff from want to got: removed lines
+// start with "-", added lines with "+", and hunks carry a few unchanged
+// lines of context and "@@ -line +line @@" headers.
+func LineDiff(want, got string) string {
+	a := splitLines(want)
+	b := splitLines(got)
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(i-goldenContext, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*goldenContext {
+				end = min(end+goldenContext, len(ops))
+				break
+			}
+			end = next
+		}
+		fmt.Fprintf(&out, "@@ -%d +%d @@\n", ops[start].a+1, ops[start].b+1)
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			out.WriteByte('\n')
+		}
+		i = end
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+type diffOp struct {
+	kind byte
+	line string
+	// a and b are the line indexes in want and got where the op applies.
+	a, b int
+}
+
+// diffLines aligns a and b on their longest common subsequence.
+// Inputs too large for the quadratic table are compared line by line.
+func diffLines(a, b []string) []diffOp {
+	if len(a)*len(b) > 4_000_000 {
+		return diffNaive(a, b)
+	}
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', line: a[i], a: i, b: j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{kind: '-', line: a[i], a: i, b: j})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', line: b[j], a: i, b: j})
+			j++
+		}
+	}
+	return ops
+}
+
+func diffNaive(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for i := 0; i < max(len(a), len(b)); i++ {
+		switch {
+		case i < len(a) && i < len(b) && a[i] == b[i]:
+			ops = append(ops, diffOp{kind: ' ', line: a[i], a: i, b: i})
+		default:
+			if i < len(a) {
+				ops = append(ops, diffOp{kind: '-', line: a[i], a: i, b: i})
+			}
+			if i < len(b) {
+				ops = append(ops, diffOp{kind: '+', line: b[i], a: i, b: i})
+			}
+		}
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
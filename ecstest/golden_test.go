@@ -0,0 +1,72 @@
+package ecstest
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// update is the idiomatic flag of a consumer package; ecstest must not
+// clash with it.
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestUpdating_ReadsEnvAndUpdateFlag(t *testing.T) {
+	if *update || Updating() {
+		t.Skip("golden files are being rewritten")
+	}
+	t.Setenv(UpdateEnv, "1")
+	if !Updating() {
+		t.Fatalf("env not honoured")
+	}
+	t.Setenv(UpdateEnv, "")
+	_ = flag.Set("update", "true")
+	defer flag.Set("update", "false")
+	if !Updating() {
+		t.Fatalf("-update flag not honoured")
+	}
+}
+
+func TestWorld_AssertGolden(t *testing.T) {
+	w := NewWorld(t).
+		Components(walletSpec).
+		PrefabJSON([]byte(`{"name":"player","entity_type":1,"tags":["player"],"components":[{"type":"wallet","data":{"gold":10,"label":"starter"}}]}`)).
+		Build()
+	w.SpawnFixtures([]byte(`[
+		{"id":"p2","name":"Rich","prefab":"player","tags":["vip"],"disabled":true,"components":[{"type":"wallet","data":{"gold":500}}]},
+		{"id":"p1","prefab":"player"}
+	]`))
+	w.AssertGolden("world")
+	if Updating() {
+		return
+	}
+
+	_ = w.Entity("p1").Tx(func(tx ginka_ecs_go.DataEntity) error {
+		c, _ := ginka_ecs_go.GetForUpdate[*testWallet](tx, testWalletType)
+		c.Gold = 11
+		return nil
+	})
+	rec := &recordingTB{TB: t}
+	if AssertWorldGolden[ginka_ecs_go.DataEntity](rec, "world", w.Entities, w.Registry) || len(rec.errors) != 1 {
+		t.Fatalf("expected one mismatch, got %q", rec.errors)
+	}
+	for _, line := range []string{`-      "gold": 10,`, `+      "gold": 11,`, `-      "version": 0`, `+      "version": 1`} {
+		if !strings.Contains(rec.errors[0], line) {
+			t.Fatalf("diff lacks %q:\n%s", line, rec.errors[0])
+		}
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	want := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	got := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	diff := LineDiff(want, got)
+	expected := "@@ -1 +1 @@\n a\n-b\n+B\n c\n d\n e\n@@ -8 +8 @@\n h\n i\n j\n+k"
+	if diff != expected {
+		t.Fatalf("diff =\n%s", diff)
+	}
+	if LineDiff(want, want) != "" {
+		t.Fatalf("expected no diff")
+	}
+}
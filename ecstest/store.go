@@ -0,0 +1,147 @@
+package ecstest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// MemoryStore is an in-memory ginka_ecs_go.PatchStore and ComponentSource.
+// It keeps the latest full payload per entity and storage key, with patches
+// applied. It is safe for concurrent use.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[storeKey]ginka_ecs_go.ComponentRecord
+	saves   int
+	patches int
+	fail    error
+}
+
+type storeKey struct {
+	entityId   string
+	storageKey string
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[storeKey]ginka_ecs_go.ComponentRecord)}
+}
+
+// SetFail makes every later write return err. Nil restores normal operation.
+func (s *MemoryStore) SetFail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = err
+}
+
+// SaveComponent implements ginka_ecs_go.ComponentStore.
+func (s *MemoryStore) SaveComponent(ctx context.Context, rec ginka_ecs_go.ComponentRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		return s.fail
+	}
+	rec.Payload = append([]byte(nil), rec.Payload...)
+	s.records[storeKey{entityId: rec.EntityId, storageKey: rec.StorageKey}] = rec
+	s.saves++
+	return nil
+}
+
+// PatchComponent implements ginka_ecs_go.PatchStore. It returns ErrNotStored
+// if the component was never saved.
+func (s *MemoryStore) PatchComponent(ctx context.Context, rec ginka_ecs_go.ComponentRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		return s.fail
+	}
+	key := storeKey{entityId: rec.EntityId, storageKey: rec.StorageKey}
+	prev, ok := s.records[key]
+	if !ok {
+		return fmt.Errorf("patch %s/%s: %w", rec.EntityId, rec.StorageKey, ErrNotStored)
+	}
+	patched, err := ginka_ecs_go.ApplyPayloadPatch(prev.Payload, rec.Payload)
+	if err != nil {
+		return fmt.Errorf("patch %s/%s: %w", rec.EntityId, rec.StorageKey, err)
+	}
+	rec.Payload = patched
+	s.records[key] = rec
+	s.patches++
+	return nil
+}
+
+// LoadComponents implements ginka_ecs_go.ComponentSource. Records are
+// passed in entity id, then storage key order.
+func (s *MemoryStore) LoadComponents(ctx context.Context, fn func(rec ginka_ecs_go.ComponentRecord) error) error {
+	for _, rec := range s.Records() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record returns the stored record of an entity's component.
+func (s *MemoryStore) Record(entityId string, storageKey string) (ginka_ecs_go.ComponentRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[storeKey{entityId: entityId, storageKey: storageKey}]
+	if ok {
+		rec.Payload = append([]byte(nil), rec.Payload...)
+	}
+	return rec, ok
+}
+
+// Records returns copies of all stored records in entity id, then storage key order.
+func (s *MemoryStore) Records() []ginka_ecs_go.ComponentRecord {
+	s.mu.Lock()
+	out := make([]ginka_ecs_go.ComponentRecord, 0, len(s.records))
+	for _, rec := range s.records {
+		rec.Payload = append([]byte(nil), rec.Payload...)
+		out = append(out, rec)
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].EntityId != out[j].EntityId {
+			return out[i].EntityId < out[j].EntityId
+		}
+		return out[i].StorageKey < out[j].StorageKey
+	})
+	return out
+}
+
+// Len returns the number of stored components.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
+
+// Saves returns the number of successful full writes.
+func (s *MemoryStore) Saves() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saves
+}
+
+// Patches returns the number of successful partial writes.
+func (s *MemoryStore) Patches() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.patches
+}
+
+var _ ginka_ecs_go.PatchStore = (*MemoryStore)(nil)
+var _ ginka_ecs_go.ComponentSource = (*MemoryStore)(nil)
@@ -0,0 +1,248 @@
+package ecstest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// DefaultStressComponentType is the ComponentType of the counter component
+// the stress harness attaches to the entities it creates, reserved in the
+// built-in list as ginka_ecs_go.StressComponentType.
+const DefaultStressComponentType = ginka_ecs_go.StressComponentType
+
+// StressConfig tunes Stress. Zero fields use the defaults noted.
+type StressConfig struct {
+	// Workers is the number of concurrent goroutines. Default 8.
+	Workers int
+	// Ops is the number of operations per worker. Default 500.
+	Ops int
+	// Ids is the number of distinct entity ids workers contend on. Default 32.
+	Ids int
+	// Seed makes the operation mix reproducible. Worker i uses Seed+i.
+	Seed int64
+	// EntityType is the type of created entities.
+	EntityType ginka_ecs_go.EntityType
+	// ComponentType is the type of the counter component.
+	// Default DefaultStressComponentType.
+	ComponentType ginka_ecs_go.ComponentType
+}
+
+// StressReport counts the operations a Stress run performed.
+type StressReport struct {
+	Creates int64
+	// Duplicates counts creates rejected because the id was registered.
+	Duplicates int64
+	Removes    int64
+	Txs        int64
+	ForEachs   int64
+	// Visited is the number of entities seen by ForEach passes.
+	Visited int64
+}
+
+// stressCounter is incremented inside Tx. Applied counts committed
+// increments outside the entity lock, so lost updates show as N != Applied.
+type stressCounter struct {
+	ginka_ecs_go.DataComponentCore
+	N       int64
+	applied atomic.Int64
+}
+
+func (c *stressCounter) StorageKey() string {
+	return "ecstest.stress"
+}
+
+// Stress runs a random mix of Create, Remove, Tx and ForEach on m from
+// several goroutines, then checks the manager's invariants:
+//
+//   - entities are fully initialized before ForEach can see them;
+//   - a ForEach pass never visits an id twice;
+//   - Len, Get, Generation and ForEach agree once workers stop;
+//   - no Tx update is lost: each counter equals its committed increments,
+//     its version and dirty flag match.
+//
+// m should start empty of the configured ids. Violations fail the test.
+// Run it under -race to also catch unsynchronized access.
+func Stress[T ginka_ecs_go.DataEntity](t testing.TB, m ginka_ecs_go.EntityManager[T], cfg StressConfig) StressReport {
+	t.Helper()
+	if cfg.Workers <= 0 {
+		cfg.Workers = 8
+	}
+	if cfg.Ops <= 0 {
+		cfg.Ops = 500
+	}
+	if cfg.Ids <= 0 {
+		cfg.Ids = 32
+	}
+	if cfg.ComponentType == 0 {
+		cfg.ComponentType = DefaultStressComponentType
+	}
+	ids := make([]string, cfg.Ids)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("stress-%d", i)
+	}
+
+	var report StressReport
+	var errMu sync.Mutex
+	var errs []error
+	fail := func(err error) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		errs = append(errs, err)
+	}
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(cfg.Seed + int64(worker)))
+			for op := 0; op < cfg.Ops; op++ {
+				id := ids[rng.Intn(len(ids))]
+				switch n := rng.Intn(100); {
+				case n < 20:
+					stressCreate(ctx, m, id, cfg, &report, fail)
+				case n < 30:
+					if m.Remove(id) {
+						atomic.AddInt64(&report.Removes, 1)
+					}
+				case n < 85:
+					stressTx(m, id, cfg, &report, fail)
+				default:
+					stressForEach(ctx, m, cfg, &report, fail)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, err := range stressCheck(ctx, m, cfg) {
+		fail(err)
+	}
+	for _, err := range errs {
+		t.Errorf("ecstest: stress: %v", err)
+	}
+	return report
+}
+
+func stressCreate[T ginka_ecs_go.DataEntity](ctx context.Context, m ginka_ecs_go.EntityManager[T], id string, cfg StressConfig, report *StressReport, fail func(error)) {
+	ent, err := m.NewEntity(id, id, cfg.EntityType)
+	if err != nil {
+		fail(fmt.Errorf("new entity %s: %w", id, err))
+		return
+	}
+	if err := ent.Add(&stressCounter{DataComponentCore: ginka_ecs_go.NewDataComponentCore(cfg.ComponentType)}); err != nil {
+		fail(fmt.Errorf("add counter to %s: %w", id, err))
+		return
+	}
+	err = m.Add(ctx, ent)
+	switch {
+	case err == nil:
+		atomic.AddInt64(&report.Creates, 1)
+	case errors.Is(err, ginka_ecs_go.ErrEntityAlreadyExists):
+		atomic.AddInt64(&report.Duplicates, 1)
+	default:
+		fail(fmt.Errorf("add entity %s: %w", id, err))
+	}
+}
+
+func stressTx[T ginka_ecs_go.DataEntity](m ginka_ecs_go.EntityManager[T], id string, cfg StressConfig, report *StressReport, fail func(error)) {
+	ent, ok := m.Get(id)
+	if !ok {
+		return
+	}
+	var counter *stressCounter
+	err := ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+		c, ok, err := ginka_ecs_go.GetForUpdateE[*stressCounter](tx, cfg.ComponentType)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("entity %s: counter: %w", id, ginka_ecs_go.ErrComponentNotFound)
+		}
+		n := c.N
+		// Widen the read-modify-write window so a missing lock loses updates.
+		runtime.Gosched()
+		c.N = n + 1
+		counter = c
+		return nil
+	})
+	if err != nil {
+		fail(fmt.Errorf("tx %s: %w", id, err))
+		return
+	}
+	counter.applied.Add(1)
+	atomic.AddInt64(&report.Txs, 1)
+}
+
+func stressForEach[T ginka_ecs_go.DataEntity](ctx context.Context, m ginka_ecs_go.EntityManager[T], cfg StressConfig, report *StressReport, fail func(error)) {
+	seen := make(map[string]struct{})
+	err := m.ForEach(ctx, func(ent T) error {
+		if _, dup := seen[ent.Id()]; dup {
+			return fmt.Errorf("for each visited %s twice", ent.Id())
+		}
+		seen[ent.Id()] = struct{}{}
+		if !ent.Has(cfg.ComponentType) {
+			return fmt.Errorf("for each visited %s before its counter was attached", ent.Id())
+		}
+		return nil
+	})
+	if err != nil {
+		fail(err)
+		return
+	}
+	atomic.AddInt64(&report.ForEachs, 1)
+	atomic.AddInt64(&report.Visited, int64(len(seen)))
+}
+
+// stressCheck verifies the manager once all workers have stopped.
+func stressCheck[T ginka_ecs_go.DataEntity](ctx context.Context, m ginka_ecs_go.EntityManager[T], cfg StressConfig) []error {
+	var errs []error
+	visited := 0
+	err := m.ForEach(ctx, func(ent T) error {
+		visited++
+		id := ent.Id()
+		if got, ok := m.Get(id); !ok || ginka_ecs_go.Entity(got) != ginka_ecs_go.Entity(ent) {
+			errs = append(errs, fmt.Errorf("entity %s: Get disagrees with ForEach", id))
+		}
+		if got, gen, ok := m.GetWithGeneration(id); !ok || gen == 0 || ginka_ecs_go.Entity(got) != ginka_ecs_go.Entity(ent) {
+			errs = append(errs, fmt.Errorf("entity %s: GetWithGeneration disagrees with ForEach", id))
+		}
+		return ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+			c, ok := ginka_ecs_go.Get[*stressCounter](tx, cfg.ComponentType)
+			if !ok {
+				errs = append(errs, fmt.Errorf("entity %s: counter missing", id))
+				return nil
+			}
+			if applied := c.applied.Load(); c.N != applied {
+				errs = append(errs, fmt.Errorf("entity %s: counter %d after %d committed increments", id, c.N, applied))
+			}
+			if c.Version() != uint64(c.N) {
+				errs = append(errs, fmt.Errorf("entity %s: version %d after %d increments", id, c.Version(), c.N))
+			}
+			dirty := false
+			for _, ct := range tx.DirtyTypes() {
+				dirty = dirty || ct == cfg.ComponentType
+			}
+			if dirty != (c.N > 0) {
+				errs = append(errs, fmt.Errorf("entity %s: dirty %v after %d increments", id, dirty, c.N))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if n := m.Len(); n != visited {
+		errs = append(errs, fmt.Errorf("Len %d, ForEach visited %d", n, visited))
+	}
+	return errs
+}
@@ -0,0 +1,239 @@
+// Package ecstest provides helpers for testing code built on ginka_ecs_go:
+// a world builder with an in-memory store, entity fixtures spawned from
+// prefabs, component assertions and a stress harness that exercises an
+// EntityManager concurrently and checks its invariants. Run the harness
+// with -race.
+//
+// Golden files are rewritten with ECSTEST_UPDATE=1 go test ./... . Plain
+// go test -update fails with "flag provided but not defined" unless the
+// test package declares the flag itself, in any of its _test.go files:
+//
+//	var _ = flag.Bool("update", false, "rewrite golden files")
+package ecstest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// StartTimeout bounds how long StartWorld waits for a world to run.
+const StartTimeout = 2 * time.Second
+
+// StartWorld runs w on a new goroutine and waits until it is running.
+// The world is stopped when the test finishes, and an error returned by
+// Run fails the test.
+func StartWorld(t testing.TB, w ginka_ecs_go.World) {
+	t.Helper()
+	runDone := make(chan error, 1)
+	go func() {
+		runDone <- w.Run()
+	}()
+	WaitForRunning(t, w)
+	t.Cleanup(func() {
+		if err := w.Stop(); err != nil {
+			t.Errorf("stop world %s: %v", w.GetName(), err)
+		}
+		if err := <-runDone; err != nil {
+			t.Errorf("run world %s: %v", w.GetName(), err)
+		}
+	})
+}
+
+// WaitForRunning fails the test unless w is running within StartTimeout.
+func WaitForRunning(t testing.TB, w ginka_ecs_go.World) {
+	t.Helper()
+	deadline := time.NewTimer(StartTimeout)
+	defer deadline.Stop()
+	for {
+		if w.IsRunning() {
+			return
+		}
+		select {
+		case <-deadline.C:
+			t.Fatalf("world %s did not start", w.GetName())
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+// World is a CoreWorld with a DataEntity manager, a component registry and
+// a Persister writing to a MemoryStore. Its helpers fail the test on error.
+type World struct {
+	*ginka_ecs_go.CoreWorld
+	Entities  *ginka_ecs_go.MapEntityManager[ginka_ecs_go.DataEntity]
+	Registry  *ginka_ecs_go.ComponentRegistry
+	Store     *MemoryStore
+	Persister *ginka_ecs_go.Persister
+
+	t       testing.TB
+	prefabs map[string]*ginka_ecs_go.Prefab
+}
+
+// WorldBuilder configures a World. Its methods return the builder so calls
+// can be chained; Build creates the world.
+type WorldBuilder struct {
+	t          testing.TB
+	name       string
+	shards     int
+	registry   *ginka_ecs_go.ComponentRegistry
+	specs      []ginka_ecs_go.ComponentSpec
+	prefabs    []*ginka_ecs_go.Prefab
+	prefabJSON [][]byte
+	deltas     bool
+	start      bool
+}
+
+// NewWorld starts configuring a World named after the test.
+func NewWorld(t testing.TB) *WorldBuilder {
+	return &WorldBuilder{t: t, name: t.Name(), shards: 4}
+}
+
+// Name sets the world name.
+func (b *WorldBuilder) Name(name string) *WorldBuilder {
+	b.name = name
+	return b
+}
+
+// Shards sets the entity manager's shard count.
+func (b *WorldBuilder) Shards(n int) *WorldBuilder {
+	b.shards = n
+	return b
+}
+
+// Registry makes the world use reg instead of a fresh registry.
+func (b *WorldBuilder) Registry(reg *ginka_ecs_go.ComponentRegistry) *WorldBuilder {
+	b.registry = reg
+	return b
+}
+
+// Components registers specs in the world's registry.
+func (b *WorldBuilder) Components(specs ...ginka_ecs_go.ComponentSpec) *WorldBuilder {
+	b.specs = append(b.specs, specs...)
+	return b
+}
+
+// Prefabs adds prefabs that Spawn and fixtures refer to by name.
+func (b *WorldBuilder) Prefabs(prefabs ...*ginka_ecs_go.Prefab) *WorldBuilder {
+	b.prefabs = append(b.prefabs, prefabs...)
+	return b
+}
+
+// PrefabJSON adds prefabs described as JSON (see ginka_ecs_go.LoadPrefab).
+// They are decoded with the world's registry once components are registered.
+func (b *WorldBuilder) PrefabJSON(data ...[]byte) *WorldBuilder {
+	b.prefabJSON = append(b.prefabJSON, data...)
+	return b
+}
+
+// DeltaPayloads enables partial updates on the world's Persister.
+func (b *WorldBuilder) DeltaPayloads(enabled bool) *WorldBuilder {
+	b.deltas = enabled
+	return b
+}
+
+// Started makes Build run the world with StartWorld.
+func (b *WorldBuilder) Started() *WorldBuilder {
+	b.start = true
+	return b
+}
+
+// Build creates the World, failing the test on configuration errors.
+func (b *WorldBuilder) Build() *World {
+	t := b.t
+	t.Helper()
+	reg := b.registry
+	if reg == nil {
+		reg = ginka_ecs_go.NewComponentRegistry()
+	}
+	for _, spec := range b.specs {
+		if err := reg.Register(spec); err != nil {
+			t.Fatalf("ecstest: %v", err)
+		}
+	}
+
+	store := NewMemoryStore()
+	persister := ginka_ecs_go.NewPersister(store)
+	persister.SetRegistry(reg)
+	persister.SetDeltaPayloads(b.deltas)
+	w := &World{
+		CoreWorld: ginka_ecs_go.NewCoreWorld(b.name),
+		Entities: ginka_ecs_go.NewEntityManager(func(id string, name string, typ ginka_ecs_go.EntityType, tags ...ginka_ecs_go.Tag) (ginka_ecs_go.DataEntity, error) {
+			return ginka_ecs_go.NewDataEntityCore(id, name, typ, tags...), nil
+		}, b.shards),
+		Registry:  reg,
+		Store:     store,
+		Persister: persister,
+		t:         t,
+		prefabs:   make(map[string]*ginka_ecs_go.Prefab, len(b.prefabs)+len(b.prefabJSON)),
+	}
+	ginka_ecs_go.AttachPersister[ginka_ecs_go.DataEntity](w.Entities, persister)
+	for _, p := range b.prefabs {
+		w.prefabs[p.Name] = p
+	}
+	for _, data := range b.prefabJSON {
+		p, err := ginka_ecs_go.LoadPrefab(data, reg)
+		if err != nil {
+			t.Fatalf("ecstest: %v", err)
+		}
+		w.prefabs[p.Name] = p
+	}
+	if b.start {
+		StartWorld(t, w)
+	}
+	return w
+}
+
+// Prefab returns the prefab registered under name.
+func (w *World) Prefab(name string) (*ginka_ecs_go.Prefab, bool) {
+	p, ok := w.prefabs[name]
+	return p, ok
+}
+
+// Spawn creates an entity from the named prefab.
+func (w *World) Spawn(prefab string, id string, overrides ...ginka_ecs_go.Component) ginka_ecs_go.DataEntity {
+	w.t.Helper()
+	p, ok := w.prefabs[prefab]
+	if !ok {
+		w.t.Fatalf("ecstest: spawn %s: prefab %s: %v", id, prefab, ErrUnknownPrefab)
+	}
+	ent, err := ginka_ecs_go.Spawn[ginka_ecs_go.DataEntity](context.Background(), w.Entities, p, id, id, overrides...)
+	if err != nil {
+		w.t.Fatalf("ecstest: %v", err)
+	}
+	return ent
+}
+
+// SpawnFixtures spawns the entities described by the JSON fixtures in data
+// (see ParseFixtures) and returns them in order.
+func (w *World) SpawnFixtures(data []byte) []ginka_ecs_go.DataEntity {
+	w.t.Helper()
+	fixtures, err := ParseFixtures(data)
+	if err != nil {
+		w.t.Fatalf("ecstest: %v", err)
+	}
+	return SpawnFixtures[ginka_ecs_go.DataEntity](w.t, w.Entities, w.Registry, w.prefabs, fixtures)
+}
+
+// Entity returns the entity registered under id.
+func (w *World) Entity(id string) ginka_ecs_go.DataEntity {
+	w.t.Helper()
+	ent, ok := w.Entities.Get(id)
+	if !ok {
+		w.t.Fatalf("ecstest: entity %s: %v", id, ginka_ecs_go.ErrEntityNotFound)
+	}
+	return ent
+}
+
+// Flush persists every dirty component to the world's Store.
+func (w *World) Flush() ginka_ecs_go.FlushStats {
+	w.t.Helper()
+	stats, err := ginka_ecs_go.FlushAll[ginka_ecs_go.DataEntity](context.Background(), w.Entities, w.Persister)
+	if err != nil {
+		w.t.Fatalf("ecstest: flush: %v", err)
+	}
+	return stats
+}
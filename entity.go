@@ -42,6 +42,9 @@ type EntityManager[T Entity] interface {
 	// Create makes a new entity with the given id.
 	// The id typically comes from somewhere else (e.g. player id from auth).
 	Create(ctx context.Context, id string, name string, typ EntityType, tags ...Tag) (T, error)
+	// NewEntity allocates an entity without registering it,
+	// so it can be fully initialized before Add makes it visible.
+	NewEntity(id string, name string, typ EntityType, tags ...Tag) (T, error)
 	// Add registers an existing entity (e.g. loaded from DB).
 	Add(ctx context.Context, ent T) error
 	// Get fetches an entity by id.
@@ -50,6 +53,13 @@ type EntityManager[T Entity] interface {
 	MustGet(id string) T
 	// Remove deletes an entity by id.
 	Remove(id string) bool
+	// OnRemove registers fn to run after an entity is removed.
+	// The returned func unregisters the hook.
+	OnRemove(fn func(ent T)) func()
+	// GetWithGeneration fetches an entity by id together with its incarnation
+	// number, read atomically. A removed and re-created id gets a different
+	// generation.
+	GetWithGeneration(id string) (T, uint64, bool)
 	// Len returns the total count.
 	Len() int
 	// ForEach runs fn on every entity.
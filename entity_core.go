@@ -19,6 +19,8 @@ type EntityCore struct {
 
 	components     map[ComponentType]Component
 	componentTypes []ComponentType
+	ticks          map[ComponentType]ComponentTicks
+	stateTick      Tick
 }
 
 func NewEntityCore(id string, name string, typ EntityType, tags ...Tag) *EntityCore {
@@ -28,6 +30,7 @@ func NewEntityCore(id string, name string, typ EntityType, tags ...Tag) *EntityC
 		typ:            typ,
 		components:     make(map[ComponentType]Component),
 		componentTypes: nil,
+		ticks:          make(map[ComponentType]ComponentTicks),
 	}
 	e.SetTags(tags...)
 	return e
@@ -157,12 +160,37 @@ func (e *EntityCore) AllComponents() []Component {
 	return e.allComponentsUnlocked()
 }
 
+// ComponentTicks returns when the component of type t was added and last changed.
+func (e *EntityCore) ComponentTicks(t ComponentType) (ComponentTicks, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.componentTicksUnlocked(t)
+}
+
+// MarkChanged records a change of the component of type t.
+func (e *EntityCore) MarkChanged(t ComponentType) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.markChangedUnlocked(t)
+}
+
+// StateTick returns the tick of the entity's latest recorded change.
+func (e *EntityCore) StateTick() Tick {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.stateTick
+}
+
 func (e *EntityCore) enabledUnlocked() bool {
 	return e.EnabledFlag.Enabled()
 }
 
 func (e *EntityCore) setEnabledUnlocked(enabled bool) {
+	if e.EnabledFlag.Enabled() == enabled {
+		return
+	}
 	e.EnabledFlag.SetEnabled(enabled)
+	e.touchUnlocked()
 }
 
 func (e *EntityCore) tagsUnlocked() []Tag {
@@ -174,19 +202,29 @@ func (e *EntityCore) hasTagUnlocked(tag Tag) bool {
 }
 
 func (e *EntityCore) addTagUnlocked(tag Tag) bool {
-	return e.TagSet.AddTag(tag)
+	if !e.TagSet.AddTag(tag) {
+		return false
+	}
+	e.touchUnlocked()
+	return true
 }
 
 func (e *EntityCore) removeTagUnlocked(tag Tag) bool {
-	return e.TagSet.RemoveTag(tag)
+	if !e.TagSet.RemoveTag(tag) {
+		return false
+	}
+	e.touchUnlocked()
+	return true
 }
 
 func (e *EntityCore) clearTagsUnlocked() {
 	e.TagSet.ClearTags()
+	e.touchUnlocked()
 }
 
 func (e *EntityCore) setTagsUnlocked(tags ...Tag) {
 	e.TagSet.SetTags(tags...)
+	e.touchUnlocked()
 }
 
 func (e *EntityCore) getComponentUnlocked(t ComponentType) (Component, bool) {
@@ -207,12 +245,19 @@ func (e *EntityCore) addComponentUnlocked(c Component) error {
 	}
 	e.components[t] = c
 	e.componentTypes = append(e.componentTypes, t)
+	if e.ticks == nil {
+		e.ticks = make(map[ComponentType]ComponentTicks)
+	}
+	tick := nextTick()
+	e.ticks[t] = ComponentTicks{Added: tick, Changed: tick}
+	e.stateTick = tick
 	return nil
 }
 
 func (e *EntityCore) removeComponentUnlocked(t ComponentType) bool {
 	_, ok := e.components[t]
 	delete(e.components, t)
+	delete(e.ticks, t)
 	if ok {
 		for i, existing := range e.componentTypes {
 			if existing == t {
@@ -220,6 +265,7 @@ func (e *EntityCore) removeComponentUnlocked(t ComponentType) bool {
 				break
 			}
 		}
+		e.touchUnlocked()
 	}
 	return ok
 }
@@ -240,11 +286,13 @@ func (e *EntityCore) removeComponentsUnlocked(types []ComponentType) int {
 			continue
 		}
 		delete(e.components, t)
+		delete(e.ticks, t)
 		count++
 	}
 	if count == 0 {
 		return 0
 	}
+	e.touchUnlocked()
 
 	filtered := e.componentTypes[:0]
 	for _, t := range e.componentTypes {
@@ -275,5 +323,28 @@ func (e *EntityCore) allComponentsUnlocked() []Component {
 	return out
 }
 
+func (e *EntityCore) componentTicksUnlocked(t ComponentType) (ComponentTicks, bool) {
+	ticks, ok := e.ticks[t]
+	return ticks, ok
+}
+
+func (e *EntityCore) markChangedUnlocked(t ComponentType) bool {
+	ticks, ok := e.ticks[t]
+	if !ok {
+		return false
+	}
+	ticks.Changed = nextTick()
+	e.ticks[t] = ticks
+	e.stateTick = ticks.Changed
+	return true
+}
+
+// touchUnlocked records a change to the entity's own state.
+func (e *EntityCore) touchUnlocked() {
+	e.stateTick = nextTick()
+}
+
 // Compile-time interface checks.
 var _ Entity = (*EntityCore)(nil)
+var _ ChangeTracker = (*EntityCore)(nil)
+var _ StateTracker = (*EntityCore)(nil)
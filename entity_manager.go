@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
+	"strconv"
 	"sync"
+	"sync/atomic"
 )
 
 // EntityFactory is a function type that creates new entities of type T.
@@ -20,11 +23,27 @@ type MapEntityManager[T Entity] struct {
 	shards    []entityShard[T]
 	shardMask uint64
 	factory   EntityFactory[T]
+	nextGen   atomic.Uint64
+
+	hooksMu     sync.RWMutex
+	removeHooks []entityHook[T]
+	nextHookId  uint64
+
+	metrics     atomic.Pointer[metricsHolder]
+	shardLabels []string
+	tracer      atomic.Pointer[tracerHolder]
+	logger      atomic.Pointer[loggerHolder]
+}
+
+type entityHook[T Entity] struct {
+	id uint64
+	fn func(ent T)
 }
 
 type entityShard[T Entity] struct {
 	mu   sync.RWMutex
 	byId map[string]T
+	gens map[string]uint64
 }
 
 const defaultEntityShardCount = 128
@@ -39,22 +58,122 @@ func NewEntityManager[T Entity](factory EntityFactory[T], shardCount int) *MapEn
 		count = defaultEntityShardCount
 	}
 	shards := make([]entityShard[T], int(count))
+	shardLabels := make([]string, len(shards))
 	for i := range shards {
 		shards[i].byId = make(map[string]T)
+		shards[i].gens = make(map[string]uint64)
+		shardLabels[i] = strconv.Itoa(i)
 	}
 	return &MapEntityManager[T]{
-		shards:    shards,
-		shardMask: count - 1,
-		factory:   factory,
+		shards:      shards,
+		shardMask:   count - 1,
+		factory:     factory,
+		shardLabels: shardLabels,
+	}
+}
+
+// SetMetrics sets where the manager reports per-shard entity counts.
+// Nil uses DefaultMetrics.
+func (m *MapEntityManager[T]) SetMetrics(metrics Metrics) {
+	if metrics == nil {
+		m.metrics.Store(nil)
+		return
+	}
+	m.metrics.Store(&metricsHolder{m: metrics})
+}
+
+func (m *MapEntityManager[T]) currentMetrics() Metrics {
+	if h := m.metrics.Load(); h != nil {
+		return h.m
+	}
+	return DefaultMetrics()
+}
+
+// SetTracer sets the Tracer for Create, Add and ForEach spans.
+// Nil uses DefaultTracer.
+func (m *MapEntityManager[T]) SetTracer(t Tracer) {
+	if t == nil {
+		m.tracer.Store(nil)
+		return
+	}
+	m.tracer.Store(&tracerHolder{t: t})
+}
+
+func (m *MapEntityManager[T]) currentTracer() Tracer {
+	if h := m.tracer.Load(); h != nil {
+		return h.t
+	}
+	return DefaultTracer()
+}
+
+// SetLogger sets the logger for entity additions and removals, which are
+// logged at debug level. Nil uses DefaultLogger.
+func (m *MapEntityManager[T]) SetLogger(l *slog.Logger) {
+	if l == nil {
+		m.logger.Store(nil)
+		return
+	}
+	m.logger.Store(&loggerHolder{l: l})
+}
+
+func (m *MapEntityManager[T]) currentLogger() *slog.Logger {
+	if h := m.logger.Load(); h != nil {
+		return h.l
 	}
+	return DefaultLogger()
+}
+
+// logEntity logs msg about ent at debug level.
+func (m *MapEntityManager[T]) logEntity(ctx context.Context, msg string, ent T) {
+	logger := m.currentLogger()
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	logger.LogAttrs(ctx, slog.LevelDebug, msg, LogEntity(ent))
+}
+
+// reportShardLocked publishes the entity count of shard idx.
+func (m *MapEntityManager[T]) reportShardLocked(idx int) {
+	metrics := m.currentMetrics()
+	if isNopMetrics(metrics) {
+		return
+	}
+	metrics.SetGauge(MetricEntities, float64(len(m.shards[idx].byId)), "shard", m.shardLabels[idx])
 }
 
 // Create allocates and registers a new entity with the given parameters.
-func (m *MapEntityManager[T]) Create(ctx context.Context, id string, name string, typ EntityType, tags ...Tag) (T, error) {
+func (m *MapEntityManager[T]) Create(ctx context.Context, id string, name string, typ EntityType, tags ...Tag) (ent T, err error) {
+	if tracer := m.currentTracer(); !isNopTracer(tracer) {
+		var span Span
+		ctx, span = tracer.Start(ctx, SpanCreateEntity, SpanAttr{Key: AttrEntityId, Value: id}, SpanAttr{Key: AttrEntityType, Value: int(typ)})
+		defer func() { endSpan(span, err) }()
+	}
+	return m.create(ctx, id, name, typ, tags...)
+}
+
+func (m *MapEntityManager[T]) create(ctx context.Context, id string, name string, typ EntityType, tags ...Tag) (T, error) {
 	var zero T
 	if err := ctx.Err(); err != nil {
 		return zero, err
 	}
+
+	ent, err := m.NewEntity(id, name, typ, tags...)
+	if err != nil {
+		return zero, err
+	}
+
+	if err := m.Add(ctx, ent); err != nil {
+		if errors.Is(err, ErrEntityAlreadyExists) {
+			return zero, fmt.Errorf("create entity %s: %w", id, ErrEntityAlreadyExists)
+		}
+		return zero, err
+	}
+	return ent, nil
+}
+
+// NewEntity allocates an entity with the manager's factory without registering it.
+func (m *MapEntityManager[T]) NewEntity(id string, name string, typ EntityType, tags ...Tag) (T, error) {
+	var zero T
 	if id == "" {
 		return zero, ErrInvalidEntityId
 	}
@@ -72,36 +191,39 @@ func (m *MapEntityManager[T]) Create(ctx context.Context, id string, name string
 	if ent.Id() != id {
 		return zero, fmt.Errorf("create entity: id mismatch: want %s got %s", id, ent.Id())
 	}
-
-	if err := m.Add(ctx, ent); err != nil {
-		if errors.Is(err, ErrEntityAlreadyExists) {
-			return zero, fmt.Errorf("create entity %s: %w", id, ErrEntityAlreadyExists)
-		}
-		return zero, err
-	}
 	return ent, nil
 }
 
 // Add registers an existing entity with the manager.
-func (m *MapEntityManager[T]) Add(ctx context.Context, ent T) error {
+func (m *MapEntityManager[T]) Add(ctx context.Context, ent T) (err error) {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 	if isNil(ent) {
 		return fmt.Errorf("add entity: nil entity")
 	}
+	if tracer := m.currentTracer(); !isNopTracer(tracer) {
+		var span Span
+		_, span = tracer.Start(ctx, SpanAddEntity, SpanAttr{Key: AttrEntityId, Value: ent.Id()}, SpanAttr{Key: AttrEntityType, Value: int(ent.Type())})
+		defer func() { endSpan(span, err) }()
+	}
 	id := ent.Id()
 	if id == "" {
 		return fmt.Errorf("add entity: %w", ErrInvalidEntityId)
 	}
 
-	shard := m.shard(id)
+	idx := m.shardIndex(id)
+	shard := &m.shards[idx]
 	shard.mu.Lock()
-	defer shard.mu.Unlock()
 	if _, ok := shard.byId[id]; ok {
+		shard.mu.Unlock()
 		return fmt.Errorf("add entity %s: %w", id, ErrEntityAlreadyExists)
 	}
 	shard.byId[id] = ent
+	shard.gens[id] = m.nextGen.Add(1)
+	m.reportShardLocked(idx)
+	shard.mu.Unlock()
+	m.logEntity(ctx, "entity added", ent)
 	return nil
 }
 
@@ -146,15 +268,84 @@ func (m *MapEntityManager[T]) MustGet(id string) T {
 }
 
 // Remove deletes an entity by ID, returning true if the entity existed.
+// OnRemove hooks run after the entity has been unregistered.
 func (m *MapEntityManager[T]) Remove(id string) bool {
-	shard := m.shard(id)
+	idx := m.shardIndex(id)
+	shard := &m.shards[idx]
 	shard.mu.Lock()
-	_, ok := shard.byId[id]
+	ent, ok := shard.byId[id]
 	delete(shard.byId, id)
+	delete(shard.gens, id)
+	if ok {
+		m.reportShardLocked(idx)
+	}
 	shard.mu.Unlock()
+	if ok {
+		m.logEntity(context.Background(), "entity removed", ent)
+		m.runRemoveHooks(ent)
+	}
 	return ok
 }
 
+// OnRemove registers fn to run after an entity is removed.
+// Hooks run synchronously on the goroutine calling Remove, in registration order.
+// The returned func unregisters the hook.
+func (m *MapEntityManager[T]) OnRemove(fn func(ent T)) func() {
+	if fn == nil {
+		return func() {}
+	}
+	m.hooksMu.Lock()
+	m.nextHookId++
+	hookId := m.nextHookId
+	m.removeHooks = append(m.removeHooks, entityHook[T]{id: hookId, fn: fn})
+	m.hooksMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.hooksMu.Lock()
+			defer m.hooksMu.Unlock()
+			for i, hook := range m.removeHooks {
+				if hook.id == hookId {
+					m.removeHooks = append(m.removeHooks[:i:i], m.removeHooks[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+func (m *MapEntityManager[T]) runRemoveHooks(ent T) {
+	m.hooksMu.RLock()
+	hooks := m.removeHooks
+	m.hooksMu.RUnlock()
+	for _, hook := range hooks {
+		hook.fn(ent)
+	}
+}
+
+// Generation returns the incarnation number assigned when id was registered.
+// Every Add/Create assigns a new, strictly increasing generation, so an id
+// that is removed and registered again never reuses its old generation.
+func (m *MapEntityManager[T]) Generation(id string) (uint64, bool) {
+	_, gen, ok := m.GetWithGeneration(id)
+	return gen, ok
+}
+
+// GetWithGeneration retrieves an entity by ID with its generation, both read
+// under the same shard lock.
+func (m *MapEntityManager[T]) GetWithGeneration(id string) (T, uint64, bool) {
+	shard := m.shard(id)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	ent, ok := shard.byId[id]
+	if !ok {
+		var zero T
+		return zero, 0, false
+	}
+	return ent, shard.gens[id], true
+}
+
 // Len returns the total number of managed entities.
 func (m *MapEntityManager[T]) Len() int {
 	count := 0
@@ -169,12 +360,26 @@ func (m *MapEntityManager[T]) Len() int {
 
 // ForEach calls the provided function for each entity.
 func (m *MapEntityManager[T]) ForEach(ctx context.Context, fn func(ent T) error) error {
-	if err := ctx.Err(); err != nil {
+	tracer := m.currentTracer()
+	if isNopTracer(tracer) {
+		_, err := m.forEach(ctx, fn)
 		return err
 	}
+	ctx, span := tracer.Start(ctx, SpanForEach)
+	visited, err := m.forEach(ctx, fn)
+	span.SetAttributes(SpanAttr{Key: AttrEntitiesVisited, Value: visited})
+	endSpan(span, err)
+	return err
+}
+
+func (m *MapEntityManager[T]) forEach(ctx context.Context, fn func(ent T) error) (int, error) {
+	var visited int
+	if err := ctx.Err(); err != nil {
+		return visited, err
+	}
 	for i := range m.shards {
 		if err := ctx.Err(); err != nil {
-			return err
+			return visited, err
 		}
 		shard := &m.shards[i]
 		shard.mu.RLock()
@@ -185,14 +390,15 @@ func (m *MapEntityManager[T]) ForEach(ctx context.Context, fn func(ent T) error)
 		shard.mu.RUnlock()
 		for _, ent := range snapshot {
 			if err := ctx.Err(); err != nil {
-				return err
+				return visited, err
 			}
+			visited++
 			if err := fn(ent); err != nil {
-				return err
+				return visited, err
 			}
 		}
 	}
-	return nil
+	return visited, nil
 }
 
 // ForEachWithComponent iterates entities that have the given component type.
@@ -225,8 +431,11 @@ func (m *MapEntityManager[T]) ForEachWithAllComponents(ctx context.Context, type
 }
 
 func (m *MapEntityManager[T]) shard(id string) *entityShard[T] {
-	idx := int(hashEntityId(id) & m.shardMask)
-	return &m.shards[idx]
+	return &m.shards[m.shardIndex(id)]
+}
+
+func (m *MapEntityManager[T]) shardIndex(id string) int {
+	return int(hashEntityId(id) & m.shardMask)
 }
 
 // hashEntityId hashes a string id for shard routing (cheap, non-cryptographic).
@@ -0,0 +1,208 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// EntityRef is a typed reference to an entity registered in an EntityManager.
+//
+// It caches the generation observed when the reference was bound, so a
+// reference to an entity that was removed and re-created under the same id
+// resolves as stale instead of silently pointing at the new entity.
+// A zero generation means the reference is unbound (e.g. decoded from
+// storage) and resolves by id alone; use Bind to pin it again.
+type EntityRef[T Entity] struct {
+	id  string
+	gen uint64
+}
+
+// EntityReference is the type-erased view of an EntityRef.
+type EntityReference interface {
+	// Id is the referenced entity id.
+	Id() string
+	// Generation is the cached incarnation, or 0 when unbound.
+	Generation() uint64
+}
+
+// EntityRefHolder is implemented by components that store entity references.
+// FindDanglingRefs uses it to validate references across a manager.
+type EntityRefHolder interface {
+	EntityRefs() []EntityReference
+}
+
+// DanglingRef describes a component holding a reference that no longer resolves.
+type DanglingRef struct {
+	// EntityId is the entity owning the component.
+	EntityId string
+	// ComponentType is the component holding the reference.
+	ComponentType ComponentType
+	// Ref is the offending reference.
+	Ref EntityReference
+	// Stale is true when the id exists but belongs to a newer incarnation.
+	Stale bool
+}
+
+// NewEntityRef creates a reference to id bound to its current generation in m.
+func NewEntityRef[T Entity](m EntityManager[T], id string) (EntityRef[T], error) {
+	if id == "" {
+		return EntityRef[T]{}, ErrInvalidEntityId
+	}
+	_, gen, ok := m.GetWithGeneration(id)
+	if !ok {
+		return EntityRef[T]{}, fmt.Errorf("entity ref %s: %w", id, ErrEntityNotFound)
+	}
+	return EntityRef[T]{id: id, gen: gen}, nil
+}
+
+// UnboundEntityRef creates a reference that resolves by id alone.
+func UnboundEntityRef[T Entity](id string) EntityRef[T] {
+	return EntityRef[T]{id: id}
+}
+
+// Id returns the referenced entity id.
+func (r EntityRef[T]) Id() string {
+	return r.id
+}
+
+// Generation returns the cached generation, or 0 when the reference is unbound.
+func (r EntityRef[T]) Generation() uint64 {
+	return r.gen
+}
+
+// IsZero reports whether the reference is empty.
+func (r EntityRef[T]) IsZero() bool {
+	return r.id == ""
+}
+
+// Bound reports whether the reference carries a generation.
+func (r EntityRef[T]) Bound() bool {
+	return r.gen != 0
+}
+
+// Bind returns a copy of r pinned to the current generation of its id in m.
+func (r EntityRef[T]) Bind(m EntityManager[T]) (EntityRef[T], error) {
+	if r.gen != 0 {
+		if _, err := r.Resolve(m); err != nil {
+			return r, err
+		}
+		return r, nil
+	}
+	return NewEntityRef(m, r.id)
+}
+
+// Resolve returns the referenced entity.
+// It returns ErrEntityNotFound if the id is not registered and
+// ErrStaleEntityRef if the id now belongs to a different incarnation.
+func (r EntityRef[T]) Resolve(m EntityManager[T]) (T, error) {
+	var zero T
+	if r.id == "" {
+		return zero, ErrInvalidEntityId
+	}
+	ent, err := checkEntityRef(m, r)
+	if err != nil {
+		return zero, fmt.Errorf("entity ref %s: %w", r.id, err)
+	}
+	return ent, nil
+}
+
+// Get is like Resolve but reports failure as a bool.
+func (r EntityRef[T]) Get(m EntityManager[T]) (T, bool) {
+	ent, err := r.Resolve(m)
+	return ent, err == nil
+}
+
+// String returns the referenced id.
+func (r EntityRef[T]) String() string {
+	return r.id
+}
+
+// MarshalJSON encodes the reference as its id.
+// Generations are process-local and are not persisted.
+func (r EntityRef[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.id)
+}
+
+// UnmarshalJSON decodes an id into an unbound reference.
+func (r *EntityRef[T]) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err != nil {
+		return fmt.Errorf("unmarshal entity ref: %w", err)
+	}
+	r.id = id
+	r.gen = 0
+	return nil
+}
+
+// MarshalBinary encodes the reference as its id, like MarshalJSON.
+func (r EntityRef[T]) MarshalBinary() ([]byte, error) {
+	return []byte(r.id), nil
+}
+
+// UnmarshalBinary decodes an id into an unbound reference.
+func (r *EntityRef[T]) UnmarshalBinary(data []byte) error {
+	r.id = string(data)
+	r.gen = 0
+	return nil
+}
+
+// FindDanglingRefs scans every entity in m and reports references held by
+// EntityRefHolder components that are missing or stale.
+// Results are sorted by entity id and component type.
+func FindDanglingRefs[T Entity](ctx context.Context, m EntityManager[T]) ([]DanglingRef, error) {
+	var out []DanglingRef
+	err := m.ForEach(ctx, func(ent T) error {
+		for _, c := range ent.AllComponents() {
+			holder, ok := c.(EntityRefHolder)
+			if !ok {
+				continue
+			}
+			for _, ref := range holder.EntityRefs() {
+				if isNil(ref) || ref.Id() == "" {
+					continue
+				}
+				_, err := checkEntityRef(m, ref)
+				if err == nil {
+					continue
+				}
+				out = append(out, DanglingRef{
+					EntityId:      ent.Id(),
+					ComponentType: c.ComponentType(),
+					Ref:           ref,
+					Stale:         errors.Is(err, ErrStaleEntityRef),
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].EntityId != out[j].EntityId {
+			return out[i].EntityId < out[j].EntityId
+		}
+		return out[i].ComponentType < out[j].ComponentType
+	})
+	return out, nil
+}
+
+// checkEntityRef returns the entity ref points at. The entity and its
+// generation come from one lookup, so a concurrent re-create cannot slip
+// between the check and the result.
+func checkEntityRef[T Entity](m EntityManager[T], ref EntityReference) (T, error) {
+	var zero T
+	ent, gen, ok := m.GetWithGeneration(ref.Id())
+	if !ok {
+		return zero, ErrEntityNotFound
+	}
+	if ref.Generation() != 0 && ref.Generation() != gen {
+		return zero, ErrStaleEntityRef
+	}
+	return ent, nil
+}
+
+var _ EntityReference = EntityRef[Entity]{}
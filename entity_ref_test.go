@@ -0,0 +1,166 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const testTargetComponentType ComponentType = 10002
+
+type testTargetComponent struct {
+	ComponentCore
+	Target EntityRef[DataEntity] `json:"target"`
+}
+
+func (c *testTargetComponent) EntityRefs() []EntityReference {
+	return []EntityReference{c.Target}
+}
+
+func newTestDataEntityManager() *MapEntityManager[DataEntity] {
+	return NewEntityManager(func(id string, name string, typ EntityType, tags ...Tag) (DataEntity, error) {
+		return NewDataEntityCore(id, name, typ, tags...), nil
+	}, 4)
+}
+
+func TestEntityRef_ResolveDetectsRecreatedId(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	if _, err := m.Create(ctx, "leader", "leader", 1); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ref, err := NewEntityRef[DataEntity](m, "leader")
+	if err != nil {
+		t.Fatalf("new ref: %v", err)
+	}
+	if _, err := ref.Resolve(m); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	m.Remove("leader")
+	if _, err := ref.Resolve(m); !errors.Is(err, ErrEntityNotFound) {
+		t.Fatalf("expected ErrEntityNotFound, got %v", err)
+	}
+
+	if _, err := m.Create(ctx, "leader", "leader", 1); err != nil {
+		t.Fatalf("recreate: %v", err)
+	}
+	if _, err := ref.Resolve(m); !errors.Is(err, ErrStaleEntityRef) {
+		t.Fatalf("expected ErrStaleEntityRef, got %v", err)
+	}
+}
+
+func TestEntityRef_ResolveNeverReturnsNewIncarnation(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	orig, err := m.Create(ctx, "leader", "leader", 1)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ref, err := NewEntityRef[DataEntity](m, "leader")
+	if err != nil {
+		t.Fatalf("new ref: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 500; i++ {
+			m.Remove("leader")
+			if _, err := m.Create(ctx, "leader", "leader", 1); err != nil {
+				t.Errorf("recreate: %v", err)
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			ent, gen, ok := m.GetWithGeneration("leader")
+			if !ok || gen == ref.Generation() || ent == orig {
+				t.Fatalf("GetWithGeneration = %v, %d, %v", ent, gen, ok)
+			}
+			return
+		default:
+		}
+		if ent, err := ref.Resolve(m); err == nil && ent != orig {
+			t.Fatalf("resolve returned a re-created entity")
+		}
+	}
+}
+
+func TestEntityRef_JSONRoundTripIsUnbound(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	if _, err := m.Create(ctx, "target", "target", 1); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ref, err := NewEntityRef[DataEntity](m, "target")
+	if err != nil {
+		t.Fatalf("new ref: %v", err)
+	}
+
+	data, err := json.Marshal(ref)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(data) != `"target"` {
+		t.Fatalf("unexpected json: %s", data)
+	}
+	var decoded EntityRef[DataEntity]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.Bound() {
+		t.Fatalf("decoded ref should be unbound")
+	}
+	bound, err := decoded.Bind(m)
+	if err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+	if bound.Generation() != ref.Generation() {
+		t.Fatalf("generation = %d, want %d", bound.Generation(), ref.Generation())
+	}
+}
+
+func TestFindDanglingRefs(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	for _, id := range []string{"a", "b", "c", "holder"} {
+		if _, err := m.Create(ctx, id, id, 1); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+	}
+	refA, _ := NewEntityRef[DataEntity](m, "a")
+	refB, _ := NewEntityRef[DataEntity](m, "b")
+	refC, _ := NewEntityRef[DataEntity](m, "c")
+	holders := map[string]EntityRef[DataEntity]{"a": refB, "b": refA, "holder": refC}
+	for id, ref := range holders {
+		ent := m.MustGet(id)
+		c := &testTargetComponent{ComponentCore: NewComponentCore(testTargetComponentType), Target: ref}
+		if err := ent.Add(c); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	m.Remove("b")
+	m.Remove("c")
+	if _, err := m.Create(ctx, "c", "c", 1); err != nil {
+		t.Fatalf("recreate: %v", err)
+	}
+
+	dangling, err := FindDanglingRefs[DataEntity](ctx, m)
+	if err != nil {
+		t.Fatalf("find dangling: %v", err)
+	}
+	if len(dangling) != 2 {
+		t.Fatalf("expected 2 dangling refs, got %+v", dangling)
+	}
+	if dangling[0].EntityId != "a" || dangling[0].Ref.Id() != "b" || dangling[0].Stale {
+		t.Fatalf("unexpected missing ref: %+v", dangling[0])
+	}
+	if dangling[1].EntityId != "holder" || dangling[1].Ref.Id() != "c" || !dangling[1].Stale {
+		t.Fatalf("unexpected stale ref: %+v", dangling[1])
+	}
+}
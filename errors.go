@@ -9,12 +9,36 @@ var (
 	ErrComponentNotFound = errors.New("component not found")
 	// ErrNilComponent indicates a nil component was provided.
 	ErrNilComponent = errors.New("nil component")
+	// ErrDuplicateComponentType indicates a ComponentType or component name is already registered.
+	ErrDuplicateComponentType = errors.New("duplicate component type")
+	// ErrUnknownComponentType indicates a ComponentType or component name is not registered.
+	ErrUnknownComponentType = errors.New("unknown component type")
+	// ErrComponentTypeMismatch indicates stored data binds a ComponentType id and a name differently than the registry.
+	ErrComponentTypeMismatch = errors.New("component type mismatch")
 	// ErrEntityAlreadyExists indicates the entity manager already contains an entity for the given id.
 	ErrEntityAlreadyExists = errors.New("entity already exists")
 	// ErrEntityNotFound indicates the entity manager does not contain an entity for the given id.
 	ErrEntityNotFound = errors.New("entity not found")
+	// ErrStaleEntityRef indicates an EntityRef points at an id that was removed and registered again.
+	ErrStaleEntityRef = errors.New("stale entity reference")
 	// ErrInvalidEntityId indicates an operation received an invalid entity id (e.g. empty string).
 	ErrInvalidEntityId = errors.New("invalid entity id")
+	// ErrInvalidPayload indicates a stored payload or its header could not be parsed.
+	ErrInvalidPayload = errors.New("invalid payload")
+	// ErrMissingMigration indicates no migration is registered to upgrade a payload's schema version.
+	ErrMissingMigration = errors.New("missing schema migration")
+	// ErrUnsupportedSchema indicates a payload's schema version is newer than the registered one.
+	ErrUnsupportedSchema = errors.New("unsupported schema version")
+	// ErrResourceNotFound indicates a world does not hold a resource of the requested type.
+	ErrResourceNotFound = errors.New("resource not found")
+	// ErrUnknownTimerHandler indicates a timer names a handler that is not registered.
+	ErrUnknownTimerHandler = errors.New("unknown timer handler")
+	// ErrMailboxFull indicates an entity mailbox already holds as many commands as it may queue.
+	ErrMailboxFull = errors.New("mailbox full")
+	// ErrMailboxReentrant indicates an Ask that would wait on the worker running its caller.
+	ErrMailboxReentrant = errors.New("mailbox ask would wait on its own worker")
+	// ErrMailboxClosed indicates a command sent after the mailboxes were closed.
+	ErrMailboxClosed = errors.New("mailboxes closed")
 	// ErrWorldAlreadyRunning indicates an operation requires the world to be stopped.
 	ErrWorldAlreadyRunning = errors.New("world already running")
 )
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+var benchCodecs = []ginka_ecs_go.Codec{
+	ginka_ecs_go.JSONCodec,
+	ginka_ecs_go.GobCodec,
+	ginka_ecs_go.BinaryCodec,
+}
+
+func benchComponents() []ginka_ecs_go.Component {
+	wallet := NewWalletComponent(123456)
+	wallet.SetVersion(42)
+	profile := NewProfileComponent("AkiHero")
+	profile.SetVersion(3)
+	return []ginka_ecs_go.Component{profile, wallet}
+}
+
+func BenchmarkCodecMarshal(b *testing.B) {
+	for _, codec := range benchCodecs {
+		for _, c := range benchComponents() {
+			name := codec.Name() + "/" + c.(ginka_ecs_go.DataComponent).StorageKey()
+			b.Run(name, func(b *testing.B) {
+				var size int
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					data, err := ginka_ecs_go.EncodePayload(codec, c)
+					if err != nil {
+						b.Fatalf("encode: %v", err)
+					}
+					size = len(data)
+				}
+				b.ReportMetric(float64(size), "bytes/payload")
+			})
+		}
+	}
+}
+
+func BenchmarkCodecUnmarshal(b *testing.B) {
+	for _, codec := range benchCodecs {
+		for _, c := range benchComponents() {
+			name := codec.Name() + "/" + c.(ginka_ecs_go.DataComponent).StorageKey()
+			data, err := ginka_ecs_go.EncodePayload(codec, c)
+			if err != nil {
+				b.Fatalf("encode: %v", err)
+			}
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					out, err := componentRegistry.New(c.ComponentType())
+					if err != nil {
+						b.Fatalf("new: %v", err)
+					}
+					if _, err := ginka_ecs_go.DecodePayload(data, out); err != nil {
+						b.Fatalf("decode: %v", err)
+					}
+				}
+				b.ReportMetric(float64(len(data)), "bytes/payload")
+			})
+		}
+	}
+}
+
+func TestCodecs_DemoComponentsRoundTrip(t *testing.T) {
+	for _, codec := range benchCodecs {
+		wallet := NewWalletComponent(99)
+		wallet.SetVersion(5)
+		data, err := ginka_ecs_go.EncodePayload(codec, wallet)
+		if err != nil {
+			t.Fatalf("%s encode: %v", codec.Name(), err)
+		}
+		out := NewWalletComponent(0)
+		if _, err := ginka_ecs_go.DecodePayload(data, out); err != nil {
+			t.Fatalf("%s decode: %v", codec.Name(), err)
+		}
+		if out.Gold != 99 || out.Version() != 5 {
+			t.Fatalf("%s round trip = %+v", codec.Name(), out)
+		}
+	}
+}
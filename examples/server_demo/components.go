@@ -1,75 +1,60 @@
 package main
 
-import (
-	"encoding/json"
-	"fmt"
+//go:generate go run ../../cmd/ginka-gen -registry componentRegistry
 
+import (
 	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
 )
 
-const (
-	ComponentTypeProfile ginka_ecs_go.ComponentType = iota + 1
-	ComponentTypeWallet
-)
-
-type ProfileComponent struct {
-	ginka_ecs_go.DataComponentCore
-	Name string `json:"name"`
-}
+// componentRegistry resolves component names used by prefabs and storage.
+var componentRegistry = newComponentRegistry()
 
-func NewProfileComponent(name string) *ProfileComponent {
-	return &ProfileComponent{
-		DataComponentCore: ginka_ecs_go.NewDataComponentCore(ComponentTypeProfile),
-		Name:              name,
+func newComponentRegistry() *ginka_ecs_go.ComponentRegistry {
+	reg := ginka_ecs_go.NewComponentRegistry()
+	if err := registerComponents(reg); err != nil {
+		panic(err)
 	}
+	// Pending timers are persisted with the player.
+	if err := ginka_ecs_go.RegisterTimers(reg); err != nil {
+		panic(err)
+	}
+	migrations := []ginka_ecs_go.Migration{
+		// v2 added the profile title.
+		{
+			Type: ComponentTypeProfile,
+			From: 1,
+			Map: func(doc map[string]any) error {
+				if _, ok := doc["title"]; !ok {
+					doc["title"] = defaultProfileTitle
+				}
+				return nil
+			},
+		},
+	}
+	for _, m := range migrations {
+		if err := reg.RegisterMigration(m); err != nil {
+			panic(err)
+		}
+	}
+	return reg
 }
 
-func (c *ProfileComponent) StorageKey() string {
-	return "profile"
-}
+const defaultProfileTitle = "Novice"
 
-func (c *ProfileComponent) Marshal() ([]byte, error) {
-	data, err := json.Marshal(c)
-	if err != nil {
-		return nil, fmt.Errorf("marshal profile: %w", err)
-	}
-	return data, nil
+//ginka:component name=profile id=1 schema=2 new=Name
+type ProfileComponent struct {
+	ginka_ecs_go.DataComponentCore
+	Name  string `json:"name"`
+	Title string `json:"title"`
 }
 
-func (c *ProfileComponent) Unmarshal(data []byte) error {
-	if err := json.Unmarshal(data, c); err != nil {
-		return fmt.Errorf("unmarshal profile: %w", err)
-	}
-	return nil
+func (c *ProfileComponent) defaults() {
+	c.Title = defaultProfileTitle
 }
 
+//ginka:component name=wallet id=2 new=Gold
 type WalletComponent struct {
 	ginka_ecs_go.DataComponentCore
+	ginka_ecs_go.FieldTracker
 	Gold int64 `json:"gold"`
 }
-
-func NewWalletComponent(gold int64) *WalletComponent {
-	return &WalletComponent{
-		DataComponentCore: ginka_ecs_go.NewDataComponentCore(ComponentTypeWallet),
-		Gold:              gold,
-	}
-}
-
-func (c *WalletComponent) StorageKey() string {
-	return "wallet"
-}
-
-func (c *WalletComponent) Marshal() ([]byte, error) {
-	data, err := json.Marshal(c)
-	if err != nil {
-		return nil, fmt.Errorf("marshal wallet: %w", err)
-	}
-	return data, nil
-}
-
-func (c *WalletComponent) Unmarshal(data []byte) error {
-	if err := json.Unmarshal(data, c); err != nil {
-		return fmt.Errorf("unmarshal wallet: %w", err)
-	}
-	return nil
-}
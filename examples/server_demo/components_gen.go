@@ -0,0 +1,128 @@
+// Code generated by ginka-gen. DO NOT EDIT.
+
+package main
+
+import (
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+const (
+	ComponentTypeProfile ginka_ecs_go.ComponentType = 1
+	ComponentTypeWallet  ginka_ecs_go.ComponentType = 2
+)
+
+// registerComponents registers the generated component specs in reg.
+func registerComponents(reg *ginka_ecs_go.ComponentRegistry) error {
+	specs := []ginka_ecs_go.ComponentSpec{
+		{
+			Name:          "profile",
+			Type:          ComponentTypeProfile,
+			New:           func() ginka_ecs_go.Component { return newProfileComponent() },
+			SchemaVersion: 2,
+		},
+		{
+			Name: "wallet",
+			Type: ComponentTypeWallet,
+			New:  func() ginka_ecs_go.Component { return newWalletComponent() },
+		},
+	}
+	for _, spec := range specs {
+		if err := reg.Register(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newProfileComponent() *ProfileComponent {
+	c := &ProfileComponent{
+		DataComponentCore: ginka_ecs_go.NewDataComponentCore(ComponentTypeProfile),
+	}
+	c.defaults()
+	return c
+}
+
+// NewProfileComponent creates a profile component.
+func NewProfileComponent(name string) *ProfileComponent {
+	c := newProfileComponent()
+	c.Name = name
+	return c
+}
+
+func (c *ProfileComponent) StorageKey() string {
+	return "profile"
+}
+
+func (c *ProfileComponent) Marshal() ([]byte, error) {
+	return componentRegistry.EncodeComponent(c)
+}
+
+func (c *ProfileComponent) Unmarshal(data []byte) error {
+	return componentRegistry.DecodeInto(c, data)
+}
+
+func (c *ProfileComponent) Clone() ginka_ecs_go.Component {
+	out := *c
+	out.SetTags(c.Tags()...)
+	return &out
+}
+
+// GetProfile returns the profile component of ent.
+func GetProfile(ent ginka_ecs_go.Entity) (*ProfileComponent, bool) {
+	return ginka_ecs_go.Get[*ProfileComponent](ent, ComponentTypeProfile)
+}
+
+// GetProfileForUpdate returns the profile component of ent and marks it dirty.
+func GetProfileForUpdate(ent ginka_ecs_go.DataEntity) (*ProfileComponent, bool, error) {
+	return ginka_ecs_go.GetForUpdateE[*ProfileComponent](ent, ComponentTypeProfile)
+}
+
+func newWalletComponent() *WalletComponent {
+	c := &WalletComponent{
+		DataComponentCore: ginka_ecs_go.NewDataComponentCore(ComponentTypeWallet),
+	}
+	return c
+}
+
+// NewWalletComponent creates a wallet component.
+func NewWalletComponent(gold int64) *WalletComponent {
+	c := newWalletComponent()
+	c.Gold = gold
+	return c
+}
+
+func (c *WalletComponent) StorageKey() string {
+	return "wallet"
+}
+
+func (c *WalletComponent) Marshal() ([]byte, error) {
+	return componentRegistry.EncodeComponent(c)
+}
+
+func (c *WalletComponent) Unmarshal(data []byte) error {
+	return componentRegistry.DecodeInto(c, data)
+}
+
+func (c *WalletComponent) Clone() ginka_ecs_go.Component {
+	out := *c
+	out.SetTags(c.Tags()...)
+	out.ClearChangedFields()
+	return &out
+}
+
+// GetWallet returns the wallet component of ent.
+func GetWallet(ent ginka_ecs_go.Entity) (*WalletComponent, bool) {
+	return ginka_ecs_go.Get[*WalletComponent](ent, ComponentTypeWallet)
+}
+
+// GetWalletForUpdate returns the wallet component of ent and marks it dirty.
+func GetWalletForUpdate(ent ginka_ecs_go.DataEntity) (*WalletComponent, bool, error) {
+	return ginka_ecs_go.GetForUpdateE[*WalletComponent](ent, ComponentTypeWallet)
+}
+
+var (
+	_ ginka_ecs_go.DataComponent = (*ProfileComponent)(nil)
+	_ ginka_ecs_go.Cloner        = (*ProfileComponent)(nil)
+	_ ginka_ecs_go.DataComponent = (*WalletComponent)(nil)
+	_ ginka_ecs_go.Cloner        = (*WalletComponent)(nil)
+)
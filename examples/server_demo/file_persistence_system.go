@@ -2,27 +2,45 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
 )
 
+// manifestFile holds the component name/type bindings of the stored data.
+const manifestFile = "components.json"
+
+// resourcesDir holds persistent world resources, one file per storage key.
+// LoadComponents skips it, so no entity may use it as id.
+const resourcesDir = "_resources"
+
+// FilePersistenceSystem flushes dirty components to one file per component
+// under baseDir/<entity id>/<storage key>.<codec>. It applies partial updates
+// (JSON merge patches) to the files on disk. A full write removes the file
+// of a previous codec, so each component has one file. Persistent world
+// resources go to baseDir/_resources/<storage key>.json.
 type FilePersistenceSystem struct {
-	baseDir string
-}
+	baseDir   string
+	persister *ginka_ecs_go.Persister
 
-type persistedComponent struct {
-	typ     ginka_ecs_go.ComponentType
-	path    string
-	payload []byte
-	version uint64
+	mu          sync.Mutex
+	createdDirs map[string]struct{}
 }
 
 func NewFilePersistenceSystem(baseDir string) *FilePersistenceSystem {
-	return &FilePersistenceSystem{baseDir: baseDir}
+	s := &FilePersistenceSystem{
+		baseDir:     baseDir,
+		createdDirs: make(map[string]struct{}),
+	}
+	s.persister = ginka_ecs_go.NewPersister(s)
+	s.persister.SetRegistry(componentRegistry)
+	s.persister.SetDeltaPayloads(true)
+	return s
 }
 
 func (s *FilePersistenceSystem) Name() string {
@@ -33,104 +51,219 @@ func (s *FilePersistenceSystem) Flush(ctx context.Context, w *GameWorld) error {
 	if s.baseDir == "" {
 		return fmt.Errorf("file persistence: baseDir is empty")
 	}
-	if err := ctx.Err(); err != nil {
+	if _, err := ginka_ecs_go.FlushAll(ctx, w.Entities, s.persister); err != nil {
 		return err
 	}
-	createdDirs := make(map[string]struct{})
-	return w.Entities.ForEach(ctx, func(ent ginka_ecs_go.DataEntity) error {
-		if err := ctx.Err(); err != nil {
-			return err
-		}
-		persisted := make([]persistedComponent, 0)
-		missing := make([]ginka_ecs_go.ComponentType, 0)
-		if err := ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
-			dirtyTypes := tx.DirtyTypes()
-			if len(dirtyTypes) == 0 {
-				return nil
-			}
-			persisted = make([]persistedComponent, 0, len(dirtyTypes))
-			missing = make([]ginka_ecs_go.ComponentType, 0, len(dirtyTypes))
-			for _, t := range dirtyTypes {
-				if err := ctx.Err(); err != nil {
-					return err
-				}
-				component, ok := tx.Get(t)
-				if !ok {
-					missing = append(missing, t)
-					continue
-				}
-				dataComponent, ok := component.(ginka_ecs_go.DataComponent)
-				if !ok {
-					return fmt.Errorf("file persistence: component %d is not a DataComponent", t)
-				}
-				marshaler, ok := component.(interface{ Marshal() ([]byte, error) })
-				if !ok {
-					return fmt.Errorf("file persistence: component %d does not implement Marshal", t)
-				}
-				payload, err := marshaler.Marshal()
-				if err != nil {
-					return err
-				}
-				key := sanitizeKey(dataComponent.StorageKey())
-				persisted = append(persisted, persistedComponent{
-					typ:     t,
-					path:    filepath.Join(s.baseDir, tx.Id(), key+".json"),
-					payload: payload,
-					version: dataComponent.Version(),
-				})
-			}
+	_, err := ginka_ecs_go.FlushResources(ctx, w, s)
+	return err
+}
+
+// LoadResources restores the persisted world resources of w. Resource
+// versions are not stored, so restored resources start at version 0.
+func (s *FilePersistenceSystem) LoadResources(w *GameWorld) error {
+	path := s.resourcePath(Leaderboard{}.StorageKey())
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
 			return nil
-		}); err != nil {
-			return err
 		}
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	rec := ginka_ecs_go.ResourceRecord{StorageKey: Leaderboard{}.StorageKey(), Payload: data}
+	return ginka_ecs_go.RestoreResource[Leaderboard](w, rec)
+}
 
-		for _, item := range persisted {
-			if err := ctx.Err(); err != nil {
-				return err
-			}
-			if err := writeFileAtomic(item.path, item.payload, 0o644, createdDirs); err != nil {
-				return err
-			}
-		}
+// SaveResource implements ginka_ecs_go.ResourceStore.
+func (s *FilePersistenceSystem) SaveResource(ctx context.Context, rec ginka_ecs_go.ResourceRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.writeFile(s.resourcePath(rec.StorageKey), rec.Payload)
+}
 
-		if len(persisted) == 0 && len(missing) == 0 {
-			return nil
+// SaveComponent implements ginka_ecs_go.ComponentStore.
+func (s *FilePersistenceSystem) SaveComponent(ctx context.Context, rec ginka_ecs_go.ComponentRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path := s.componentPath(rec)
+	if err := s.writeFile(path, rec.Payload); err != nil {
+		return err
+	}
+	return removeStaleCodecFiles(path)
+}
+
+// PatchComponent implements ginka_ecs_go.PatchStore.
+func (s *FilePersistenceSystem) PatchComponent(ctx context.Context, rec ginka_ecs_go.ComponentRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path := s.componentPath(rec)
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	patched, err := ginka_ecs_go.ApplyPayloadPatch(current, rec.Payload)
+	if err != nil {
+		return fmt.Errorf("patch %s: %w", path, err)
+	}
+	return s.writeFile(path, patched)
+}
+
+// SyncManifest checks the component manifest stored in baseDir against
+// componentRegistry and records the current bindings. It fails if a stored
+// ComponentType id changed meaning.
+func (s *FilePersistenceSystem) SyncManifest() error {
+	path := filepath.Join(s.baseDir, manifestFile)
+	stored := ginka_ecs_go.ComponentManifest{}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
 		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := componentRegistry.CheckManifest(stored); err != nil {
+		return fmt.Errorf("file persistence: %w", err)
+	}
+	data, err = json.MarshalIndent(stored.Merge(componentRegistry.Manifest()), "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.writeFile(path, data)
+}
 
-		if err := ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
-			toClear := make(map[ginka_ecs_go.ComponentType]struct{}, len(persisted)+len(missing))
-			for _, t := range missing {
-				if _, ok := tx.Get(t); !ok {
-					toClear[t] = struct{}{}
-				}
-			}
-			for _, item := range persisted {
-				component, ok := tx.Get(item.typ)
-				if !ok {
-					continue
-				}
-				dataComponent, ok := component.(ginka_ecs_go.DataComponent)
-				if !ok {
-					continue
-				}
-				if dataComponent.Version() == item.version {
-					toClear[item.typ] = struct{}{}
-				}
-			}
-			if len(toClear) == 0 {
-				return nil
-			}
-			types := make([]ginka_ecs_go.ComponentType, 0, len(toClear))
-			for t := range toClear {
-				types = append(types, t)
-			}
-			tx.ClearDirty(types...)
+// LoadComponents implements ginka_ecs_go.ComponentSource. Storage keys are
+// resolved to ComponentTypes through componentRegistry; see lookupStorageKey.
+func (s *FilePersistenceSystem) LoadComponents(ctx context.Context, fn func(rec ginka_ecs_go.ComponentRecord) error) error {
+	entityDirs, err := os.ReadDir(s.baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
 			return nil
-		}); err != nil {
+		}
+		return fmt.Errorf("read %s: %w", s.baseDir, err)
+	}
+	for _, dir := range entityDirs {
+		if !dir.IsDir() || dir.Name() == resourcesDir {
+			continue
+		}
+		if err := s.loadEntityDir(ctx, dir.Name(), fn); err != nil {
 			return err
 		}
+	}
+	return nil
+}
+
+// LoadEntity decodes the stored components of one entity, migrating payloads
+// written with older schema versions.
+func (s *FilePersistenceSystem) LoadEntity(ctx context.Context, entityId string) ([]ginka_ecs_go.Component, error) {
+	var out []ginka_ecs_go.Component
+	err := s.loadEntityDir(ctx, entityId, func(rec ginka_ecs_go.ComponentRecord) error {
+		c, err := componentRegistry.DecodeComponent(rec.Type, rec.Payload)
+		if err != nil {
+			return fmt.Errorf("load %s/%s: %w", rec.EntityId, rec.StorageKey, err)
+		}
+		out = append(out, c)
 		return nil
 	})
+	return out, err
+}
+
+// Migrate rewrites every stored component to its current schema version.
+func (s *FilePersistenceSystem) Migrate(ctx context.Context) (ginka_ecs_go.MigrateStats, error) {
+	return ginka_ecs_go.MigrateStore(ctx, s, s, componentRegistry)
+}
+
+func (s *FilePersistenceSystem) loadEntityDir(ctx context.Context, entityId string, fn func(rec ginka_ecs_go.ComponentRecord) error) error {
+	dir := filepath.Join(s.baseDir, entityId)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", dir, err)
+	}
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ext := filepath.Ext(file.Name())
+		if file.IsDir() || ext == ".tmp" {
+			continue
+		}
+		key := strings.TrimSuffix(file.Name(), ext)
+		spec, ok := lookupStorageKey(key)
+		if !ok {
+			return fmt.Errorf("load %s/%s: %w", entityId, file.Name(), ginka_ecs_go.ErrUnknownComponentType)
+		}
+		path := filepath.Join(dir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		rec := ginka_ecs_go.ComponentRecord{
+			EntityId:   entityId,
+			Type:       spec.Type,
+			StorageKey: key,
+			Codec:      strings.TrimPrefix(ext, "."),
+			Payload:    data,
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupStorageKey finds the registered component stored under key. Demo
+// components use their name as storage key; library components such as
+// the timers list may not, so their specs are searched by StorageKey.
+func lookupStorageKey(key string) (ginka_ecs_go.ComponentSpec, bool) {
+	if spec, ok := componentRegistry.LookupName(key); ok {
+		return spec, true
+	}
+	for _, spec := range componentRegistry.Specs() {
+		dc, ok := spec.New().(ginka_ecs_go.DataComponent)
+		if ok && dc.StorageKey() == key {
+			return spec, true
+		}
+	}
+	return ginka_ecs_go.ComponentSpec{}, false
+}
+
+func (s *FilePersistenceSystem) componentPath(rec ginka_ecs_go.ComponentRecord) string {
+	return filepath.Join(s.baseDir, rec.EntityId, sanitizeKey(rec.StorageKey)+"."+rec.Codec)
+}
+
+// removeStaleCodecFiles deletes the files a component was stored in under
+// other codecs, so a codec change does not leave two copies to load.
+func removeStaleCodecFiles(path string) error {
+	dir, name := filepath.Split(path)
+	key := strings.TrimSuffix(name, filepath.Ext(name))
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", dir, err)
+	}
+	for _, file := range files {
+		ext := filepath.Ext(file.Name())
+		if file.IsDir() || ext == ".tmp" || file.Name() == name || strings.TrimSuffix(file.Name(), ext) != key {
+			continue
+		}
+		stale := filepath.Join(dir, file.Name())
+		if err := os.Remove(stale); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove %s: %w", stale, err)
+		}
+	}
+	return nil
+}
+
+func (s *FilePersistenceSystem) writeFile(path string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeFileAtomic(path, data, 0o644, s.createdDirs)
+}
+
+func (s *FilePersistenceSystem) resourcePath(key string) string {
+	return filepath.Join(s.baseDir, resourcesDir, sanitizeKey(key)+".json")
 }
 
 func sanitizeKey(key string) string {
@@ -1,18 +1,43 @@
 package main
 
-import ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+import (
+	"context"
+	"errors"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
 
 type GameWorld struct {
 	*ginka_ecs_go.CoreWorld
 	Entities ginka_ecs_go.EntityManager[ginka_ecs_go.DataEntity]
+
+	leaderboard LeaderboardSystem
 }
 
+// NewGameWorld creates a world whose timers and leaderboard are attached
+// to its manager.
+// The world does not drive its timers itself; Tick does, once per tick, so
+// recorded sessions can log every tick.
 func NewGameWorld(name string) *GameWorld {
 	entities := ginka_ecs_go.NewEntityManager(func(id string, entityName string, typ ginka_ecs_go.EntityType, tags ...ginka_ecs_go.Tag) (ginka_ecs_go.DataEntity, error) {
 		return ginka_ecs_go.NewDataEntityCore(id, entityName, typ, tags...), nil
 	}, 0)
-	return &GameWorld{
+	w := &GameWorld{
 		CoreWorld: ginka_ecs_go.NewCoreWorld(name),
 		Entities:  entities,
 	}
+	ginka_ecs_go.SetResource(w, Leaderboard{})
+	// The world lives as long as its manager, so the timers and the
+	// leaderboard stay attached.
+	_ = ginka_ecs_go.AttachTimers(entities, w.Timers())
+	_ = AttachLeaderboard(w)
+	return w
+}
+
+// Tick runs one world tick: it steps the timers, firing those due, and
+// refreshes the leaderboard.
+func (w *GameWorld) Tick(ctx context.Context) error {
+	_, timersErr := w.Timers().Step(ctx)
+	_, err := w.leaderboard.Update(ctx, w)
+	return errors.Join(timersErr, err)
 }
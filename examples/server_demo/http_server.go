@@ -1,28 +1,200 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+	"github.com/Shigure42/ginka-ecs-go/audit"
+	"github.com/Shigure42/ginka-ecs-go/debug"
 )
 
+// ActorHeader names the request header identifying who makes a request
+// when the server has no Authenticator. Anyone can set it, so it is only
+// trustworthy behind a proxy that authenticates callers and sets it itself.
+// Requests without it are attributed to their remote address.
+const ActorHeader = "X-Actor"
+
+// Authenticator returns the identity of the caller of r, or an error if r
+// is not authenticated.
+type Authenticator func(r *http.Request) (string, error)
+
 type Server struct {
 	world   *GameWorld
 	auth    *AuthSystem
 	wallet  *WalletSystem
 	profile *ProfileSystem
+	// inspector serves the debug view of the world; see AdminRoutes.
+	inspector *debug.Inspector
+	// session, if set, records every request for replay.
+	session *ReplaySession
+	// auditor, if set, audits the changes requests make.
+	auditor *audit.Auditor
+	// authenticate, if set, identifies the actor of audited requests.
+	authenticate Authenticator
+	// mailboxes, if set, run player commands on per-player mailboxes.
+	mailboxes *ginka_ecs_go.Mailboxes[ginka_ecs_go.DataEntity]
+}
+
+func NewServer(world *GameWorld, auth *AuthSystem, wallet *WalletSystem, profile *ProfileSystem) (*Server, error) {
+	inspector := debug.NewInspector(componentRegistry)
+	if err := debug.Register(inspector, world, world.Entities); err != nil {
+		return nil, fmt.Errorf("new server: %w", err)
+	}
+	return &Server{world: world, auth: auth, wallet: wallet, profile: profile, inspector: inspector}, nil
+}
+
+// SetSession makes the server apply requests through session, which must
+// have been created with NewReplayHandlers for the server's world. While
+// recording, debug inspector edits are refused, since they would change
+// the world outside the log. Recorded inputs are applied one at a time.
+func (s *Server) SetSession(session *ReplaySession) {
+	s.session = session
+}
+
+// apply runs direct, or records req under kind and applies it through the
+// replay session.
+func (s *Server) apply(ctx context.Context, kind string, req any, direct func() error) error {
+	if s.session == nil {
+		return direct()
+	}
+	return s.session.Apply(ctx, kind, req)
+}
+
+// Tick runs one world tick. While recording, the tick is an input of the
+// log and is followed by a checkpoint.
+func (s *Server) Tick(ctx context.Context) error {
+	if s.session == nil {
+		return s.world.Tick(ctx)
+	}
+	err := s.session.Apply(ctx, InputTick, TickRequest{})
+	if advanceErr := s.session.Advance(ctx); advanceErr != nil && err == nil {
+		err = advanceErr
+	}
+	return err
+}
+
+// RunTicks calls Tick every interval until ctx is done. Failed ticks are
+// logged to the world's logger.
+func (s *Server) RunTicks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Tick(ctx); err != nil {
+				s.world.Logger().ErrorContext(ctx, "tick failed", slog.Any("error", err))
+			}
+		}
+	}
 }
 
-func NewServer(world *GameWorld, auth *AuthSystem, wallet *WalletSystem, profile *ProfileSystem) *Server {
-	return &Server{world: world, auth: auth, wallet: wallet, profile: profile}
+// SetMailboxes makes the server run add-gold and rename requests on the
+// player's mailbox instead of the request goroutine, so commands for one
+// player run one at a time. mailboxes must be over the server's world
+// entities. A full mailbox answers 503 with Retry-After.
+func (s *Server) SetMailboxes(mailboxes *ginka_ecs_go.Mailboxes[ginka_ecs_go.DataEntity]) {
+	s.mailboxes = mailboxes
 }
 
+// dispatch runs fn directly, or on playerId's mailbox with the request
+// context when the server has mailboxes.
+func (s *Server) dispatch(ctx context.Context, playerId string, fn func(ctx context.Context) error) error {
+	if s.mailboxes == nil {
+		return fn(ctx)
+	}
+	_, err := ginka_ecs_go.Ask(ctx, s.mailboxes, playerId, func(ctx context.Context, _ ginka_ecs_go.DataEntity) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// writeCommandError reports a failed player command, asking the client to
+// retry when the player's mailbox is full.
+func writeCommandError(w http.ResponseWriter, op string, err error) {
+	if errors.Is(err, ginka_ecs_go.ErrMailboxFull) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, fmt.Sprintf("%s: %v", op, err), http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, fmt.Sprintf("%s: %v", op, err), http.StatusBadRequest)
+}
+
+// SetAuditor makes the server audit the changes of every request, naming
+// the actor, the request path as the reason and the remote address as the
+// source. The actor comes from the Authenticator, or from ActorHeader when
+// there is none.
+func (s *Server) SetAuditor(auditor *audit.Auditor) {
+	s.auditor = auditor
+}
+
+// SetAuthenticator makes the server answer 401 to requests a rejects and
+// take the actor of audited requests from a.
+func (s *Server) SetAuthenticator(a Authenticator) {
+	s.authenticate = a
+}
+
+// Routes returns the public game API. It does not include the debug
+// inspector; see AdminRoutes.
 func (s *Server) Routes() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/login", s.handleLogin)
 	mux.HandleFunc("/add-gold", s.handleAddGold)
 	mux.HandleFunc("/rename", s.handleRename)
-	return mux
+	return s.withAudit(mux)
+}
+
+// AdminRoutes returns the debug inspector under /debug/, authenticated and
+// audited like Routes. It exposes every entity, so serve it on a separate
+// listener bound to a private address, never on the public one.
+func (s *Server) AdminRoutes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/", http.StripPrefix("/debug", s.refuseEditsWhileRecording(s.inspector.Routes())))
+	return s.withAudit(mux)
+}
+
+// refuseEditsWhileRecording answers 409 to requests that could change the
+// world while the server records a replay session.
+func (s *Server) refuseEditsWhileRecording(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.session != nil && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "debug edits are disabled while recording a replay session", http.StatusConflict)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) withAudit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var id string
+		if s.authenticate != nil {
+			var err error
+			if id, err = s.authenticate(r); err != nil {
+				http.Error(w, fmt.Sprintf("authenticate: %v", err), http.StatusUnauthorized)
+				return
+			}
+		} else {
+			id = r.Header.Get(ActorHeader)
+		}
+		if s.auditor == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		actor := ginka_ecs_go.Actor{Id: id, Reason: r.Method + " " + r.URL.Path, Source: r.RemoteAddr}
+		if actor.Id == "" {
+			actor.Id = r.RemoteAddr
+		}
+		ctx := ginka_ecs_go.WithActor(s.auditor.Context(r.Context()), actor)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
 }
 
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
@@ -42,7 +214,8 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "missing fields", http.StatusBadRequest)
 		return
 	}
-	if err := s.auth.Login(r.Context(), s.world, LoginRequest{PlayerId: req.PlayerId, Name: req.Name}); err != nil {
+	login := LoginRequest{PlayerId: req.PlayerId, Name: req.Name}
+	if err := s.apply(r.Context(), InputLogin, login, func() error { return s.auth.Login(r.Context(), s.world, login) }); err != nil {
 		http.Error(w, fmt.Sprintf("login: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -66,8 +239,12 @@ func (s *Server) handleAddGold(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "missing fields", http.StatusBadRequest)
 		return
 	}
-	if err := s.wallet.AddGold(r.Context(), s.world, AddGoldRequest{PlayerId: req.PlayerId, Amount: req.Amount}); err != nil {
-		http.Error(w, fmt.Sprintf("add gold: %v", err), http.StatusBadRequest)
+	addGold := AddGoldRequest{PlayerId: req.PlayerId, Amount: req.Amount}
+	err := s.dispatch(r.Context(), req.PlayerId, func(ctx context.Context) error {
+		return s.apply(ctx, InputAddGold, addGold, func() error { return s.wallet.AddGold(ctx, s.world, addGold) })
+	})
+	if err != nil {
+		writeCommandError(w, "add gold", err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -90,8 +267,12 @@ func (s *Server) handleRename(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "missing fields", http.StatusBadRequest)
 		return
 	}
-	if err := s.profile.Rename(r.Context(), s.world, RenameRequest{PlayerId: req.PlayerId, Name: req.Name}); err != nil {
-		http.Error(w, fmt.Sprintf("rename: %v", err), http.StatusBadRequest)
+	rename := RenameRequest{PlayerId: req.PlayerId, Name: req.Name}
+	err := s.dispatch(r.Context(), req.PlayerId, func(ctx context.Context) error {
+		return s.apply(ctx, InputRename, rename, func() error { return s.profile.Rename(ctx, s.world, rename) })
+	})
+	if err != nil {
+		writeCommandError(w, "rename", err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+	"github.com/Shigure42/ginka-ecs-go/audit"
+	"github.com/Shigure42/ginka-ecs-go/debug"
+	"github.com/Shigure42/ginka-ecs-go/ecstest"
 )
 
 func TestHTTPServerFlow(t *testing.T) {
@@ -20,19 +26,16 @@ func TestHTTPServerFlow(t *testing.T) {
 	profileSys := &ProfileSystem{}
 	walletSys := &WalletSystem{}
 	persistenceSys := NewFilePersistenceSystem(baseDir)
-	runDone := startWorld(t, world)
-	defer func() {
-		if err := world.Stop(); err != nil {
-			t.Fatalf("stop world: %v", err)
-		}
-		if err := <-runDone; err != nil {
-			t.Fatalf("run world: %v", err)
-		}
-	}()
+	ecstest.StartWorld(t, world)
 
-	server := NewServer(world, authSys, walletSys, profileSys)
+	server, err := NewServer(world, authSys, walletSys, profileSys)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
 	httpServer := httptest.NewServer(server.Routes())
 	defer httpServer.Close()
+	adminServer := httptest.NewServer(server.AdminRoutes())
+	defer adminServer.Close()
 
 	post := func(path string, payload any) {
 		body, err := json.Marshal(payload)
@@ -79,6 +82,28 @@ func TestHTTPServerFlow(t *testing.T) {
 	checkPlayer("1001", "AkiHero", 120)
 	checkPlayer("2002", "Mio", 45)
 
+	resp, err := http.Get(httpServer.URL + "/debug/worlds")
+	if err != nil {
+		t.Fatalf("public debug: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("public listener serves the inspector: status %d", resp.StatusCode)
+	}
+	resp, err = http.Get(adminServer.URL + "/debug/worlds/http-world/entities/1001")
+	if err != nil {
+		t.Fatalf("inspect player: %v", err)
+	}
+	var detail debug.EntityDetail
+	err = json.NewDecoder(resp.Body).Decode(&detail)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("decode inspector response: %v", err)
+	}
+	if detail.Id != "1001" || len(detail.Components) != 2 || len(detail.Dirty) != 2 {
+		t.Fatalf("inspector detail = %+v", detail)
+	}
+
 	if err := persistenceSys.Flush(context.Background(), world); err != nil {
 		t.Fatalf("flush: %v", err)
 	}
@@ -86,3 +111,161 @@ func TestHTTPServerFlow(t *testing.T) {
 		t.Fatalf("expected profile persisted: %v", err)
 	}
 }
+
+func TestHTTPServerAuditsWalletChanges(t *testing.T) {
+	world := NewGameWorld("audit-world")
+	ecstest.StartWorld(t, world)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := audit.OpenFileSink(path)
+	if err != nil {
+		t.Fatalf("open audit sink: %v", err)
+	}
+	defer sink.Close()
+
+	server, err := NewServer(world, &AuthSystem{}, &WalletSystem{}, &ProfileSystem{})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	server.SetAuditor(audit.NewAuditor(sink, componentRegistry, ComponentTypeWallet))
+	tokens := map[string]string{"t-1001": "player:1001", "t-gm7": "gm:7", "t-gm8": "gm:8"}
+	server.SetAuthenticator(func(r *http.Request) (string, error) {
+		id, ok := tokens[r.Header.Get("Authorization")]
+		if !ok {
+			return "", errors.New("unknown token")
+		}
+		return id, nil
+	})
+	httpServer := httptest.NewServer(server.Routes())
+	defer httpServer.Close()
+	adminServer := httptest.NewServer(server.AdminRoutes())
+	defer adminServer.Close()
+
+	send := func(method string, path string, token string, payload any) int {
+		body, _ := json.Marshal(payload)
+		base := httpServer.URL
+		if strings.HasPrefix(path, "/debug/") {
+			base = adminServer.URL
+		}
+		req, _ := http.NewRequest(method, base+path, bytes.NewReader(body))
+		req.Header.Set("Authorization", token)
+		// The header is ignored once the server authenticates callers.
+		req.Header.Set(ActorHeader, "gm:spoofed")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("post %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	sendOK := func(method string, path string, token string, payload any) {
+		if code := send(method, path, token, payload); code != http.StatusOK {
+			t.Fatalf("%s %s status = %d", method, path, code)
+		}
+	}
+	sendOK(http.MethodPost, "/login", "t-1001", map[string]any{"player_id": "1001", "name": "Aki"})
+	sendOK(http.MethodPost, "/add-gold", "t-gm7", map[string]any{"player_id": "1001", "amount": 120})
+	sendOK(http.MethodPost, "/rename", "t-1001", map[string]any{"player_id": "1001", "name": "AkiHero"})
+	if code := send(http.MethodPost, "/add-gold", "forged", map[string]any{"player_id": "1001", "amount": 1}); code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated add gold status = %d", code)
+	}
+	// Inspector edits are audited like any other request.
+	server.inspector.SetEditGuard(func(r *http.Request) error { return nil })
+	sendOK(http.MethodPatch, "/debug/worlds/audit-world/entities/1001/components/wallet", "t-gm8", map[string]any{"gold": 500})
+
+	records, err := audit.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read audit file: %v", err)
+	}
+	// The wallet's whole life is on record, from the login that created it.
+	if len(records) != 3 {
+		t.Fatalf("audit records = %+v", records)
+	}
+	for i, rec := range records {
+		if rec.EntityId != "1001" || rec.Component != "wallet" || !strings.HasPrefix(rec.Actor.Source, "127.0.0.1:") {
+			t.Fatalf("audit record %d = %+v", i, rec)
+		}
+		if i > 0 && rec.OldVersion != records[i-1].NewVersion {
+			t.Fatalf("audit record %d starts at version %d, previous ended at %d", i, rec.OldVersion, records[i-1].NewVersion)
+		}
+	}
+	created := records[0]
+	if created.Op != audit.OpAdd || created.Actor.Id != "player:1001" || created.Actor.Reason != "POST /login" || !bytes.Contains(created.Diff, []byte(`"gold":0`)) {
+		t.Fatalf("login audit record = %+v diff %s", created, created.Diff)
+	}
+	rec := records[1]
+	if rec.Op != audit.OpUpdate || rec.Actor.Id != "gm:7" || rec.Actor.Reason != "POST /add-gold" || rec.NewVersion != rec.OldVersion+1 {
+		t.Fatalf("audit record = %+v", rec)
+	}
+	if !bytes.Contains(rec.Diff, []byte(`"gold":120`)) {
+		t.Fatalf("audit diff = %s", rec.Diff)
+	}
+	edit := records[2]
+	if edit.Actor.Id != "gm:8" || edit.Actor.Reason != "PATCH /debug/worlds/audit-world/entities/1001/components/wallet" || !bytes.Contains(edit.Diff, []byte(`"gold":500`)) {
+		t.Fatalf("inspector audit record = %+v diff %s", edit, edit.Diff)
+	}
+}
+
+func TestHTTPServerRunsCommandsOnMailboxes(t *testing.T) {
+	world := NewGameWorld("mailbox-world")
+	ecstest.StartWorld(t, world)
+	mailboxes := ginka_ecs_go.NewMailboxes(world.Entities, ginka_ecs_go.MailboxConfig{Workers: 2, MailboxSize: 64})
+	defer mailboxes.Close()
+
+	server, err := NewServer(world, &AuthSystem{}, &WalletSystem{}, &ProfileSystem{})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	server.SetMailboxes(mailboxes)
+	httpServer := httptest.NewServer(server.Routes())
+	defer httpServer.Close()
+
+	post := func(path string, payload any) *http.Response {
+		body, _ := json.Marshal(payload)
+		resp, err := http.Post(httpServer.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Errorf("post %s: %v", path, err)
+			return nil
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := post("/login", map[string]any{"player_id": "1001", "name": "Aki"}); resp == nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("login failed")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp := post("/add-gold", map[string]any{"player_id": "1001", "amount": 5}); resp != nil && resp.StatusCode != http.StatusOK {
+				t.Errorf("add gold status = %d", resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+	entity, _ := world.Entities.Get("1001")
+	wallet, _ := ginka_ecs_go.Get[*WalletComponent](entity, ComponentTypeWallet)
+	if wallet.Gold != 100 {
+		t.Fatalf("wallet gold = %d", wallet.Gold)
+	}
+	if resp := post("/add-gold", map[string]any{"player_id": "9999", "amount": 5}); resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown player was not rejected")
+	}
+
+	// Fill the player's mailbox so the next request is refused.
+	release := make(chan struct{})
+	for i := 0; i < 64; i++ {
+		if err := mailboxes.Tell(context.Background(), "1001", func(ctx context.Context, ent ginka_ecs_go.DataEntity) error {
+			<-release
+			return nil
+		}); err != nil {
+			t.Fatalf("tell: %v", err)
+		}
+	}
+	resp := post("/rename", map[string]any{"player_id": "1001", "name": "AkiHero"})
+	close(release)
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("full mailbox response = %+v", resp)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// Leaderboard is a persistent world resource ranking players by gold. Its
+// Gold map is shared by copies, so read it only inside
+// ginka_ecs_go.ReadResource.
+type Leaderboard struct {
+	Gold map[string]int64
+}
+
+// StorageKey implements ginka_ecs_go.PersistentResource.
+func (l Leaderboard) StorageKey() string {
+	return "leaderboard"
+}
+
+type LeaderboardEntry struct {
+	PlayerId string
+	Gold     int64
+}
+
+// Top returns the n richest players, ties broken by id.
+func (l Leaderboard) Top(n int) []LeaderboardEntry {
+	out := make([]LeaderboardEntry, 0, len(l.Gold))
+	for id, gold := range l.Gold {
+		out = append(out, LeaderboardEntry{PlayerId: id, Gold: gold})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Gold != out[j].Gold {
+			return out[i].Gold > out[j].Gold
+		}
+		return out[i].PlayerId < out[j].PlayerId
+	})
+	if n >= 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// LeaderboardSystem refreshes the Leaderboard resource from wallets that
+// changed since its previous run. Removed players are dropped by the hook
+// AttachLeaderboard installs.
+type LeaderboardSystem struct {
+	cursor ginka_ecs_go.ChangeCursor
+}
+
+func (s *LeaderboardSystem) Name() string {
+	return "leaderboard"
+}
+
+func (s *LeaderboardSystem) ResourceAccess() ginka_ecs_go.ResourceAccess {
+	return ginka_ecs_go.ResourceAccess{
+		Writes: []reflect.Type{ginka_ecs_go.ResourceTypeOf[Leaderboard]()},
+	}
+}
+
+// Update returns the number of wallets refreshed.
+func (s *LeaderboardSystem) Update(ctx context.Context, w *GameWorld) (int, error) {
+	since := s.cursor.Begin()
+	type walletGold struct {
+		ent  ginka_ecs_go.DataEntity
+		gold int64
+	}
+	changed := make(map[string]walletGold)
+	filters := []ginka_ecs_go.Filter{ginka_ecs_go.Changed(ComponentTypeWallet, since)}
+	err := ginka_ecs_go.ForEachMatching(ctx, w.Entities, filters, func(ent ginka_ecs_go.DataEntity) error {
+		return ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+			wallet, ok := GetWallet(tx)
+			if ok {
+				changed[tx.Id()] = walletGold{ent: ent, gold: wallet.Gold}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+	err = ginka_ecs_go.UpdateResource(w, func(board *Leaderboard) error {
+		if board.Gold == nil {
+			board.Gold = make(map[string]int64, len(changed))
+		}
+		for id, entry := range changed {
+			// A player removed since the scan has already been dropped
+			// by the remove hook; do not bring it back.
+			if current, ok := w.Entities.Get(id); !ok || current != entry.ent {
+				continue
+			}
+			board.Gold[id] = entry.gold
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(changed), nil
+}
+
+// AttachLeaderboard drops players from the Leaderboard of w when they are
+// removed from its entity manager. The returned func detaches the hook.
+func AttachLeaderboard(w *GameWorld) func() {
+	return w.Entities.OnRemove(func(ent ginka_ecs_go.DataEntity) {
+		_ = ginka_ecs_go.UpdateResource(w, func(board *Leaderboard) error {
+			delete(board.Gold, ent.Id())
+			return nil
+		})
+	})
+}
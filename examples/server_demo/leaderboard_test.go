@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+func TestLeaderboardSystem_OnlyRefreshesChangedWallets(t *testing.T) {
+	ctx := context.Background()
+	world := NewGameWorld("leaderboard-world")
+	authSys := &AuthSystem{}
+	walletSys := &WalletSystem{}
+	leaderboardSys := &LeaderboardSystem{}
+	if err := ginka_ecs_go.CheckSystemResources(world, leaderboardSys); err != nil {
+		t.Fatalf("check resources: %v", err)
+	}
+
+	for _, id := range []string{"1001", "2002"} {
+		if err := authSys.Login(ctx, world, LoginRequest{PlayerId: id, Name: "p" + id}); err != nil {
+			t.Fatalf("login: %v", err)
+		}
+	}
+	if n, err := leaderboardSys.Update(ctx, world); err != nil || n != 2 {
+		t.Fatalf("first update = %d, %v", n, err)
+	}
+	if n, err := leaderboardSys.Update(ctx, world); err != nil || n != 0 {
+		t.Fatalf("idle update = %d, %v", n, err)
+	}
+
+	if err := walletSys.AddGold(ctx, world, AddGoldRequest{PlayerId: "2002", Amount: 50}); err != nil {
+		t.Fatalf("add gold: %v", err)
+	}
+	if n, err := leaderboardSys.Update(ctx, world); err != nil || n != 1 {
+		t.Fatalf("update after add gold = %d, %v", n, err)
+	}
+
+	var got []LeaderboardEntry
+	if err := ginka_ecs_go.ReadResource(world, func(board Leaderboard) error {
+		got = board.Top(10)
+		return nil
+	}); err != nil {
+		t.Fatalf("read leaderboard: %v", err)
+	}
+	want := []LeaderboardEntry{{PlayerId: "2002", Gold: 50}, {PlayerId: "1001", Gold: 0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("top = %+v", got)
+	}
+}
+
+func TestLeaderboard_DropsRemovedPlayers(t *testing.T) {
+	ctx := context.Background()
+	world := NewGameWorld("leaderboard-remove-world")
+	for _, id := range []string{"1001", "2002"} {
+		if err := (&AuthSystem{}).Login(ctx, world, LoginRequest{PlayerId: id, Name: "p" + id}); err != nil {
+			t.Fatalf("login: %v", err)
+		}
+	}
+	if err := world.Tick(ctx); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if !world.Entities.Remove("1001") {
+		t.Fatalf("remove 1001")
+	}
+
+	var got []LeaderboardEntry
+	_ = ginka_ecs_go.ReadResource(world, func(board Leaderboard) error {
+		got = board.Top(-1)
+		return nil
+	})
+	if want := []LeaderboardEntry{{PlayerId: "2002", Gold: 0}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("top after remove = %+v", got)
+	}
+}
@@ -2,17 +2,37 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 )
 
 func main() {
+	dataDir := flag.String("data", "tmp/server_demo", "directory for persisted components")
+	migrate := flag.Bool("migrate", false, "upgrade stored components to the current schema versions and exit")
+	flag.Parse()
+
 	ctx := context.Background()
+	persistenceSys := NewFilePersistenceSystem(*dataDir)
+	if err := persistenceSys.SyncManifest(); err != nil {
+		log.Fatal(err)
+	}
+	if *migrate {
+		stats, err := persistenceSys.Migrate(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("migrate: %d scanned, %d rewritten\n", stats.Scanned, stats.Migrated)
+		return
+	}
+
 	world := NewGameWorld("demo-world")
+	if err := persistenceSys.LoadResources(world); err != nil {
+		log.Fatal(err)
+	}
 	authSys := &AuthSystem{}
 	profileSys := &ProfileSystem{}
 	walletSys := &WalletSystem{}
-	persistenceSys := NewFilePersistenceSystem("tmp/server_demo")
 	runDone := make(chan error, 1)
 	go func() {
 		runDone <- world.Run()
@@ -43,7 +63,12 @@ func main() {
 		log.Fatal(err)
 	}
 
-	fmt.Println("flush: dirty components")
+	fmt.Println("tick: refresh leaderboard")
+	if err := world.Tick(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("flush: dirty components and resources")
 	if err := persistenceSys.Flush(ctx, world); err != nil {
 		log.Fatal(err)
 	}
@@ -2,13 +2,13 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
-	"time"
 
 	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+	"github.com/Shigure42/ginka-ecs-go/ecstest"
 )
 
 func TestServerDemoFlow(t *testing.T) {
@@ -19,15 +19,7 @@ func TestServerDemoFlow(t *testing.T) {
 	profileSys := &ProfileSystem{}
 	walletSys := &WalletSystem{}
 	persistenceSys := NewFilePersistenceSystem(baseDir)
-	runDone := startWorld(t, world)
-	defer func() {
-		if err := world.Stop(); err != nil {
-			t.Fatalf("stop world: %v", err)
-		}
-		if err := <-runDone; err != nil {
-			t.Fatalf("run world: %v", err)
-		}
-	}()
+	ecstest.StartWorld(t, world)
 
 	playerId := "1001"
 
@@ -75,7 +67,7 @@ func TestServerDemoFlow(t *testing.T) {
 	var profileDisk struct {
 		Name string `json:"name"`
 	}
-	if err := json.Unmarshal(data, &profileDisk); err != nil {
+	if _, err := ginka_ecs_go.DecodePayload(data, &profileDisk); err != nil {
 		t.Fatalf("unmarshal profile: %v", err)
 	}
 	if profileDisk.Name != "AkiHero" {
@@ -90,7 +82,7 @@ func TestServerDemoFlow(t *testing.T) {
 	var walletDisk struct {
 		Gold int64 `json:"gold"`
 	}
-	if err := json.Unmarshal(data, &walletDisk); err != nil {
+	if _, err := ginka_ecs_go.DecodePayload(data, &walletDisk); err != nil {
 		t.Fatalf("unmarshal wallet: %v", err)
 	}
 	if walletDisk.Gold != 120 {
@@ -98,29 +90,234 @@ func TestServerDemoFlow(t *testing.T) {
 	}
 }
 
-func startWorld(t *testing.T, world ginka_ecs_go.World) chan error {
-	t.Helper()
-	runDone := make(chan error, 1)
-	go func() {
-		runDone <- world.Run()
-	}()
-	waitForRunning(t, world)
-	return runDone
+func TestFilePersistence_WritesPatchesAfterFirstFlush(t *testing.T) {
+	ctx := context.Background()
+	baseDir := t.TempDir()
+	world := NewGameWorld("patch-world")
+	authSys := &AuthSystem{}
+	walletSys := &WalletSystem{}
+	profileSys := &ProfileSystem{}
+	persistenceSys := NewFilePersistenceSystem(baseDir)
+
+	if err := authSys.Login(ctx, world, LoginRequest{PlayerId: "1001", Name: "Aki"}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if err := persistenceSys.Flush(ctx, world); err != nil {
+		t.Fatalf("first flush: %v", err)
+	}
+	if err := walletSys.AddGold(ctx, world, AddGoldRequest{PlayerId: "1001", Amount: 75}); err != nil {
+		t.Fatalf("add gold: %v", err)
+	}
+	if err := profileSys.Rename(ctx, world, RenameRequest{PlayerId: "1001", Name: "AkiHero"}); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	stats, err := ginka_ecs_go.FlushAll(ctx, world.Entities, persistenceSys.persister)
+	if err != nil {
+		t.Fatalf("second flush: %v", err)
+	}
+	if stats.Patches != 2 || stats.Full != 0 {
+		t.Fatalf("expected two patches, got %+v", stats)
+	}
+
+	data, err := os.ReadFile(filepath.Join(baseDir, "1001", "wallet.json"))
+	if err != nil {
+		t.Fatalf("read wallet: %v", err)
+	}
+	var walletDisk struct {
+		Gold    int64  `json:"gold"`
+		Version uint64 `json:"version"`
+	}
+	if _, err := ginka_ecs_go.DecodePayload(data, &walletDisk); err != nil {
+		t.Fatalf("unmarshal wallet: %v", err)
+	}
+	if walletDisk.Gold != 75 || walletDisk.Version != 2 {
+		t.Fatalf("wallet on disk = %+v", walletDisk)
+	}
+}
+
+func TestFilePersistence_RemovesFilesOfPreviousCodec(t *testing.T) {
+	ctx := context.Background()
+	baseDir := t.TempDir()
+	world := NewGameWorld("codec-world")
+	persistenceSys := NewFilePersistenceSystem(baseDir)
+	if err := (&AuthSystem{}).Login(ctx, world, LoginRequest{PlayerId: "1001", Name: "Aki"}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	// The wallet used to be stored with another codec.
+	stale := filepath.Join(baseDir, "1001", "wallet."+ginka_ecs_go.GobCodec.Name())
+	if err := os.MkdirAll(filepath.Dir(stale), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(stale, []byte("old"), 0o644); err != nil {
+		t.Fatalf("write stale wallet: %v", err)
+	}
+
+	if err := persistenceSys.Flush(ctx, world); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("stale wallet file kept: %v", err)
+	}
+	components, err := persistenceSys.LoadEntity(ctx, "1001")
+	if err != nil || len(components) != 2 {
+		t.Fatalf("load = %d components, %v", len(components), err)
+	}
 }
 
-func waitForRunning(t *testing.T, world ginka_ecs_go.World) {
-	t.Helper()
-	deadline := time.NewTimer(2 * time.Second)
-	defer deadline.Stop()
-	for {
-		if world.IsRunning() {
-			return
+func TestFilePersistence_RestoresLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	baseDir := t.TempDir()
+	world := NewGameWorld("leaderboard-world")
+	persistenceSys := NewFilePersistenceSystem(baseDir)
+
+	if err := (&AuthSystem{}).Login(ctx, world, LoginRequest{PlayerId: "1001", Name: "Aki"}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if err := (&WalletSystem{}).AddGold(ctx, world, AddGoldRequest{PlayerId: "1001", Amount: 40}); err != nil {
+		t.Fatalf("add gold: %v", err)
+	}
+	if err := world.Tick(ctx); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if err := persistenceSys.Flush(ctx, world); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if len(world.Resources().DirtyResources()) != 0 {
+		t.Fatalf("flush should clear the leaderboard dirty flag")
+	}
+
+	restored := NewGameWorld("restored-world")
+	if err := NewFilePersistenceSystem(baseDir).LoadResources(restored); err != nil {
+		t.Fatalf("load resources: %v", err)
+	}
+	var top []LeaderboardEntry
+	_ = ginka_ecs_go.ReadResource(restored, func(board Leaderboard) error {
+		top = board.Top(-1)
+		return nil
+	})
+	if len(top) != 1 || top[0] != (LeaderboardEntry{PlayerId: "1001", Gold: 40}) {
+		t.Fatalf("restored leaderboard = %+v", top)
+	}
+	if err := persistenceSys.LoadComponents(ctx, func(rec ginka_ecs_go.ComponentRecord) error {
+		if rec.EntityId == resourcesDir {
+			t.Fatalf("resources loaded as components: %+v", rec)
 		}
-		select {
-		case <-deadline.C:
-			t.Fatalf("world did not start")
-		default:
-			time.Sleep(5 * time.Millisecond)
+		return nil
+	}); err != nil {
+		t.Fatalf("load components: %v", err)
+	}
+}
+
+func TestFilePersistence_MigratesLegacyProfiles(t *testing.T) {
+	ctx := context.Background()
+	baseDir := t.TempDir()
+	dir := filepath.Join(baseDir, "1001")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	// Written before profiles had a title or payloads had headers.
+	if err := os.WriteFile(filepath.Join(dir, "profile.json"), []byte(`{"name":"Aki","version":1}`), 0o644); err != nil {
+		t.Fatalf("write legacy profile: %v", err)
+	}
+	persistenceSys := NewFilePersistenceSystem(baseDir)
+
+	components, err := persistenceSys.LoadEntity(ctx, "1001")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(components) != 1 {
+		t.Fatalf("loaded %d components", len(components))
+	}
+	profile := components[0].(*ProfileComponent)
+	if profile.Name != "Aki" || profile.Title != defaultProfileTitle || profile.Version() != 1 {
+		t.Fatalf("loaded profile = %+v", profile)
+	}
+
+	stats, err := persistenceSys.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if stats.Scanned != 1 || stats.Migrated != 1 {
+		t.Fatalf("migrate stats = %+v", stats)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "profile.json"))
+	if err != nil {
+		t.Fatalf("read profile: %v", err)
+	}
+	header, _, err := ginka_ecs_go.SplitPayload(data)
+	if err != nil || header.Schema != 2 {
+		t.Fatalf("migrated header = %+v, err %v", header, err)
+	}
+
+	stats, err = persistenceSys.Migrate(ctx)
+	if err != nil || stats.Migrated != 0 {
+		t.Fatalf("second migrate: %+v, %v", stats, err)
+	}
+}
+
+func TestFilePersistence_SyncManifestDetectsChangedIds(t *testing.T) {
+	baseDir := t.TempDir()
+	persistenceSys := NewFilePersistenceSystem(baseDir)
+	if err := persistenceSys.SyncManifest(); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	if err := persistenceSys.SyncManifest(); err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+
+	// Simulate data written by a build where the constants were swapped.
+	swapped := []byte(`{"profile": 2, "wallet": 1}`)
+	if err := os.WriteFile(filepath.Join(baseDir, manifestFile), swapped, 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	if err := persistenceSys.SyncManifest(); !errors.Is(err, ginka_ecs_go.ErrComponentTypeMismatch) {
+		t.Fatalf("expected ErrComponentTypeMismatch, got %v", err)
+	}
+}
+
+func TestGameWorld_TimersPersistAndCancelOnRemove(t *testing.T) {
+	ctx := context.Background()
+	baseDir := t.TempDir()
+	world := NewGameWorld("timers-world")
+	persistenceSys := NewFilePersistenceSystem(baseDir)
+	noop := func(ctx context.Context, ent ginka_ecs_go.DataEntity, timer ginka_ecs_go.Timer) error { return nil }
+	world.Timers().Handle("invite_timeout", noop)
+
+	if err := (&AuthSystem{}).Login(ctx, world, LoginRequest{PlayerId: "1001", Name: "Aki"}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	player, _ := world.Entities.Get("1001")
+	at := world.Timers().TickTime(world.Timers().Tick() + 100)
+	if _, err := world.Timers().Schedule(player, at, "invite_timeout", nil); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if err := persistenceSys.Flush(ctx, world); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	// Removing the player cancels its pending timers.
+	world.Entities.Remove("1001")
+	if n := world.Timers().Len(); n != 0 {
+		t.Fatalf("pending after remove = %d", n)
+	}
+
+	// Loading the player into another world brings its timers back.
+	components, err := persistenceSys.LoadEntity(ctx, "1001")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	restarted := NewGameWorld("timers-world")
+	restarted.Timers().Handle("invite_timeout", noop)
+	loaded := ginka_ecs_go.NewDataEntityCore("1001", "Aki", EntityTypePlayer)
+	for _, c := range components {
+		if err := loaded.Add(c); err != nil {
+			t.Fatalf("add %d: %v", c.ComponentType(), err)
 		}
 	}
+	if err := restarted.Entities.Add(ctx, loaded); err != nil {
+		t.Fatalf("add player: %v", err)
+	}
+	if n, err := restarted.Timers().Restore(loaded); err != nil || n != 1 {
+		t.Fatalf("restore = %d, %v", n, err)
+	}
 }
@@ -0,0 +1,24 @@
+package main
+
+import (
+	_ "embed"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+//go:embed prefabs/player.json
+var playerPrefabJSON []byte
+
+// playerPrefab spawns players with a profile and an empty wallet, both
+// marked dirty so the first flush persists them. Its entity_type is the
+// numeric value of EntityTypePlayer; TestPlayerPrefab_EntityType keeps the
+// two in sync.
+var playerPrefab = mustLoadPrefab(playerPrefabJSON, componentRegistry)
+
+func mustLoadPrefab(data []byte, reg *ginka_ecs_go.ComponentRegistry) *ginka_ecs_go.Prefab {
+	prefab, err := ginka_ecs_go.LoadPrefab(data, reg)
+	if err != nil {
+		panic(err)
+	}
+	return prefab
+}
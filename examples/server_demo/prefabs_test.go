@@ -0,0 +1,9 @@
+package main
+
+import "testing"
+
+func TestPlayerPrefab_EntityType(t *testing.T) {
+	if playerPrefab.EntityType != EntityTypePlayer {
+		t.Fatalf("prefabs/player.json entity_type = %d, want EntityTypePlayer (%d)", playerPrefab.EntityType, EntityTypePlayer)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+	"github.com/Shigure42/ginka-ecs-go/replay"
+)
+
+// Input kinds recorded by a replay session.
+const (
+	InputLogin   = "login"
+	InputAddGold = "add_gold"
+	InputRename  = "rename"
+	InputTick    = "tick"
+)
+
+// ReplaySession records the requests applied to a GameWorld.
+type ReplaySession = replay.Session[ginka_ecs_go.DataEntity]
+
+// NewReplayHandlers routes recorded requests to the systems acting on w,
+// and ticks to w.Tick.
+func NewReplayHandlers(w *GameWorld, auth *AuthSystem, wallet *WalletSystem, profile *ProfileSystem) *replay.Handlers {
+	h := replay.NewHandlers()
+	replay.Handle(h, InputLogin, func(ctx context.Context, in replay.Input[LoginRequest]) error {
+		return auth.Login(ctx, w, in.Request)
+	})
+	replay.Handle(h, InputAddGold, func(ctx context.Context, in replay.Input[AddGoldRequest]) error {
+		return wallet.AddGold(ctx, w, in.Request)
+	})
+	replay.Handle(h, InputRename, func(ctx context.Context, in replay.Input[RenameRequest]) error {
+		return profile.Rename(ctx, w, in.Request)
+	})
+	replay.Handle(h, InputTick, func(ctx context.Context, in replay.Input[TickRequest]) error {
+		return w.Tick(ctx)
+	})
+	return h
+}
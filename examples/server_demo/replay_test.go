@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+	"github.com/Shigure42/ginka-ecs-go/ecstest"
+	"github.com/Shigure42/ginka-ecs-go/replay"
+)
+
+func TestServerRecordsReplayableSession(t *testing.T) {
+	ctx := context.Background()
+	authSys := &AuthSystem{}
+	profileSys := &ProfileSystem{}
+	walletSys := &WalletSystem{}
+
+	world := NewGameWorld("replay-world")
+	ecstest.StartWorld(t, world)
+	var log bytes.Buffer
+	session, err := replay.NewSession(ctx, &log, world.GetName(), world.Entities, componentRegistry, NewReplayHandlers(world, authSys, walletSys, profileSys), 7)
+	if err != nil {
+		t.Fatalf("session: %v", err)
+	}
+	server, err := NewServer(world, authSys, walletSys, profileSys)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	server.SetSession(session)
+	httpServer := httptest.NewServer(server.Routes())
+	defer httpServer.Close()
+	adminServer := httptest.NewServer(server.AdminRoutes())
+	defer adminServer.Close()
+
+	post := func(path string, payload any, wantStatus int) {
+		body, _ := json.Marshal(payload)
+		resp, err := http.Post(httpServer.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("post %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("post %s status = %d", path, resp.StatusCode)
+		}
+	}
+	tick := func() {
+		if err := server.Tick(ctx); err != nil {
+			t.Fatalf("tick: %v", err)
+		}
+	}
+	post("/login", map[string]any{"player_id": "1001", "name": "Aki"}, http.StatusOK)
+	post("/login", map[string]any{"player_id": "2002", "name": "Mio"}, http.StatusOK)
+	tick()
+	post("/add-gold", map[string]any{"player_id": "1001", "amount": 120}, http.StatusOK)
+	post("/add-gold", map[string]any{"player_id": "9999", "amount": 5}, http.StatusBadRequest)
+	tick()
+	post("/rename", map[string]any{"player_id": "1001", "name": "AkiHero"}, http.StatusOK)
+	tick()
+
+	// Inspector edits would change the world behind the log's back.
+	server.inspector.SetEditGuard(func(r *http.Request) error { return nil })
+	req, _ := http.NewRequest(http.MethodPatch, adminServer.URL+"/debug/worlds/replay-world/entities/1001/components/wallet", bytes.NewReader([]byte(`{"gold":1}`)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("debug edit while recording status = %d", resp.StatusCode)
+	}
+
+	recorded, err := replay.ReadLog(&log)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	fresh := NewGameWorld("replay-world")
+	ecstest.StartWorld(t, fresh)
+	res, err := replay.Replay(ctx, recorded, fresh.Entities, componentRegistry, NewReplayHandlers(fresh, authSys, walletSys, profileSys))
+	if err != nil {
+		t.Fatalf("replay: %v (%+v)", err, res)
+	}
+	if res.Inputs != 8 || res.Checkpoints != 3 {
+		t.Fatalf("result = %+v", res)
+	}
+
+	var want, got bytes.Buffer
+	_ = ginka_ecs_go.DumpEntities(ctx, &want, world.Entities, componentRegistry)
+	_ = ginka_ecs_go.DumpEntities(ctx, &got, fresh.Entities, componentRegistry)
+	if want.String() != got.String() {
+		t.Fatalf("replayed world differs:\n%s", ecstest.LineDiff(want.String(), got.String()))
+	}
+	// The leaderboard is refreshed by recorded ticks, so it replays too.
+	top := func(w *GameWorld) (out []LeaderboardEntry) {
+		_ = ginka_ecs_go.ReadResource(w, func(board Leaderboard) error {
+			out = board.Top(-1)
+			return nil
+		})
+		return out
+	}
+	if want, got := top(world), top(fresh); len(want) != 2 || !reflect.DeepEqual(want, got) {
+		t.Fatalf("leaderboard = %v, replayed %v", want, got)
+	}
+}
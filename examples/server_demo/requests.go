@@ -14,3 +14,7 @@ type RenameRequest struct {
 	PlayerId string
 	Name     string
 }
+
+// TickRequest ends a world tick. It carries nothing; recording it puts the
+// tick's timer firings and leaderboard refresh in the replay log.
+type TickRequest struct{}
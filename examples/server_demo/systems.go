@@ -15,25 +15,14 @@ func (s *AuthSystem) Name() string {
 }
 
 func (s *AuthSystem) Login(ctx context.Context, w *GameWorld, login LoginRequest) error {
-	player, err := w.Entities.Create(ctx, login.PlayerId, login.Name, EntityTypePlayer, TagPlayer)
+	_, err := ginka_ecs_go.Spawn(ctx, w.Entities, playerPrefab, login.PlayerId, login.Name, NewProfileComponent(login.Name))
 	if err != nil {
 		if errors.Is(err, ginka_ecs_go.ErrEntityAlreadyExists) {
 			return nil
 		}
 		return err
 	}
-	return player.Tx(func(tx ginka_ecs_go.DataEntity) error {
-		if err := tx.Add(NewProfileComponent(login.Name)); err != nil {
-			return err
-		}
-		if err := tx.Add(NewWalletComponent(0)); err != nil {
-			return err
-		}
-		// Mark new components as dirty so they will be persisted.
-		tx.GetForUpdate(ComponentTypeProfile)
-		tx.GetForUpdate(ComponentTypeWallet)
-		return nil
-	})
+	return nil
 }
 
 type WalletSystem struct{}
@@ -43,6 +32,12 @@ func (s *WalletSystem) Name() string {
 }
 
 func (s *WalletSystem) AddGold(ctx context.Context, w *GameWorld, addGold AddGoldRequest) error {
+	return ginka_ecs_go.RunSystem(ctx, s, func(ctx context.Context) error {
+		return s.addGold(ctx, w, addGold)
+	})
+}
+
+func (s *WalletSystem) addGold(ctx context.Context, w *GameWorld, addGold AddGoldRequest) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -50,8 +45,8 @@ func (s *WalletSystem) AddGold(ctx context.Context, w *GameWorld, addGold AddGol
 	if !exists {
 		return fmt.Errorf("wallet system: player %s: %w", addGold.PlayerId, ginka_ecs_go.ErrEntityNotFound)
 	}
-	return player.Tx(func(tx ginka_ecs_go.DataEntity) error {
-		wallet, ok, err := ginka_ecs_go.GetForUpdateE[*WalletComponent](tx, ComponentTypeWallet)
+	return ginka_ecs_go.TxContext(ctx, player, func(ctx context.Context, tx ginka_ecs_go.DataEntity) error {
+		wallet, ok, err := GetWalletForUpdate(tx)
 		if err != nil {
 			return fmt.Errorf("wallet system: get component %d for update: %w", ComponentTypeWallet, err)
 		}
@@ -59,6 +54,7 @@ func (s *WalletSystem) AddGold(ctx context.Context, w *GameWorld, addGold AddGol
 			return fmt.Errorf("wallet system: component %d: %w", ComponentTypeWallet, ginka_ecs_go.ErrComponentNotFound)
 		}
 		wallet.Gold += addGold.Amount
+		wallet.MarkFieldChanged("gold")
 		return nil
 	})
 }
@@ -70,6 +66,12 @@ func (s *ProfileSystem) Name() string {
 }
 
 func (s *ProfileSystem) Rename(ctx context.Context, w *GameWorld, rename RenameRequest) error {
+	return ginka_ecs_go.RunSystem(ctx, s, func(ctx context.Context) error {
+		return s.rename(ctx, w, rename)
+	})
+}
+
+func (s *ProfileSystem) rename(ctx context.Context, w *GameWorld, rename RenameRequest) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -77,8 +79,8 @@ func (s *ProfileSystem) Rename(ctx context.Context, w *GameWorld, rename RenameR
 	if !exists {
 		return fmt.Errorf("profile system: player %s: %w", rename.PlayerId, ginka_ecs_go.ErrEntityNotFound)
 	}
-	return player.Tx(func(tx ginka_ecs_go.DataEntity) error {
-		profile, ok, err := ginka_ecs_go.GetForUpdateE[*ProfileComponent](tx, ComponentTypeProfile)
+	return ginka_ecs_go.TxContext(ctx, player, func(ctx context.Context, tx ginka_ecs_go.DataEntity) error {
+		profile, ok, err := GetProfileForUpdate(tx)
 		if err != nil {
 			return fmt.Errorf("profile system: get component %d for update: %w", ComponentTypeProfile, err)
 		}
@@ -0,0 +1,53 @@
+package ginka_ecs_go
+
+// FieldChangeReporter is implemented by components that know which of their
+// fields changed since the last flush. Persister uses it to build partial
+// updates without diffing against the previous payload.
+type FieldChangeReporter interface {
+	// ChangedFields returns the encoded (JSON) names of changed top-level fields.
+	ChangedFields() []string
+	// ClearChangedFields forgets all changed fields.
+	ClearChangedFields()
+}
+
+// FieldTracker is a basic FieldChangeReporter implementation.
+// Embed it in a DataComponent and call MarkFieldChanged next to each write.
+type FieldTracker struct {
+	fields []string
+}
+
+// MarkFieldChanged records that the named fields changed.
+func (f *FieldTracker) MarkFieldChanged(names ...string) {
+	for _, name := range names {
+		if containsString(f.fields, name) {
+			continue
+		}
+		f.fields = append(f.fields, name)
+	}
+}
+
+// ChangedFields returns a copy of the changed field names.
+func (f *FieldTracker) ChangedFields() []string {
+	if len(f.fields) == 0 {
+		return nil
+	}
+	out := make([]string, len(f.fields))
+	copy(out, f.fields)
+	return out
+}
+
+// ClearChangedFields forgets all changed fields.
+func (f *FieldTracker) ClearChangedFields() {
+	f.fields = nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+var _ FieldChangeReporter = (*FieldTracker)(nil)
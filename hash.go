@@ -0,0 +1,201 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// HashEntity returns a stable 64-bit FNV-1a hash of ent's state: its id,
+// type, sorted tags and enabled flag, then its components in ComponentType
+// order with their enabled flag, sorted tags and payload encoded through the
+// registered codec. The entity name is not included.
+//
+// Equal state hashes equally across processes as long as the codecs encode
+// deterministically; JSONCodec and BinaryCodec do, GobCodec does not for
+// maps. DataEntities are read inside Tx. A nil reg uses
+// DefaultComponentRegistry.
+func HashEntity(ent Entity, reg *ComponentRegistry) (uint64, error) {
+	sum, _, err := hashEntity(ent, reg)
+	return sum, err
+}
+
+// hashEntity returns the hash of ent and, for a StateTracker, the state
+// tick the hash reflects.
+func hashEntity(ent Entity, reg *ComponentRegistry) (uint64, Tick, error) {
+	if reg == nil {
+		reg = DefaultComponentRegistry
+	}
+	var sum uint64
+	var tick Tick
+	hashState := func(e Entity) error {
+		if tracker, ok := e.(StateTracker); ok {
+			tick = tracker.StateTick()
+		}
+		h := fnv.New64a()
+		hashString(h, e.Id())
+		hashUint(h, uint64(e.Type()))
+		hashTags(h, e.Tags())
+		hashBool(h, e.Enabled())
+
+		components := e.AllComponents()
+		sort.Slice(components, func(i, j int) bool {
+			return components[i].ComponentType() < components[j].ComponentType()
+		})
+		hashUint(h, uint64(len(components)))
+		for _, c := range components {
+			payload, err := encodeComponent(reg, c)
+			if err != nil {
+				return fmt.Errorf("hash entity %s: component %d: %w", e.Id(), c.ComponentType(), err)
+			}
+			hashUint(h, uint64(c.ComponentType()))
+			hashBool(h, c.Enabled())
+			hashTags(h, c.Tags())
+			hashUint(h, uint64(len(payload)))
+			h.Write(payload)
+		}
+		sum = h.Sum64()
+		return nil
+	}
+	var err error
+	if de, ok := ent.(DataEntity); ok {
+		err = de.Tx(func(tx DataEntity) error { return hashState(tx) })
+	} else {
+		err = hashState(ent)
+	}
+	return sum, tick, err
+}
+
+func encodeComponent(reg *ComponentRegistry, c Component) ([]byte, error) {
+	if m, ok := c.(ComponentMarshaler); ok {
+		return m.Marshal()
+	}
+	return reg.EncodeComponent(c)
+}
+
+func hashUint(h hash.Hash64, v uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	h.Write(buf[:])
+}
+
+func hashBool(h hash.Hash64, v bool) {
+	if v {
+		h.Write([]byte{1})
+	} else {
+		h.Write([]byte{0})
+	}
+}
+
+func hashString(h hash.Hash64, s string) {
+	hashUint(h, uint64(len(s)))
+	h.Write([]byte(s))
+}
+
+func hashTags(h hash.Hash64, tags []Tag) {
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+	hashUint(h, uint64(len(tags)))
+	for _, tag := range tags {
+		hashString(h, string(tag))
+	}
+}
+
+// WorldHasher maintains a hash over every entity of a manager, for checking
+// that two servers, or a server and a replay, hold the same state.
+//
+// Sum only rehashes entities whose StateTick moved since the previous Sum,
+// so after the first call it costs a walk over the entities plus encoding
+// what changed. Entities that are not StateTrackers are rehashed every time.
+// Component fields written without GetForUpdate or MarkChanged are not
+// seen until the entity changes otherwise.
+//
+// Entity hashes are combined by addition, so the world hash does not depend
+// on iteration order. It is safe for concurrent use.
+type WorldHasher[T Entity] struct {
+	entities EntityManager[T]
+	registry *ComponentRegistry
+
+	mu     sync.Mutex
+	sum    uint64
+	epoch  uint64
+	hashes map[string]entityHashEntry
+}
+
+type entityHashEntry struct {
+	ent   Entity
+	hash  uint64
+	tick  Tick
+	epoch uint64
+}
+
+// NewWorldHasher creates a hasher over m. A nil reg uses
+// DefaultComponentRegistry.
+func NewWorldHasher[T Entity](m EntityManager[T], reg *ComponentRegistry) *WorldHasher[T] {
+	return &WorldHasher[T]{
+		entities: m,
+		registry: reg,
+		hashes:   make(map[string]entityHashEntry),
+	}
+}
+
+// Sum brings the world hash up to date and returns it.
+func (h *WorldHasher[T]) Sum(ctx context.Context) (uint64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.epoch++
+	err := h.entities.ForEach(ctx, func(ent T) error {
+		id := ent.Id()
+		entry, ok := h.hashes[id]
+		if ok && entry.ent == Entity(ent) && entry.tick != 0 {
+			if tracker, isTracker := Entity(ent).(StateTracker); isTracker && tracker.StateTick() == entry.tick {
+				entry.epoch = h.epoch
+				h.hashes[id] = entry
+				return nil
+			}
+		}
+		sum, tick, err := hashEntity(ent, h.registry)
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.sum -= entry.hash
+		}
+		h.sum += sum
+		h.hashes[id] = entityHashEntry{ent: ent, hash: sum, tick: tick, epoch: h.epoch}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for id, entry := range h.hashes {
+		if entry.epoch != h.epoch {
+			h.sum -= entry.hash
+			delete(h.hashes, id)
+		}
+	}
+	return h.sum, nil
+}
+
+// EntityHash returns the hash of entity id as of the latest Sum. Comparing
+// entity hashes narrows a mismatched Sum down to the differing entities.
+func (h *WorldHasher[T]) EntityHash(id string) (uint64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entry, ok := h.hashes[id]
+	return entry.hash, ok
+}
+
+// Hashes returns every entity hash as of the latest Sum, by entity id.
+func (h *WorldHasher[T]) Hashes() map[string]uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make(map[string]uint64, len(h.hashes))
+	for id, entry := range h.hashes {
+		out[id] = entry.hash
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHashEntity_StableAndSensitive(t *testing.T) {
+	build := func(tags []Tag, components ...Component) *DataEntityCore {
+		ent := NewDataEntityCore("p1", "Player", 1, tags...)
+		for _, c := range components {
+			_ = ent.Add(c)
+		}
+		return ent
+	}
+	hash := func(ent Entity) uint64 {
+		sum, err := HashEntity(ent, nil)
+		if err != nil {
+			t.Fatalf("hash: %v", err)
+		}
+		return sum
+	}
+
+	a := build([]Tag{"vip", "player"}, newTestLabelComponent("hero"), newTestDataComponent())
+	b := build([]Tag{"player", "vip"}, newTestDataComponent(), newTestLabelComponent("hero"))
+	base := hash(a)
+	if base != hash(b) {
+		t.Fatalf("hash depends on tag or component order")
+	}
+	renamed := NewDataEntityCore("p1", "Other name", 1, "player", "vip")
+	_ = renamed.Add(newTestDataComponent())
+	_ = renamed.Add(newTestLabelComponent("hero"))
+	if hash(renamed) != base {
+		t.Fatalf("hash includes the entity name")
+	}
+
+	label, _ := GetForUpdate[*testLabelComponent](b, testLabelComponentType)
+	label.Label = "villain"
+	if hash(b) == base {
+		t.Fatalf("hash ignores component payload")
+	}
+	c := build([]Tag{"player"}, newTestLabelComponent("hero"), newTestDataComponent())
+	if hash(c) == base {
+		t.Fatalf("hash ignores tags")
+	}
+	a.SetEnabled(false)
+	if hash(a) == base {
+		t.Fatalf("hash ignores enabled flag")
+	}
+}
+
+func TestWorldHasher_Incremental(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	for _, id := range []string{"p1", "p2", "p3"} {
+		ent, _ := m.Create(ctx, id, id, 1)
+		_ = ent.Add(newTestLabelComponent(id))
+	}
+	fresh := func() uint64 {
+		sum, err := NewWorldHasher[DataEntity](m, nil).Sum(ctx)
+		if err != nil {
+			t.Fatalf("sum: %v", err)
+		}
+		return sum
+	}
+
+	h := NewWorldHasher[DataEntity](m, nil)
+	first, err := h.Sum(ctx)
+	if err != nil {
+		t.Fatalf("sum: %v", err)
+	}
+	if first != fresh() || len(h.Hashes()) != 3 {
+		t.Fatalf("sum = %d, fresh = %d, hashes = %v", first, fresh(), h.Hashes())
+	}
+	p2Hash, _ := h.EntityHash("p2")
+	direct, _ := HashEntity(m.MustGet("p2"), nil)
+	if p2Hash != direct {
+		t.Fatalf("entity hash = %d, want %d", p2Hash, direct)
+	}
+
+	// A write the entity did not record is not picked up: p2 is not rehashed.
+	p2, _ := Get[*testLabelComponent](m.MustGet("p2"), testLabelComponentType)
+	p2.Label = "silent"
+	if sum, _ := h.Sum(ctx); sum != first {
+		t.Fatalf("unrecorded write rehashed p2")
+	}
+	m.MustGet("p2").(ChangeTracker).MarkChanged(testLabelComponentType)
+	changed, _ := h.Sum(ctx)
+	if changed == first || changed != fresh() {
+		t.Fatalf("sum after change = %d, fresh = %d", changed, fresh())
+	}
+
+	m.MustGet("p1").AddTag("vip")
+	m.Remove("p3")
+	ent, _ := m.Create(ctx, "p4", "p4", 1)
+	_ = ent.Add(newTestDataComponent())
+	sum, _ := h.Sum(ctx)
+	if sum != fresh() {
+		t.Fatalf("sum = %d, fresh = %d", sum, fresh())
+	}
+	if _, ok := h.EntityHash("p3"); ok {
+		t.Fatalf("removed entity still hashed")
+	}
+}
@@ -0,0 +1,12 @@
+package history
+
+import "errors"
+
+var (
+	// ErrNothingToUndo indicates an empty undo history.
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNothingToRedo indicates an empty redo history.
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrEntityGone indicates a recorded entity that is no longer in the manager.
+	ErrEntityGone = errors.New("entity no longer exists")
+)
@@ -0,0 +1,216 @@
+// Package history records the changes transactions make to entities and
+// undoes and redoes them, for level editors and admin tools.
+//
+// A History is a ginka_ecs_go.TxObserver. Transactions run with
+// ginka_ecs_go.TxContext under the context returned by History.Context are
+// recorded as one step each, holding before and after images of every
+// component they changed plus the entity tags and enabled flag. Undo and
+// Redo write those images back inside a transaction and mark restored
+// components dirty so they are persisted again.
+package history
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// DefaultLimit is the number of steps kept when New is given no limit.
+const DefaultLimit = 100
+
+// History is a bounded undo and redo stack of recorded transactions.
+// It is safe for concurrent use.
+type History[T ginka_ecs_go.DataEntity] struct {
+	entities ginka_ecs_go.EntityManager[T]
+	registry *ginka_ecs_go.ComponentRegistry
+	limit    int
+
+	mu   sync.Mutex
+	undo []*ginka_ecs_go.TxChange
+	redo []*ginka_ecs_go.TxChange
+}
+
+// New creates a History over the entities of m, keeping at most limit steps.
+// A limit of 0 or less uses DefaultLimit. Recorded component types must be
+// registered in reg; a nil reg uses ginka_ecs_go.DefaultComponentRegistry.
+func New[T ginka_ecs_go.DataEntity](m ginka_ecs_go.EntityManager[T], reg *ginka_ecs_go.ComponentRegistry, limit int) *History[T] {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	return &History[T]{entities: m, registry: reg, limit: limit}
+}
+
+// Context returns a context under which TxContext transactions are recorded.
+func (h *History[T]) Context(ctx context.Context) context.Context {
+	return ginka_ecs_go.WithTxObserver(ctx, h.registry, h)
+}
+
+// applyingKey marks the context of a transaction applied by Undo or Redo.
+type applyingKey struct{}
+
+// ObserveTx records change as a new step and clears the redo stack.
+// The oldest step is dropped once the limit is reached.
+func (h *History[T]) ObserveTx(ctx context.Context, change *ginka_ecs_go.TxChange) {
+	if ctx.Value(applyingKey{}) == any(h) {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.undo) == h.limit {
+		copy(h.undo, h.undo[1:])
+		h.undo = h.undo[:len(h.undo)-1]
+	}
+	h.undo = append(h.undo, change)
+	clear(h.redo)
+	h.redo = h.redo[:0]
+}
+
+// Undo restores the state from before the latest recorded step. If it
+// fails, the step stays on the undo stack.
+func (h *History[T]) Undo(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.undo) == 0 {
+		return ErrNothingToUndo
+	}
+	change := h.undo[len(h.undo)-1]
+	if err := h.apply(ctx, change, true); err != nil {
+		return err
+	}
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, change)
+	return nil
+}
+
+// Redo reapplies the latest undone step. If it fails, the step stays on
+// the redo stack.
+func (h *History[T]) Redo(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.redo) == 0 {
+		return ErrNothingToRedo
+	}
+	change := h.redo[len(h.redo)-1]
+	if err := h.apply(ctx, change, false); err != nil {
+		return err
+	}
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, change)
+	return nil
+}
+
+// CanUndo reports whether there is a step to undo.
+func (h *History[T]) CanUndo() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.undo) > 0
+}
+
+// CanRedo reports whether there is a step to redo.
+func (h *History[T]) CanRedo() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.redo) > 0
+}
+
+// Len returns the number of steps that can be undone and redone.
+func (h *History[T]) Len() (undo int, redo int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.undo), len(h.redo)
+}
+
+// Clear forgets every step.
+func (h *History[T]) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.undo = nil
+	h.redo = nil
+}
+
+// apply writes the before images of change if undo is set, or its after
+// images otherwise. Tags the step did not add or remove are kept.
+// Components are replaced rather than decoded in place, keep a version
+// above both the image and the current component, and are marked dirty.
+// Observers on ctx other than h see the transaction.
+func (h *History[T]) apply(ctx context.Context, change *ginka_ecs_go.TxChange, undo bool) error {
+	ent, ok := h.entities.Get(change.EntityId)
+	if !ok {
+		return fmt.Errorf("history: %s: %w", change.EntityId, ErrEntityGone)
+	}
+	targets := make([]ginka_ecs_go.Component, len(change.Components))
+	for i, cc := range change.Components {
+		img := cc.After
+		if undo {
+			img = cc.Before
+		}
+		if img == nil {
+			continue
+		}
+		c, err := img.Decode(change.Registry)
+		if err != nil {
+			return fmt.Errorf("history: %s: component %d: %w", change.EntityId, cc.Type, err)
+		}
+		targets[i] = c
+	}
+
+	ctx = context.WithValue(ctx, applyingKey{}, any(h))
+	return ginka_ecs_go.TxContext(ctx, ent, func(ctx context.Context, tx ginka_ecs_go.DataEntity) error {
+		for i := len(change.Components) - 1; i >= 0; i-- {
+			t := change.Components[i].Type
+			current, exists := tx.Get(t)
+			target := targets[i]
+			if target == nil {
+				tx.RemoveComponent(t)
+				continue
+			}
+			if exists {
+				if dc, ok := target.(ginka_ecs_go.DataComponent); ok {
+					if cur, ok := current.(ginka_ecs_go.DataComponent); ok {
+						dc.SetVersion(max(dc.Version(), cur.Version()))
+					}
+				}
+				tx.RemoveComponent(t)
+			}
+			if err := tx.Add(target); err != nil {
+				return fmt.Errorf("history: %s: component %d: %w", change.EntityId, t, err)
+			}
+			tx.GetForUpdate(t)
+		}
+		if change.TagsChanged {
+			from, to := change.TagsBefore, change.TagsAfter
+			if undo {
+				from, to = to, from
+			}
+			moveTags(tx, from, to)
+		}
+		if change.EnabledChanged {
+			if undo {
+				tx.SetEnabled(change.EnabledBefore)
+			} else {
+				tx.SetEnabled(change.EnabledAfter)
+			}
+		}
+		return nil
+	})
+}
+
+// moveTags removes the tags in from but not in to and adds those in to
+// but not in from, leaving tags the step did not touch alone.
+func moveTags(tx ginka_ecs_go.DataEntity, from []ginka_ecs_go.Tag, to []ginka_ecs_go.Tag) {
+	for _, tag := range from {
+		if !slices.Contains(to, tag) {
+			tx.RemoveTag(tag)
+		}
+	}
+	for _, tag := range to {
+		if !slices.Contains(from, tag) {
+			tx.AddTag(tag)
+		}
+	}
+}
+
+var _ ginka_ecs_go.TxObserver = (*History[ginka_ecs_go.DataEntity])(nil)
@@ -0,0 +1,170 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+	"github.com/Shigure42/ginka-ecs-go/ecstest"
+)
+
+const (
+	testWalletType ginka_ecs_go.ComponentType = 1
+	testBadgeType  ginka_ecs_go.ComponentType = 2
+)
+
+type testWallet struct {
+	ginka_ecs_go.DataComponentCore
+	Gold int64 `json:"gold"`
+}
+
+func (c *testWallet) StorageKey() string {
+	return "wallet"
+}
+
+func newTestWallet(gold int64) *testWallet {
+	return &testWallet{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testWalletType), Gold: gold}
+}
+
+type testBadge struct {
+	ginka_ecs_go.ComponentCore
+	Title string `json:"title"`
+}
+
+func newTestBadge(title string) *testBadge {
+	return &testBadge{ComponentCore: ginka_ecs_go.NewComponentCore(testBadgeType), Title: title}
+}
+
+// newTestWorld builds a world whose player p1 holds 10 gold.
+func newTestWorld(t *testing.T) *ecstest.World {
+	t.Helper()
+	w := ecstest.NewWorld(t).Components(
+		ginka_ecs_go.ComponentSpec{Name: "wallet", Type: testWalletType, New: func() ginka_ecs_go.Component { return newTestWallet(0) }},
+		ginka_ecs_go.ComponentSpec{Name: "badge", Type: testBadgeType, New: func() ginka_ecs_go.Component { return newTestBadge("") }},
+	).Build()
+	ent, err := w.Entities.Create(context.Background(), "p1", "p1", 1, "player")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_ = ent.Add(newTestWallet(10))
+	return w
+}
+
+func edit(t *testing.T, ctx context.Context, ent ginka_ecs_go.DataEntity, fn func(tx ginka_ecs_go.DataEntity)) {
+	t.Helper()
+	if err := ginka_ecs_go.TxContext(ctx, ent, func(_ context.Context, tx ginka_ecs_go.DataEntity) error {
+		fn(tx)
+		return nil
+	}); err != nil {
+		t.Fatalf("tx: %v", err)
+	}
+}
+
+func gold(ent ginka_ecs_go.DataEntity) (int64, uint64) {
+	w, ok := ginka_ecs_go.Get[*testWallet](ent, testWalletType)
+	if !ok {
+		return -1, 0
+	}
+	return w.Gold, w.Version()
+}
+
+func TestHistory_UndoRedo(t *testing.T) {
+	w := newTestWorld(t)
+	h := New[ginka_ecs_go.DataEntity](w.Entities, w.Registry, 0)
+	ctx := h.Context(context.Background())
+	p1 := w.Entity("p1")
+
+	edit(t, ctx, p1, func(tx ginka_ecs_go.DataEntity) {
+		w, _ := ginka_ecs_go.GetForUpdate[*testWallet](tx, testWalletType)
+		w.Gold = 20
+	})
+	edit(t, ctx, p1, func(tx ginka_ecs_go.DataEntity) {
+		tx.AddTag("vip")
+		tx.SetEnabled(false)
+		_ = tx.Add(newTestBadge("founder"))
+	})
+	// Reads and unobserved transactions are not recorded.
+	edit(t, ctx, p1, func(tx ginka_ecs_go.DataEntity) { tx.Get(testWalletType) })
+	_ = p1.Tx(func(tx ginka_ecs_go.DataEntity) error { tx.AddTag("unrecorded"); return nil })
+	if undo, redo := h.Len(); undo != 2 || redo != 0 {
+		t.Fatalf("len = %d, %d", undo, redo)
+	}
+
+	p1.ClearDirty()
+	if err := h.Undo(context.Background()); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if p1.Has(testBadgeType) || p1.HasTag("vip") || !p1.HasTag("unrecorded") || !p1.Enabled() {
+		t.Fatalf("after undo: badge %v tags %v enabled %v", p1.Has(testBadgeType), p1.Tags(), p1.Enabled())
+	}
+	if err := h.Undo(context.Background()); err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if g, v := gold(p1); g != 10 || v != 2 {
+		t.Fatalf("gold = %d version %d, want 10 version 2", g, v)
+	}
+	if dirty := p1.DirtyTypes(); len(dirty) != 1 || dirty[0] != testWalletType {
+		t.Fatalf("dirty = %v", dirty)
+	}
+	if err := h.Undo(context.Background()); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("undo empty err = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := h.Redo(context.Background()); err != nil {
+			t.Fatalf("redo: %v", err)
+		}
+	}
+	if g, v := gold(p1); g != 20 || v != 3 {
+		t.Fatalf("gold = %d version %d, want 20 version 3", g, v)
+	}
+	badge, ok := ginka_ecs_go.Get[*testBadge](p1, testBadgeType)
+	if !ok || badge.Title != "founder" || !p1.HasTag("vip") || p1.Enabled() {
+		t.Fatalf("after redo: badge %v tags %v enabled %v", badge, p1.Tags(), p1.Enabled())
+	}
+	if err := h.Redo(context.Background()); !errors.Is(err, ErrNothingToRedo) {
+		t.Fatalf("redo empty err = %v", err)
+	}
+
+	// A new step clears the redo stack.
+	_ = h.Undo(context.Background())
+	edit(t, ctx, p1, func(tx ginka_ecs_go.DataEntity) { tx.RemoveComponent(testWalletType) })
+	if h.CanRedo() {
+		t.Fatalf("redo stack survived a new step")
+	}
+	_ = h.Undo(context.Background())
+	if g, _ := gold(p1); g != 20 {
+		t.Fatalf("removed wallet not restored: gold = %d", g)
+	}
+}
+
+func TestHistory_LimitAndMissingEntity(t *testing.T) {
+	w := newTestWorld(t)
+	h := New[ginka_ecs_go.DataEntity](w.Entities, w.Registry, 2)
+	ctx := h.Context(context.Background())
+	p1 := w.Entity("p1")
+	for i := int64(1); i <= 3; i++ {
+		edit(t, ctx, p1, func(tx ginka_ecs_go.DataEntity) {
+			w, _ := ginka_ecs_go.GetForUpdate[*testWallet](tx, testWalletType)
+			w.Gold = 100 * i
+		})
+	}
+	if undo, _ := h.Len(); undo != 2 {
+		t.Fatalf("undo len = %d, want 2", undo)
+	}
+	_ = h.Undo(context.Background())
+	_ = h.Undo(context.Background())
+	if g, _ := gold(p1); g != 100 {
+		t.Fatalf("gold = %d, want 100", g)
+	}
+
+	_ = h.Redo(context.Background())
+	w.Entities.Remove("p1")
+	if err := h.Redo(context.Background()); !errors.Is(err, ErrEntityGone) {
+		t.Fatalf("redo err = %v", err)
+	}
+	if undo, redo := h.Len(); undo != 1 || redo != 1 {
+		t.Fatalf("len after failed redo = %d, %d", undo, redo)
+	}
+}
@@ -0,0 +1,101 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Log attribute keys used by the package. Systems should use the same keys
+// so entity and component records can be correlated.
+const (
+	LogKeyWorld         = "world"
+	LogKeyEntityId      = "entity_id"
+	LogKeyEntityType    = "entity_type"
+	LogKeyComponentType = "component_type"
+)
+
+// discardLogger drops every record. It is the default.
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+type loggerHolder struct {
+	l *slog.Logger
+}
+
+var defaultLogger atomic.Pointer[loggerHolder]
+
+// SetDefaultLogger sets the logger used by managers, worlds and persisters
+// without their own. Nil restores the default, which discards everything.
+func SetDefaultLogger(l *slog.Logger) {
+	if l == nil {
+		l = discardLogger
+	}
+	defaultLogger.Store(&loggerHolder{l: l})
+}
+
+// DefaultLogger returns the logger set with SetDefaultLogger.
+func DefaultLogger() *slog.Logger {
+	if h := defaultLogger.Load(); h != nil {
+		return h.l
+	}
+	return discardLogger
+}
+
+// loggerOrDefault returns l, or the default when l is nil.
+func loggerOrDefault(l *slog.Logger) *slog.Logger {
+	if l != nil {
+		return l
+	}
+	return DefaultLogger()
+}
+
+// LogEntity returns the entity_id and entity_type attributes of ent,
+// inlined into the record rather than grouped.
+func LogEntity(ent Entity) slog.Attr {
+	return slog.Attr{Value: entityLogValue(ent)}
+}
+
+// LogComponentType returns the component_type attribute of t, named by reg.
+// Unregistered types are logged as numbers. A nil reg uses
+// DefaultComponentRegistry.
+func LogComponentType(reg *ComponentRegistry, t ComponentType) slog.Attr {
+	return slog.Attr{Key: LogKeyComponentType, Value: componentTypeLogValue(reg, t)}
+}
+
+func entityLogValue(ent Entity) slog.Value {
+	return slog.GroupValue(
+		slog.String(LogKeyEntityId, ent.Id()),
+		slog.Int(LogKeyEntityType, int(ent.Type())),
+	)
+}
+
+func componentTypeLogValue(reg *ComponentRegistry, t ComponentType) slog.Value {
+	if reg == nil {
+		reg = DefaultComponentRegistry
+	}
+	if spec, ok := reg.Lookup(t); ok {
+		return slog.StringValue(spec.Name)
+	}
+	return slog.IntValue(int(t))
+}
+
+// LogValue implements slog.LogValuer. It resolves t to its name in
+// DefaultComponentRegistry.
+func (t ComponentType) LogValue() slog.Value {
+	return componentTypeLogValue(DefaultComponentRegistry, t)
+}
+
+// LogValue implements slog.LogValuer as a group of entity_id and entity_type.
+func (e *EntityCore) LogValue() slog.Value {
+	return entityLogValue(e)
+}
+
+var _ slog.LogValuer = ComponentType(0)
+var _ slog.LogValuer = (*EntityCore)(nil)
@@ -0,0 +1,134 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// logLines decodes the JSON records written to buf.
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func newJSONLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// bumpingStore updates the entity while its component is being saved.
+type bumpingStore struct {
+	*memoryPatchStore
+	ent DataEntity
+}
+
+func (s *bumpingStore) SaveComponent(ctx context.Context, rec ComponentRecord) error {
+	s.ent.GetForUpdate(rec.Type)
+	return s.memoryPatchStore.SaveComponent(ctx, rec)
+}
+
+func TestLogValue_EntityAndComponentType(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newJSONLogger(&buf)
+	ent := NewDataEntityCore("p1", "p1", 7)
+	logger.Info("inline", LogEntity(ent), slog.Any(LogKeyComponentType, ComponentType(-12345)))
+	logger.Info("grouped", slog.Any("entity", ent))
+
+	lines := logLines(t, &buf)
+	if lines[0][LogKeyEntityId] != "p1" || lines[0][LogKeyEntityType] != float64(7) || lines[0][LogKeyComponentType] != float64(-12345) {
+		t.Fatalf("inline record = %v", lines[0])
+	}
+	group, _ := lines[1]["entity"].(map[string]any)
+	if group[LogKeyEntityId] != "p1" || group[LogKeyEntityType] != float64(7) {
+		t.Fatalf("grouped record = %v", lines[1])
+	}
+
+	reg := NewComponentRegistry()
+	if err := reg.Register(ComponentSpec{Name: "test", Type: testDataComponentType, New: func() Component { return newTestDataComponent() }}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if got := LogComponentType(reg, testDataComponentType).Value.String(); got != "test" {
+		t.Fatalf("component type = %s", got)
+	}
+}
+
+func TestLogger_LifecycleAndFlush(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newJSONLogger(&buf)
+
+	w := NewCoreWorld("arena")
+	w.SetLogger(logger)
+	go func() { _ = w.Run() }()
+	waitForRunning(t, w)
+	_ = w.Stop()
+
+	m := newTestDataEntityManager()
+	m.SetLogger(logger)
+	ctx := context.Background()
+	ent, err := m.Create(ctx, "p1", "p1", 3)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_ = ent.Add(newTestDataComponent())
+	ent.GetForUpdate(testDataComponentType)
+	m.Remove("p1")
+
+	reg := NewComponentRegistry()
+	if err := reg.Register(ComponentSpec{Name: "test", Type: testDataComponentType, New: func() Component { return newTestDataComponent() }}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	store := &bumpingStore{memoryPatchStore: newMemoryPatchStore(), ent: ent}
+	p := NewPersister(store)
+	p.SetRegistry(reg)
+	p.SetLogger(logger)
+	if _, err := p.FlushEntity(ctx, ent); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	store.fail = errors.New("disk full")
+	if _, err := p.FlushEntity(ctx, ent); err == nil {
+		t.Fatalf("expected flush error")
+	}
+
+	var msgs []string
+	for _, rec := range logLines(t, &buf) {
+		msg := rec["msg"].(string)
+		msgs = append(msgs, msg)
+		switch msg {
+		case "world running", "world stopping", "world stopped":
+			if rec[LogKeyWorld] != "arena" {
+				t.Fatalf("%s: %v", msg, rec)
+			}
+		case "entity added", "entity removed":
+			if rec[LogKeyEntityId] != "p1" || rec[LogKeyEntityType] != float64(3) || rec["level"] != "DEBUG" {
+				t.Fatalf("%s: %v", msg, rec)
+			}
+		case "component changed during flush; left dirty":
+			if rec[LogKeyEntityId] != "p1" || rec[LogKeyComponentType] != "test" || rec["written_version"] != float64(1) || rec["current_version"] != float64(2) {
+				t.Fatalf("%s: %v", msg, rec)
+			}
+		case "flush entity failed":
+			if rec[LogKeyEntityId] != "p1" || rec["level"] != "ERROR" || !strings.Contains(rec["error"].(string), "disk full") {
+				t.Fatalf("%s: %v", msg, rec)
+			}
+		}
+	}
+	want := []string{"world running", "world stopping", "world stopped", "entity added", "entity removed", "component changed during flush; left dirty", "flush entity failed"}
+	if strings.Join(msgs, "|") != strings.Join(want, "|") {
+		t.Fatalf("messages = %q", msgs)
+	}
+}
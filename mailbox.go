@@ -0,0 +1,293 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+)
+
+// DefaultMailboxSize is the number of commands an entity mailbox queues
+// when MailboxConfig leaves it unset.
+const DefaultMailboxSize = 64
+
+// MailboxConfig configures Mailboxes.
+type MailboxConfig struct {
+	// Workers is the number of worker goroutines, rounded up to a power of
+	// two. Zero uses GOMAXPROCS.
+	Workers int
+	// MailboxSize bounds the commands queued per entity, including the one
+	// running. Zero uses DefaultMailboxSize.
+	MailboxSize int
+}
+
+// Mailboxes is an execution mode in which every command for an entity runs
+// serially on one worker, so request goroutines no longer contend on the
+// entity lock. Entities are assigned to workers by id the way
+// MapEntityManager assigns them to shards. Each entity has its own bounded
+// mailbox; a worker takes one command from each ready mailbox in turn so a
+// busy entity does not starve the others on its worker.
+//
+// Commands are accepted or refused immediately: a full mailbox fails with
+// ErrMailboxFull, which callers should treat as back-pressure.
+// It is safe for concurrent use.
+type Mailboxes[T Entity] struct {
+	entities    EntityManager[T]
+	mailboxSize int
+	workers     []mailboxWorker[T]
+	workerMask  uint64
+	wg          sync.WaitGroup
+
+	logMu  sync.Mutex
+	logger *slog.Logger
+}
+
+type mailboxWorker[T Entity] struct {
+	mu     sync.Mutex
+	wake   *sync.Cond
+	boxes  map[string]*mailbox[T]
+	ready  []*mailbox[T]
+	closed bool
+}
+
+type mailbox[T Entity] struct {
+	id    string
+	queue []mailboxCommand[T]
+}
+
+type mailboxCommand[T Entity] struct {
+	ctx context.Context
+	run func(ctx context.Context, ent T) error
+	// done receives the result of Ask commands; Tell commands have none.
+	done chan error
+}
+
+// NewMailboxes starts the workers running commands against the entities of
+// m. Close stops them.
+func NewMailboxes[T Entity](m EntityManager[T], cfg MailboxConfig) *Mailboxes[T] {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	count := nextPow2(uint64(workers))
+	size := cfg.MailboxSize
+	if size <= 0 {
+		size = DefaultMailboxSize
+	}
+	mb := &Mailboxes[T]{
+		entities:    m,
+		mailboxSize: size,
+		workers:     make([]mailboxWorker[T], count),
+		workerMask:  count - 1,
+	}
+	for i := range mb.workers {
+		w := &mb.workers[i]
+		w.wake = sync.NewCond(&w.mu)
+		w.boxes = make(map[string]*mailbox[T])
+		mb.wg.Add(1)
+		go mb.work(w)
+	}
+	return mb
+}
+
+// SetLogger sets the logger failed Tell commands are reported to.
+// Nil uses DefaultLogger.
+func (mb *Mailboxes[T]) SetLogger(l *slog.Logger) {
+	mb.logMu.Lock()
+	defer mb.logMu.Unlock()
+	mb.logger = l
+}
+
+func (mb *Mailboxes[T]) currentLogger() *slog.Logger {
+	mb.logMu.Lock()
+	defer mb.logMu.Unlock()
+	return loggerOrDefault(mb.logger)
+}
+
+// Ask runs fn on entity id's mailbox and waits for its result. fn receives
+// ctx; if ctx is done before fn starts, fn is skipped. Ask returns
+// ErrMailboxFull when the mailbox is full, ErrEntityNotFound when m has no
+// such entity by the time fn would run, and ctx.Err() if ctx ends first.
+//
+// A command waiting on a worker that is busy running it, or running a
+// command further up its chain of Asks, would never return. Ask detects
+// that through the ctx handed to commands and fails with
+// ErrMailboxReentrant; use Tell instead. Commands of different chains
+// asking each other's workers can still deadlock, so give such Asks a
+// deadline.
+func Ask[T Entity, R any](ctx context.Context, mb *Mailboxes[T], id string, fn func(ctx context.Context, ent T) (R, error)) (R, error) {
+	var result R
+	if mb.holds(ctx, mb.workerIndex(id)) {
+		return result, fmt.Errorf("mailbox %s: %w", id, ErrMailboxReentrant)
+	}
+	done := make(chan error, 1)
+	err := mb.send(id, mailboxCommand[T]{
+		ctx: ctx,
+		run: func(ctx context.Context, ent T) error {
+			r, err := fn(ctx, ent)
+			result = r
+			return err
+		},
+		done: done,
+	})
+	if err != nil {
+		var zero R
+		return zero, err
+	}
+	select {
+	case err := <-done:
+		return result, err
+	case <-ctx.Done():
+		var zero R
+		return zero, ctx.Err()
+	}
+}
+
+// Tell queues fn on entity id's mailbox without waiting. Its error, if
+// any, is logged. Tell fails with ErrMailboxFull when the mailbox is full.
+func (mb *Mailboxes[T]) Tell(ctx context.Context, id string, fn func(ctx context.Context, ent T) error) error {
+	return mb.send(id, mailboxCommand[T]{ctx: ctx, run: fn})
+}
+
+// Len returns the number of commands queued or running for entity id.
+func (mb *Mailboxes[T]) Len(id string) int {
+	w := mb.worker(id)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if box, ok := w.boxes[id]; ok {
+		return len(box.queue)
+	}
+	return 0
+}
+
+// Close stops accepting commands, runs the ones already queued and waits
+// for the workers to exit.
+func (mb *Mailboxes[T]) Close() {
+	for i := range mb.workers {
+		w := &mb.workers[i]
+		w.mu.Lock()
+		w.closed = true
+		w.wake.Broadcast()
+		w.mu.Unlock()
+	}
+	mb.wg.Wait()
+}
+
+func (mb *Mailboxes[T]) worker(id string) *mailboxWorker[T] {
+	return &mb.workers[mb.workerIndex(id)]
+}
+
+func (mb *Mailboxes[T]) workerIndex(id string) uint64 {
+	return hashEntityId(id) & mb.workerMask
+}
+
+type mailboxHoldsKey struct{}
+
+// mailboxHold is a worker blocked running a command of the current chain.
+type mailboxHold struct {
+	mailboxes any
+	worker    uint64
+}
+
+// holds reports whether ctx comes from a command chain already running on
+// the given worker.
+func (mb *Mailboxes[T]) holds(ctx context.Context, worker uint64) bool {
+	held, _ := ctx.Value(mailboxHoldsKey{}).([]mailboxHold)
+	for _, h := range held {
+		if h.mailboxes == any(mb) && h.worker == worker {
+			return true
+		}
+	}
+	return false
+}
+
+// withHold returns ctx for a command running on worker.
+func (mb *Mailboxes[T]) withHold(ctx context.Context, worker uint64) context.Context {
+	held, _ := ctx.Value(mailboxHoldsKey{}).([]mailboxHold)
+	next := make([]mailboxHold, len(held), len(held)+1)
+	copy(next, held)
+	next = append(next, mailboxHold{mailboxes: mb, worker: worker})
+	return context.WithValue(ctx, mailboxHoldsKey{}, next)
+}
+
+func (mb *Mailboxes[T]) send(id string, cmd mailboxCommand[T]) error {
+	w := mb.worker(id)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return fmt.Errorf("mailbox %s: %w", id, ErrMailboxClosed)
+	}
+	box, ok := w.boxes[id]
+	if !ok {
+		box = &mailbox[T]{id: id}
+		w.boxes[id] = box
+	}
+	if len(box.queue) >= mb.mailboxSize {
+		return fmt.Errorf("mailbox %s: %w", id, ErrMailboxFull)
+	}
+	box.queue = append(box.queue, cmd)
+	if len(box.queue) == 1 {
+		w.ready = append(w.ready, box)
+		w.wake.Signal()
+	}
+	return nil
+}
+
+// work runs commands of w's ready mailboxes in turn. A mailbox stays in the
+// worker's map, and counts its running command, until that command ends, so
+// its size bound covers the command in progress.
+func (mb *Mailboxes[T]) work(w *mailboxWorker[T]) {
+	defer mb.wg.Done()
+	for {
+		w.mu.Lock()
+		for len(w.ready) == 0 && !w.closed {
+			w.wake.Wait()
+		}
+		if len(w.ready) == 0 {
+			w.mu.Unlock()
+			return
+		}
+		box := w.ready[0]
+		w.ready[0] = nil
+		w.ready = w.ready[1:]
+		cmd := box.queue[0]
+		w.mu.Unlock()
+
+		err := mb.run(box.id, cmd)
+		if cmd.done != nil {
+			cmd.done <- err
+		} else if err != nil {
+			mb.currentLogger().LogAttrs(cmd.ctx, slog.LevelError, "mailbox command failed",
+				slog.String(LogKeyEntityId, box.id), slog.Any("error", err))
+		}
+
+		w.mu.Lock()
+		box.queue[0] = mailboxCommand[T]{}
+		box.queue = box.queue[1:]
+		if len(box.queue) > 0 {
+			w.ready = append(w.ready, box)
+		} else {
+			delete(w.boxes, box.id)
+		}
+		w.mu.Unlock()
+	}
+}
+
+// run executes cmd against entity id, turning a panic into an error so the
+// worker survives it.
+func (mb *Mailboxes[T]) run(id string, cmd mailboxCommand[T]) (err error) {
+	if err := cmd.ctx.Err(); err != nil {
+		return err
+	}
+	ent, ok := mb.entities.Get(id)
+	if !ok {
+		return fmt.Errorf("mailbox %s: %w", id, ErrEntityNotFound)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("mailbox %s: panic: %v", id, r)
+		}
+	}()
+	return cmd.run(mb.withHold(cmd.ctx, mb.workerIndex(id)), ent)
+}
@@ -0,0 +1,178 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMailboxes_AskRunsSerially(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	if _, err := m.Create(ctx, "p1", "p1", 1); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	mb := NewMailboxes[DataEntity](m, MailboxConfig{Workers: 3, MailboxSize: 200})
+	defer mb.Close()
+
+	var running, overlaps, count int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Ask(ctx, mb, "p1", func(ctx context.Context, ent DataEntity) (int, error) {
+				mu.Lock()
+				running++
+				if running > 1 {
+					overlaps++
+				}
+				mu.Unlock()
+				// Unsynchronized on purpose: the race detector flags it if
+				// commands for one entity ever run concurrently.
+				count++
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return count, nil
+			})
+			if err != nil {
+				t.Errorf("ask: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if overlaps != 0 || count != 100 {
+		t.Fatalf("overlaps = %d, count = %d", overlaps, count)
+	}
+
+	got, err := Ask(ctx, mb, "p1", func(ctx context.Context, ent DataEntity) (string, error) {
+		return ent.Id(), nil
+	})
+	if err != nil || got != "p1" {
+		t.Fatalf("ask = %q, %v", got, err)
+	}
+	if _, err := Ask(ctx, mb, "ghost", func(ctx context.Context, ent DataEntity) (int, error) {
+		return 0, nil
+	}); !errors.Is(err, ErrEntityNotFound) {
+		t.Fatalf("missing entity err = %v", err)
+	}
+	if _, err := Ask(ctx, mb, "p1", func(ctx context.Context, ent DataEntity) (int, error) {
+		panic("boom")
+	}); err == nil {
+		t.Fatalf("panic was not reported")
+	}
+	if mb.Len("p1") != 0 {
+		t.Fatalf("len = %d after drain", mb.Len("p1"))
+	}
+}
+
+func TestMailboxes_BackPressureAndClose(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	for _, id := range []string{"p1", "p2", "p3"} {
+		if _, err := m.Create(ctx, id, id, 1); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	mb := NewMailboxes[DataEntity](m, MailboxConfig{Workers: 1, MailboxSize: 2})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	if err := mb.Tell(ctx, "p1", func(ctx context.Context, ent DataEntity) error {
+		close(started)
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatalf("tell: %v", err)
+	}
+	<-started
+	var ran []string
+	record := func(ctx context.Context, ent DataEntity) error {
+		ran = append(ran, ent.Id())
+		return nil
+	}
+	if err := mb.Tell(ctx, "p1", record); err != nil {
+		t.Fatalf("tell: %v", err)
+	}
+	if err := mb.Tell(ctx, "p1", record); !errors.Is(err, ErrMailboxFull) {
+		t.Fatalf("full mailbox err = %v", err)
+	}
+	// Other entities keep their own bound even on the same worker.
+	if err := mb.Tell(ctx, "p2", record); err != nil {
+		t.Fatalf("tell p2: %v", err)
+	}
+	if mb.Len("p1") != 2 {
+		t.Fatalf("len = %d", mb.Len("p1"))
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := mb.Tell(cancelled, "p2", record); err != nil {
+		t.Fatalf("tell cancelled: %v", err)
+	}
+	timeout, stop := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer stop()
+	if _, err := Ask(timeout, mb, "p3", func(ctx context.Context, ent DataEntity) (int, error) {
+		return 0, nil
+	}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ask while blocked err = %v", err)
+	}
+
+	close(release)
+	mb.Close()
+	if len(ran) != 2 || ran[0] != "p2" || ran[1] != "p1" {
+		t.Fatalf("ran = %v", ran)
+	}
+	if err := mb.Tell(ctx, "p1", record); !errors.Is(err, ErrMailboxClosed) {
+		t.Fatalf("closed err = %v", err)
+	}
+}
+
+func TestMailboxes_AskFromCommandDetectsOwnWorker(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	mb := NewMailboxes[DataEntity](m, MailboxConfig{Workers: 4})
+	defer mb.Close()
+
+	ids := []string{"p1"}
+	for i := 2; len(ids) < 2; i++ {
+		id := fmt.Sprintf("p%d", i)
+		if mb.workerIndex(id) != mb.workerIndex(ids[0]) {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		if _, err := m.Create(ctx, id, id, 1); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	self := func(ctx context.Context, ent DataEntity) (string, error) {
+		return Ask(ctx, mb, ent.Id(), func(ctx context.Context, ent DataEntity) (string, error) {
+			return ent.Id(), nil
+		})
+	}
+	if _, err := Ask(ctx, mb, ids[0], self); !errors.Is(err, ErrMailboxReentrant) {
+		t.Fatalf("ask own mailbox err = %v", err)
+	}
+
+	// Asking another worker works, but not asking back down the chain.
+	got, err := Ask(ctx, mb, ids[0], func(ctx context.Context, ent DataEntity) (string, error) {
+		return Ask(ctx, mb, ids[1], func(ctx context.Context, other DataEntity) (string, error) {
+			_, err := Ask(ctx, mb, ids[0], func(ctx context.Context, ent DataEntity) (string, error) {
+				return "", nil
+			})
+			if !errors.Is(err, ErrMailboxReentrant) {
+				return "", fmt.Errorf("ask back err = %v", err)
+			}
+			return other.Id(), nil
+		})
+	})
+	if err != nil || got != ids[1] {
+		t.Fatalf("chained ask = %q, %v", got, err)
+	}
+}
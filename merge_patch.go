@@ -0,0 +1,105 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// CreateMergePatch returns an RFC 7396 JSON merge patch that turns original into modified.
+// Objects are diffed recursively; arrays and scalars are replaced wholesale.
+func CreateMergePatch(original []byte, modified []byte) ([]byte, error) {
+	orig, err := decodeJSONValue(original)
+	if err != nil {
+		return nil, fmt.Errorf("create merge patch: original: %w", err)
+	}
+	mod, err := decodeJSONValue(modified)
+	if err != nil {
+		return nil, fmt.Errorf("create merge patch: modified: %w", err)
+	}
+	origObj, ok1 := orig.(map[string]any)
+	modObj, ok2 := mod.(map[string]any)
+	if !ok1 || !ok2 {
+		return json.Marshal(mod)
+	}
+	return json.Marshal(diffJSONObjects(origObj, modObj))
+}
+
+// ApplyMergePatch applies an RFC 7396 JSON merge patch to doc.
+func ApplyMergePatch(doc []byte, patch []byte) ([]byte, error) {
+	p, err := decodeJSONValue(patch)
+	if err != nil {
+		return nil, fmt.Errorf("apply merge patch: patch: %w", err)
+	}
+	var target any
+	if len(bytes.TrimSpace(doc)) > 0 {
+		target, err = decodeJSONValue(doc)
+		if err != nil {
+			return nil, fmt.Errorf("apply merge patch: doc: %w", err)
+		}
+	}
+	return json.Marshal(mergeJSONPatch(target, p))
+}
+
+// IsEmptyMergePatch reports whether patch changes nothing.
+func IsEmptyMergePatch(patch []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(patch), []byte("{}"))
+}
+
+func diffJSONObjects(orig map[string]any, mod map[string]any) map[string]any {
+	patch := make(map[string]any)
+	for k, mv := range mod {
+		ov, exists := orig[k]
+		if !exists {
+			patch[k] = mv
+			continue
+		}
+		oObj, ok1 := ov.(map[string]any)
+		mObj, ok2 := mv.(map[string]any)
+		if ok1 && ok2 {
+			if sub := diffJSONObjects(oObj, mObj); len(sub) > 0 {
+				patch[k] = sub
+			}
+			continue
+		}
+		if !reflect.DeepEqual(ov, mv) {
+			patch[k] = mv
+		}
+	}
+	for k := range orig {
+		if _, exists := mod[k]; !exists {
+			patch[k] = nil
+		}
+	}
+	return patch
+}
+
+func mergeJSONPatch(target any, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any)
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = mergeJSONPatch(targetObj[k], v)
+	}
+	return targetObj
+}
+
+func decodeJSONValue(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
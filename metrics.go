@@ -0,0 +1,82 @@
+package ginka_ecs_go
+
+import "sync/atomic"
+
+// Metrics receives instrumentation from entity managers, entities, worlds and
+// the persistence path. Labels are name/value pairs ("shard", "3").
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// AddCounter adds delta to a monotonically increasing counter.
+	AddCounter(name string, delta float64, labels ...string)
+	// SetGauge sets a gauge to value.
+	SetGauge(name string, value float64, labels ...string)
+	// ObserveHistogram records one observation.
+	ObserveHistogram(name string, value float64, labels ...string)
+}
+
+// Metric names reported by the package.
+const (
+	// MetricEntities is a gauge of registered entities, labelled by shard.
+	MetricEntities = "ginka_entities"
+	// MetricTxWaitSeconds is a histogram of time spent waiting for the entity lock in Tx.
+	MetricTxWaitSeconds = "ginka_tx_wait_seconds"
+	// MetricTxHoldSeconds is a histogram of time the entity lock is held by Tx.
+	MetricTxHoldSeconds = "ginka_tx_hold_seconds"
+	// MetricDirtyComponents is a gauge of dirty components found by the last FlushAll.
+	MetricDirtyComponents = "ginka_dirty_components"
+	// MetricFlushSeconds is a histogram of FlushAll durations.
+	MetricFlushSeconds = "ginka_flush_duration_seconds"
+	// MetricFlushErrors counts failed entity flushes.
+	MetricFlushErrors = "ginka_flush_errors_total"
+	// MetricFlushedComponents counts written components, labelled by mode (full or patch).
+	MetricFlushedComponents = "ginka_flushed_components_total"
+	// MetricFlushedBytes counts written payload bytes.
+	MetricFlushedBytes = "ginka_flushed_bytes_total"
+	// MetricWorldRunning is a gauge that is 1 while a world runs, labelled by world.
+	MetricWorldRunning = "ginka_world_running"
+)
+
+// NopMetrics discards everything. It is the default.
+var NopMetrics Metrics = nopMetrics{}
+
+type nopMetrics struct{}
+
+func (nopMetrics) AddCounter(string, float64, ...string)       {}
+func (nopMetrics) SetGauge(string, float64, ...string)         {}
+func (nopMetrics) ObserveHistogram(string, float64, ...string) {}
+
+type metricsHolder struct {
+	m Metrics
+}
+
+var defaultMetrics atomic.Pointer[metricsHolder]
+
+// SetDefaultMetrics sets the Metrics used by entities and by managers,
+// worlds and persisters without their own. Nil restores NopMetrics.
+func SetDefaultMetrics(m Metrics) {
+	if m == nil {
+		m = NopMetrics
+	}
+	defaultMetrics.Store(&metricsHolder{m: m})
+}
+
+// DefaultMetrics returns the Metrics set with SetDefaultMetrics.
+func DefaultMetrics() Metrics {
+	if h := defaultMetrics.Load(); h != nil {
+		return h.m
+	}
+	return NopMetrics
+}
+
+// metricsOrDefault returns m, or the default when m is nil.
+func metricsOrDefault(m Metrics) Metrics {
+	if m != nil {
+		return m
+	}
+	return DefaultMetrics()
+}
+
+func isNopMetrics(m Metrics) bool {
+	_, ok := m.(nopMetrics)
+	return ok
+}
@@ -0,0 +1,292 @@
+// Package metrics collects ginka_ecs_go instrumentation in memory and
+// exposes it in the Prometheus text exposition format, without depending on
+// the Prometheus client library.
+//
+//	reg := metrics.NewRegistry()
+//	ginka_ecs_go.SetDefaultMetrics(reg)
+//	http.Handle("/metrics", reg)
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// DefaultBuckets are the histogram upper bounds in seconds used when a
+// histogram has no buckets of its own. They span 10µs to 10s.
+var DefaultBuckets = []float64{0.00001, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+
+type kind int
+
+const (
+	kindCounter kind = iota + 1
+	kindGauge
+	kindHistogram
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindCounter:
+		return "counter"
+	case kindGauge:
+		return "gauge"
+	default:
+		return "histogram"
+	}
+}
+
+// Registry is an in-memory ginka_ecs_go.Metrics that serves its values as
+// Prometheus text over HTTP. A name keeps the kind it was first used with;
+// observations of another kind under the same name are dropped.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+	help     map[string]string
+	buckets  map[string][]float64
+}
+
+type family struct {
+	kind    kind
+	buckets []float64
+	series  map[string]*series
+}
+
+type series struct {
+	labels []string
+	value  float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewRegistry creates an empty Registry with HELP texts for the metrics
+// reported by ginka_ecs_go.
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]*family),
+		help: map[string]string{
+			ginka_ecs_go.MetricEntities:          "Registered entities per entity manager shard.",
+			ginka_ecs_go.MetricTxWaitSeconds:     "Time spent waiting for the entity lock in Tx.",
+			ginka_ecs_go.MetricTxHoldSeconds:     "Time the entity lock was held by Tx.",
+			ginka_ecs_go.MetricDirtyComponents:   "Dirty components found by the last FlushAll.",
+			ginka_ecs_go.MetricFlushSeconds:      "Duration of FlushAll.",
+			ginka_ecs_go.MetricFlushErrors:       "Failed entity flushes.",
+			ginka_ecs_go.MetricFlushedComponents: "Components written by the persister.",
+			ginka_ecs_go.MetricFlushedBytes:      "Payload bytes written by the persister.",
+			ginka_ecs_go.MetricWorldRunning:      "Whether a world is running.",
+		},
+		buckets: make(map[string][]float64),
+	}
+}
+
+// SetHelp sets the HELP text of name.
+func (r *Registry) SetHelp(name string, help string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.help[name] = help
+}
+
+// SetBuckets sets the histogram upper bounds of name. It must be called
+// before the first observation; later calls have no effect on existing series.
+func (r *Registry) SetBuckets(name string, buckets []float64) {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buckets[name] = sorted
+}
+
+// AddCounter implements ginka_ecs_go.Metrics. Negative deltas are ignored.
+func (r *Registry) AddCounter(name string, delta float64, labels ...string) {
+	if delta < 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s := r.seriesLocked(name, kindCounter, labels); s != nil {
+		s.value += delta
+	}
+}
+
+// SetGauge implements ginka_ecs_go.Metrics.
+func (r *Registry) SetGauge(name string, value float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s := r.seriesLocked(name, kindGauge, labels); s != nil {
+		s.value = value
+	}
+}
+
+// ObserveHistogram implements ginka_ecs_go.Metrics.
+func (r *Registry) ObserveHistogram(name string, value float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.seriesLocked(name, kindHistogram, labels)
+	if s == nil {
+		return
+	}
+	buckets := r.families[name].buckets
+	if i := sort.SearchFloat64s(buckets, value); i < len(buckets) {
+		s.counts[i]++
+	}
+	s.sum += value
+	s.count++
+}
+
+// seriesLocked returns the series of name and labels, creating it if needed.
+// It returns nil if name is registered with another kind.
+func (r *Registry) seriesLocked(name string, k kind, labels []string) *series {
+	f := r.families[name]
+	if f == nil {
+		f = &family{kind: k, series: make(map[string]*series)}
+		if k == kindHistogram {
+			f.buckets = r.buckets[name]
+			if f.buckets == nil {
+				f.buckets = DefaultBuckets
+			}
+		}
+		r.families[name] = f
+	}
+	if f.kind != k {
+		return nil
+	}
+	pairs := normalizeLabels(labels)
+	key := strings.Join(pairs, "\x00")
+	s := f.series[key]
+	if s == nil {
+		s = &series{labels: pairs}
+		if k == kindHistogram {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// normalizeLabels drops a trailing unpaired name and sorts pairs by name.
+func normalizeLabels(labels []string) []string {
+	n := len(labels) / 2
+	if n == 0 {
+		return nil
+	}
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i * 2
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return labels[idx[a]] < labels[idx[b]] })
+	out := make([]string, 0, n*2)
+	for _, i := range idx {
+		out = append(out, labels[i], labels[i+1])
+	}
+	return out
+}
+
+// WriteText writes every metric in the Prometheus text exposition format,
+// sorted by name and labels.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		f := r.families[name]
+		if help, ok := r.help[name]; ok {
+			fmt.Fprintf(bw, "# HELP %s %s\n", name, escapeHelp(help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, f.kind)
+
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s := f.series[key]
+			if f.kind != kindHistogram {
+				fmt.Fprintf(bw, "%s%s %s\n", name, formatLabels(s.labels), formatValue(s.value))
+				continue
+			}
+			var cumulative uint64
+			for i, bound := range f.buckets {
+				cumulative += s.counts[i]
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", name, formatLabels(append(s.labels[:len(s.labels):len(s.labels)], "le", formatValue(bound))), cumulative)
+			}
+			fmt.Fprintf(bw, "%s_bucket%s %d\n", name, formatLabels(append(s.labels[:len(s.labels):len(s.labels)], "le", "+Inf")), s.count)
+			fmt.Fprintf(bw, "%s_sum%s %s\n", name, formatLabels(s.labels), formatValue(s.sum))
+			fmt.Fprintf(bw, "%s_count%s %d\n", name, formatLabels(s.labels), s.count)
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP implements http.Handler by writing the text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WriteText(w)
+}
+
+func formatLabels(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func escapeHelp(v string) string {
+	return helpEscaper.Replace(v)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var _ ginka_ecs_go.Metrics = (*Registry)(nil)
+var _ http.Handler = (*Registry)(nil)
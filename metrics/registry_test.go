@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+func TestRegistry_WritesPrometheusText(t *testing.T) {
+	reg := NewRegistry()
+	reg.SetHelp("jobs_total", "Jobs done.\nPer queue.")
+	reg.SetBuckets("latency_seconds", []float64{1, 0.1})
+
+	reg.AddCounter("jobs_total", 2, "queue", `a"b`)
+	reg.AddCounter("jobs_total", 1, "queue", `a"b`)
+	reg.AddCounter("jobs_total", -5, "queue", `a"b`)
+	reg.SetGauge("depth", 7, "z", "1", "a", "2")
+	reg.SetGauge("depth", 3) // a second series without labels
+	reg.AddCounter("depth", 1)
+	reg.ObserveHistogram("latency_seconds", 0.05)
+	reg.ObserveHistogram("latency_seconds", 0.1)
+	reg.ObserveHistogram("latency_seconds", 30)
+
+	srv := httptest.NewServer(reg)
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("content type = %q", ct)
+	}
+
+	expected := strings.Join([]string{
+		`# TYPE depth gauge`,
+		`depth 3`,
+		`depth{a="2",z="1"} 7`,
+		`# HELP jobs_total Jobs done.\nPer queue.`,
+		`# TYPE jobs_total counter`,
+		`jobs_total{queue="a\"b"} 3`,
+		`# TYPE latency_seconds histogram`,
+		`latency_seconds_bucket{le="0.1"} 2`,
+		`latency_seconds_bucket{le="1"} 2`,
+		`latency_seconds_bucket{le="+Inf"} 3`,
+		`latency_seconds_sum 30.15`,
+		`latency_seconds_count 3`,
+	}, "\n") + "\n"
+	if string(body) != expected {
+		t.Fatalf("exposition:\n%s\nwant:\n%s", body, expected)
+	}
+}
+
+func TestRegistry_CollectsLibraryMetrics(t *testing.T) {
+	reg := NewRegistry()
+	ginka_ecs_go.SetDefaultMetrics(reg)
+	t.Cleanup(func() { ginka_ecs_go.SetDefaultMetrics(nil) })
+
+	m := ginka_ecs_go.NewEntityManager(func(id string, name string, typ ginka_ecs_go.EntityType, tags ...ginka_ecs_go.Tag) (ginka_ecs_go.DataEntity, error) {
+		return ginka_ecs_go.NewDataEntityCore(id, name, typ, tags...), nil
+	}, 2)
+	ent, _ := m.Create(context.Background(), "e1", "e1", 1)
+	_ = ent.Tx(func(tx ginka_ecs_go.DataEntity) error { return nil })
+
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, line := range []string{
+		`# HELP ginka_entities Registered entities per entity manager shard.`,
+		`ginka_entities{shard="`,
+		`ginka_tx_hold_seconds_count 1`,
+		`ginka_tx_wait_seconds_count 1`,
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Fatalf("missing %q in\n%s", line, b.String())
+		}
+	}
+}
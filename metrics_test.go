@@ -0,0 +1,118 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingMetrics keeps the last gauge values, counter sums and
+// histogram observation counts keyed by name and labels.
+type recordingMetrics struct {
+	mu         sync.Mutex
+	counters   map[string]float64
+	gauges     map[string]float64
+	histograms map[string]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{counters: make(map[string]float64), gauges: make(map[string]float64), histograms: make(map[string]int)}
+}
+
+func metricKey(name string, labels []string) string {
+	return strings.Join(append([]string{name}, labels...), ",")
+}
+
+func (r *recordingMetrics) AddCounter(name string, delta float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[metricKey(name, labels)] += delta
+}
+
+func (r *recordingMetrics) SetGauge(name string, value float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[metricKey(name, labels)] = value
+}
+
+func (r *recordingMetrics) ObserveHistogram(name string, value float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.histograms[metricKey(name, labels)]++
+}
+
+func TestMetrics_ManagerAndWorld(t *testing.T) {
+	rec := newRecordingMetrics()
+	m := NewEntityManager(func(id string, name string, typ EntityType, tags ...Tag) (DataEntity, error) {
+		return NewDataEntityCore(id, name, typ, tags...), nil
+	}, 1)
+	m.SetMetrics(rec)
+	ctx := context.Background()
+	_, _ = m.Create(ctx, "a", "a", 1)
+	_, _ = m.Create(ctx, "b", "b", 1)
+	m.Remove("a")
+	if got := rec.gauges[metricKey(MetricEntities, []string{"shard", "0"})]; got != 1 {
+		t.Fatalf("entities gauge = %v", got)
+	}
+
+	w := NewCoreWorld("arena")
+	w.SetMetrics(rec)
+	done := make(chan error, 1)
+	go func() { done <- w.Run() }()
+	waitForRunning(t, w)
+	if got := rec.gaugeValue(MetricWorldRunning, "world", "arena"); got != 1 {
+		t.Fatalf("running gauge = %v", got)
+	}
+	_ = w.Stop()
+	<-done
+	if got := rec.gaugeValue(MetricWorldRunning, "world", "arena"); got != 0 {
+		t.Fatalf("running gauge after stop = %v", got)
+	}
+}
+
+func (r *recordingMetrics) gaugeValue(name string, labels ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.gauges[metricKey(name, labels)]
+}
+
+func TestMetrics_TxAndFlush(t *testing.T) {
+	rec := newRecordingMetrics()
+	SetDefaultMetrics(rec)
+	t.Cleanup(func() { SetDefaultMetrics(nil) })
+
+	m := newTestDataEntityManager()
+	ctx := context.Background()
+	ent, _ := m.Create(ctx, "e1", "e1", 1)
+	_ = ent.Add(newTestDataComponent())
+	_ = ent.Tx(func(tx DataEntity) error {
+		_, _ = tx.GetForUpdate(testDataComponentType)
+		return nil
+	})
+	if rec.histograms[MetricTxWaitSeconds] != 1 || rec.histograms[MetricTxHoldSeconds] != 1 {
+		t.Fatalf("tx histograms = %v", rec.histograms)
+	}
+
+	store := newMemoryPatchStore()
+	p := NewPersister(store)
+	if _, err := FlushAll(ctx, m, p); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if rec.gauges[MetricDirtyComponents] != 1 || rec.histograms[MetricFlushSeconds] != 1 {
+		t.Fatalf("flush metrics: gauges %v histograms %v", rec.gauges, rec.histograms)
+	}
+	if rec.counters[metricKey(MetricFlushedComponents, []string{"mode", "full"})] != 1 || rec.counters[MetricFlushedBytes] == 0 {
+		t.Fatalf("flush counters = %v", rec.counters)
+	}
+
+	store.fail = errors.New("disk full")
+	_, _ = ent.GetForUpdate(testDataComponentType)
+	if _, err := FlushAll(ctx, m, p); err == nil {
+		t.Fatalf("expected flush error")
+	}
+	if rec.counters[MetricFlushErrors] != 1 {
+		t.Fatalf("flush errors = %v", rec.counters[MetricFlushErrors])
+	}
+}
@@ -0,0 +1,161 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Migration upgrades stored payload bodies of one component type from
+// schema version From to From+1. Exactly one of Bytes and Map must be set.
+type Migration struct {
+	Type ComponentType
+	From uint32
+	// Bytes rewrites an encoded body. It receives bodies of any codec.
+	Bytes func(body []byte) ([]byte, error)
+	// Map edits a JSON body decoded into a generic map.
+	// Payloads of other codecs cannot be migrated with it.
+	Map func(doc map[string]any) error
+}
+
+type migrationKey struct {
+	typ  ComponentType
+	from uint32
+}
+
+// ComponentSource enumerates stored component payloads.
+// Records passed to fn must have Type and Payload set.
+type ComponentSource interface {
+	LoadComponents(ctx context.Context, fn func(rec ComponentRecord) error) error
+}
+
+// MigrateStats summarizes a MigrateStore run.
+type MigrateStats struct {
+	// Scanned is the number of records read.
+	Scanned int
+	// Migrated is the number of records rewritten.
+	Migrated int
+}
+
+// RegisterMigration adds a migration step to the registry.
+// Registering two steps for the same type and version is an error.
+func (r *ComponentRegistry) RegisterMigration(m Migration) error {
+	if m.From == 0 {
+		return fmt.Errorf("register migration %d: schema versions start at 1", m.Type)
+	}
+	if (m.Bytes == nil) == (m.Map == nil) {
+		return fmt.Errorf("register migration %d v%d: exactly one of Bytes and Map must be set", m.Type, m.From)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := migrationKey{typ: m.Type, from: m.From}
+	if _, ok := r.migrations[key]; ok {
+		return fmt.Errorf("register migration %d v%d: already registered", m.Type, m.From)
+	}
+	r.migrations[key] = m
+	return nil
+}
+
+// MigratePayload upgrades data to the current schema version of type t.
+// It reports whether the payload changed. Payloads without a schema version
+// are treated as version 1; payloads recording a different type name are
+// rejected with ErrComponentTypeMismatch.
+func (r *ComponentRegistry) MigratePayload(t ComponentType, data []byte) ([]byte, bool, error) {
+	spec, ok := r.Lookup(t)
+	if !ok {
+		return nil, false, fmt.Errorf("migrate component %d: %w", t, ErrUnknownComponentType)
+	}
+	header, body, err := SplitPayload(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("migrate component %s: %w", spec.Name, err)
+	}
+	if header.Name != "" && header.Name != spec.Name {
+		return nil, false, fmt.Errorf("migrate component %d: payload is %q, registered as %q: %w", t, header.Name, spec.Name, ErrComponentTypeMismatch)
+	}
+	from := header.Schema
+	if from == 0 {
+		from = 1
+	}
+	target := spec.schemaVersion()
+	if from > target {
+		return nil, false, fmt.Errorf("migrate component %s: payload v%d, registered v%d: %w", spec.Name, from, target, ErrUnsupportedSchema)
+	}
+	if from == target {
+		return data, false, nil
+	}
+
+	for v := from; v < target; v++ {
+		r.mu.RLock()
+		m, ok := r.migrations[migrationKey{typ: t, from: v}]
+		r.mu.RUnlock()
+		if !ok {
+			return nil, false, fmt.Errorf("migrate component %s: v%d to v%d: %w", spec.Name, v, v+1, ErrMissingMigration)
+		}
+		if body, err = m.apply(header.Codec, body); err != nil {
+			return nil, false, fmt.Errorf("migrate component %s: v%d to v%d: %w", spec.Name, v, v+1, err)
+		}
+	}
+	header.Schema = target
+	header.Name = spec.Name
+	return JoinPayload(header, body), true, nil
+}
+
+// MigrateStore eagerly upgrades every record of src to the current schema
+// versions of reg and saves changed records to dst. Records are rewritten as
+// they are read, so src must tolerate writes to dst during enumeration.
+func MigrateStore(ctx context.Context, src ComponentSource, dst ComponentStore, reg *ComponentRegistry) (MigrateStats, error) {
+	if reg == nil {
+		reg = DefaultComponentRegistry
+	}
+	var stats MigrateStats
+	err := src.LoadComponents(ctx, func(rec ComponentRecord) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		stats.Scanned++
+		payload, changed, err := reg.MigratePayload(rec.Type, rec.Payload)
+		if err != nil {
+			return fmt.Errorf("migrate entity %s: %w", rec.EntityId, err)
+		}
+		if !changed {
+			return nil
+		}
+		header, _, err := SplitPayload(payload)
+		if err != nil {
+			return err
+		}
+		rec.Payload = payload
+		rec.Codec = header.Codec
+		rec.Name = header.Name
+		if err := dst.SaveComponent(ctx, rec); err != nil {
+			return fmt.Errorf("migrate entity %s component %d: %w", rec.EntityId, rec.Type, err)
+		}
+		stats.Migrated++
+		return nil
+	})
+	return stats, err
+}
+
+func (m Migration) apply(codec string, body []byte) ([]byte, error) {
+	if m.Bytes != nil {
+		return m.Bytes(body)
+	}
+	if codec != JSONCodec.Name() {
+		return nil, fmt.Errorf("map migration on %s payload", codec)
+	}
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	if err := m.Map(doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
@@ -0,0 +1,151 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+type testSchemaComponent struct {
+	DataComponentCore
+	DisplayName string `json:"display_name"`
+	Level       int    `json:"level"`
+	Guild       string `json:"guild"`
+}
+
+func newTestSchemaRegistry(t *testing.T, version uint32) *ComponentRegistry {
+	t.Helper()
+	reg := NewComponentRegistry()
+	err := reg.Register(ComponentSpec{
+		Name: "label",
+		Type: testLabelComponentType,
+		New: func() Component {
+			return &testSchemaComponent{DataComponentCore: NewDataComponentCore(testLabelComponentType)}
+		},
+		SchemaVersion: version,
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	migrations := []Migration{
+		// v1 -> v2 renames name to display_name.
+		{Type: testLabelComponentType, From: 1, Map: func(doc map[string]any) error {
+			doc["display_name"] = doc["name"]
+			delete(doc, "name")
+			return nil
+		}},
+		// v2 -> v3 adds level.
+		{Type: testLabelComponentType, From: 2, Map: func(doc map[string]any) error {
+			doc["level"] = 1
+			return nil
+		}},
+		// v3 -> v4 rewrites raw bytes.
+		{Type: testLabelComponentType, From: 3, Bytes: func(body []byte) ([]byte, error) {
+			return bytes.Replace(body, []byte(`"guild":"old"`), []byte(`"guild":"new"`), 1), nil
+		}},
+	}
+	for _, m := range migrations {
+		if err := reg.RegisterMigration(m); err != nil {
+			t.Fatalf("register migration: %v", err)
+		}
+	}
+	return reg
+}
+
+func TestMigratePayload_MultiStepChain(t *testing.T) {
+	reg := newTestSchemaRegistry(t, 4)
+
+	legacy := []byte(`{"name":"aki","guild":"old","version":3}`)
+	c, err := reg.DecodeComponent(testLabelComponentType, legacy)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	got := c.(*testSchemaComponent)
+	if got.DisplayName != "aki" || got.Level != 1 || got.Guild != "new" || got.Version() != 3 {
+		t.Fatalf("migrated = %+v", got)
+	}
+
+	// Starting in the middle of the chain only runs the remaining steps.
+	v3 := JoinPayload(PayloadHeader{Codec: "json", Schema: 3}, []byte(`{"display_name":"aki","level":7,"guild":"old"}`))
+	migrated, changed, err := reg.MigratePayload(testLabelComponentType, v3)
+	if err != nil || !changed {
+		t.Fatalf("migrate: changed %v, err %v", changed, err)
+	}
+	header, body, _ := SplitPayload(migrated)
+	if header.Schema != 4 || string(body) != `{"display_name":"aki","level":7,"guild":"new"}` {
+		t.Fatalf("header %+v body %s", header, body)
+	}
+
+	// Current payloads pass through untouched.
+	current, err := reg.EncodeComponent(got)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if out, changed, err := reg.MigratePayload(testLabelComponentType, current); err != nil || changed || !bytes.Equal(out, current) {
+		t.Fatalf("current payload: changed %v, err %v", changed, err)
+	}
+}
+
+func TestMigratePayload_Errors(t *testing.T) {
+	reg := newTestSchemaRegistry(t, 5)
+	if _, _, err := reg.MigratePayload(testLabelComponentType, []byte(`{}`)); !errors.Is(err, ErrMissingMigration) {
+		t.Fatalf("expected ErrMissingMigration, got %v", err)
+	}
+
+	reg = newTestSchemaRegistry(t, 2)
+	future := JoinPayload(PayloadHeader{Codec: "json", Schema: 3}, []byte(`{}`))
+	if _, _, err := reg.MigratePayload(testLabelComponentType, future); !errors.Is(err, ErrUnsupportedSchema) {
+		t.Fatalf("expected ErrUnsupportedSchema, got %v", err)
+	}
+
+	binary := JoinPayload(PayloadHeader{Codec: "binary", Schema: 1}, []byte{1})
+	if _, _, err := reg.MigratePayload(testLabelComponentType, binary); err == nil {
+		t.Fatalf("expected map migration on binary payload to fail")
+	}
+
+	if err := reg.RegisterMigration(Migration{Type: testLabelComponentType, From: 1, Map: func(map[string]any) error { return nil }}); err == nil {
+		t.Fatalf("expected duplicate migration error")
+	}
+}
+
+type memoryComponentSource struct {
+	*memoryPatchStore
+	records []ComponentRecord
+}
+
+func (s *memoryComponentSource) LoadComponents(ctx context.Context, fn func(rec ComponentRecord) error) error {
+	for _, rec := range s.records {
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestMigrateStore_RewritesOldRecords(t *testing.T) {
+	reg := newTestSchemaRegistry(t, 4)
+	current, _ := reg.EncodeComponent(&testSchemaComponent{DataComponentCore: NewDataComponentCore(testLabelComponentType)})
+	src := &memoryComponentSource{
+		memoryPatchStore: newMemoryPatchStore(),
+		records: []ComponentRecord{
+			{EntityId: "1", Type: testLabelComponentType, StorageKey: "label", Payload: []byte(`{"name":"a"}`)},
+			{EntityId: "2", Type: testLabelComponentType, StorageKey: "label", Payload: current},
+		},
+	}
+
+	stats, err := MigrateStore(context.Background(), src, src, reg)
+	if err != nil {
+		t.Fatalf("migrate store: %v", err)
+	}
+	if stats.Scanned != 2 || stats.Migrated != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	header, _, err := SplitPayload(src.docs["1/label"])
+	if err != nil || header.Schema != 4 {
+		t.Fatalf("stored header %+v, err %v", header, err)
+	}
+	if _, ok := src.docs["2/label"]; ok {
+		t.Fatalf("current record should not be rewritten")
+	}
+}
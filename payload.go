@@ -0,0 +1,147 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Stored payloads start with a small self-describing header so they can be
+// decoded regardless of the codec currently configured:
+//
+//	"GK" | uvarint header length | fields
+//
+// Each field is a one-byte tag, a uvarint length and the value bytes.
+// Unknown tags are skipped, so fields can be added without breaking readers.
+// Payloads without the magic prefix are treated as headerless JSON.
+var payloadMagic = []byte("GK")
+
+const (
+	payloadFieldCodec  byte = 1
+	payloadFieldSchema byte = 2
+	payloadFieldName   byte = 3
+)
+
+// PayloadHeader describes how a stored payload was encoded.
+type PayloadHeader struct {
+	// Codec is the Name of the codec that encoded the body.
+	Codec string
+	// Schema is the component schema version of the body.
+	// Zero means the payload was written without one.
+	Schema uint32
+	// Name is the registered name of the component type.
+	// Empty means the payload was written without one.
+	Name string
+}
+
+// EncodePayload encodes v with codec and prepends a payload header.
+func EncodePayload(codec Codec, v any) ([]byte, error) {
+	if isNil(codec) {
+		codec = JSONCodec
+	}
+	body, err := codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return JoinPayload(PayloadHeader{Codec: codec.Name()}, body), nil
+}
+
+// DecodePayload decodes data into v using the codec named in its header.
+func DecodePayload(data []byte, v any) (PayloadHeader, error) {
+	header, body, err := SplitPayload(data)
+	if err != nil {
+		return header, err
+	}
+	codec, ok := LookupCodec(header.Codec)
+	if !ok {
+		return header, fmt.Errorf("decode payload: unknown codec %q: %w", header.Codec, ErrInvalidPayload)
+	}
+	if err := codec.Unmarshal(body, v); err != nil {
+		return header, fmt.Errorf("decode payload: %s: %w", header.Codec, err)
+	}
+	return header, nil
+}
+
+// JoinPayload prepends header to body.
+func JoinPayload(header PayloadHeader, body []byte) []byte {
+	var fields []byte
+	fields = appendPayloadField(fields, payloadFieldCodec, []byte(header.Codec))
+	if header.Schema != 0 {
+		fields = appendPayloadField(fields, payloadFieldSchema, binary.AppendUvarint(nil, uint64(header.Schema)))
+	}
+	if header.Name != "" {
+		fields = appendPayloadField(fields, payloadFieldName, []byte(header.Name))
+	}
+
+	out := make([]byte, 0, len(payloadMagic)+binary.MaxVarintLen64+len(fields)+len(body))
+	out = append(out, payloadMagic...)
+	out = binary.AppendUvarint(out, uint64(len(fields)))
+	out = append(out, fields...)
+	return append(out, body...)
+}
+
+// SplitPayload parses the header of data and returns it with the body.
+// Headerless data is reported as JSON.
+func SplitPayload(data []byte) (PayloadHeader, []byte, error) {
+	if !bytes.HasPrefix(data, payloadMagic) {
+		return PayloadHeader{Codec: JSONCodec.Name()}, data, nil
+	}
+	rest := data[len(payloadMagic):]
+	n, size := binary.Uvarint(rest)
+	if size <= 0 || uint64(len(rest)-size) < n {
+		return PayloadHeader{}, nil, fmt.Errorf("split payload: header length: %w", ErrInvalidPayload)
+	}
+	fields := rest[size : size+int(n)]
+	body := rest[size+int(n):]
+
+	var header PayloadHeader
+	for len(fields) > 0 {
+		tag := fields[0]
+		l, size := binary.Uvarint(fields[1:])
+		if size <= 0 || uint64(len(fields)-1-size) < l {
+			return PayloadHeader{}, nil, fmt.Errorf("split payload: field %d: %w", tag, ErrInvalidPayload)
+		}
+		value := fields[1+size : 1+size+int(l)]
+		fields = fields[1+size+int(l):]
+		switch tag {
+		case payloadFieldCodec:
+			header.Codec = string(value)
+		case payloadFieldSchema:
+			schema, size := binary.Uvarint(value)
+			if size <= 0 || schema > math.MaxUint32 {
+				return PayloadHeader{}, nil, fmt.Errorf("split payload: schema: %w", ErrInvalidPayload)
+			}
+			header.Schema = uint32(schema)
+		case payloadFieldName:
+			header.Name = string(value)
+		}
+	}
+	if header.Codec == "" {
+		return PayloadHeader{}, nil, fmt.Errorf("split payload: missing codec: %w", ErrInvalidPayload)
+	}
+	return header, body, nil
+}
+
+// ApplyPayloadPatch applies a JSON merge patch to the body of a stored JSON payload,
+// keeping its header.
+func ApplyPayloadPatch(payload []byte, patch []byte) ([]byte, error) {
+	header, body, err := SplitPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	if header.Codec != JSONCodec.Name() {
+		return nil, fmt.Errorf("apply payload patch: codec %s does not support patches", header.Codec)
+	}
+	patched, err := ApplyMergePatch(body, patch)
+	if err != nil {
+		return nil, err
+	}
+	return JoinPayload(header, patched), nil
+}
+
+func appendPayloadField(dst []byte, tag byte, value []byte) []byte {
+	dst = append(dst, tag)
+	dst = binary.AppendUvarint(dst, uint64(len(value)))
+	return append(dst, value...)
+}
@@ -0,0 +1,540 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ComponentRecord is one component payload handed to a ComponentStore.
+type ComponentRecord struct {
+	EntityId   string
+	Type       ComponentType
+	StorageKey string
+	Version    uint64
+	// Name is the registered name of the component type, if any.
+	Name string
+	// Codec names the codec of the payload body.
+	Codec string
+	// Payload is the full encoded component, including its payload header,
+	// for SaveComponent. For PatchComponent it is an RFC 7396 JSON merge
+	// patch against the body of the previously saved payload; use
+	// ApplyPayloadPatch to apply it.
+	Payload []byte
+}
+
+// ComponentStore persists full component payloads.
+type ComponentStore interface {
+	SaveComponent(ctx context.Context, rec ComponentRecord) error
+}
+
+// PatchStore is a ComponentStore that can apply partial updates.
+// Persister only sends patches to stores implementing it.
+type PatchStore interface {
+	ComponentStore
+	PatchComponent(ctx context.Context, rec ComponentRecord) error
+}
+
+// ComponentMarshaler is implemented by components that encode their own payload.
+// The output is stored as is, so it should carry a payload header (see EncodePayload).
+// Components without it are encoded with the codec registered for their type.
+type ComponentMarshaler interface {
+	Marshal() ([]byte, error)
+}
+
+// FlushStats summarizes a flush.
+type FlushStats struct {
+	// Entities is the number of entities with at least one write.
+	Entities int
+	// Full is the number of full payload writes.
+	Full int
+	// Patches is the number of partial updates.
+	Patches int
+	// Bytes is the total number of payload bytes written.
+	Bytes int
+	// Dirty is the number of dirty components found.
+	Dirty int
+}
+
+// Persister flushes dirty DataComponents to a ComponentStore.
+//
+// Payloads are encoded under the entity lock and written outside of it;
+// dirty flags are cleared afterwards only for components whose version did
+// not change in between, so concurrent updates are never lost.
+//
+// Components are encoded with the codec registered for their type.
+// With delta payloads enabled and a PatchStore, Persister keeps the last
+// persisted payload per component and writes JSON merge patches instead of
+// full payloads. Components implementing FieldChangeReporter have their
+// patches built from the reported fields; others are diffed against the
+// snapshot. A full rewrite is used when there is no snapshot, the payload is
+// not a JSON object, or the patch would not be smaller.
+type Persister struct {
+	store    ComponentStore
+	registry *ComponentRegistry
+
+	mu        sync.Mutex
+	deltas    bool
+	snapshots map[persistKey][]byte
+	metrics   Metrics
+	tracer    Tracer
+	logger    *slog.Logger
+}
+
+type persistKey struct {
+	id  string
+	typ ComponentType
+}
+
+type pendingWrite struct {
+	rec   ComponentRecord
+	full  []byte
+	patch bool
+	skip  bool
+}
+
+// NewPersister creates a Persister writing to store.
+func NewPersister(store ComponentStore) *Persister {
+	return &Persister{
+		store:     store,
+		registry:  DefaultComponentRegistry,
+		snapshots: make(map[persistKey][]byte),
+	}
+}
+
+// SetRegistry sets the registry used to choose codecs per ComponentType.
+// Nil restores DefaultComponentRegistry.
+func (p *Persister) SetRegistry(reg *ComponentRegistry) {
+	if reg == nil {
+		reg = DefaultComponentRegistry
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.registry = reg
+}
+
+// SetMetrics sets where flush counts, sizes, errors and durations are reported.
+// Nil uses DefaultMetrics.
+func (p *Persister) SetMetrics(m Metrics) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.metrics = m
+}
+
+func (p *Persister) currentMetrics() Metrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return metricsOrDefault(p.metrics)
+}
+
+// SetTracer sets the Tracer for flush spans. Nil uses DefaultTracer.
+func (p *Persister) SetTracer(t Tracer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tracer = t
+}
+
+func (p *Persister) currentTracer() Tracer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tracer != nil {
+		return p.tracer
+	}
+	return DefaultTracer()
+}
+
+// SetLogger sets the logger for flush errors and for components left dirty
+// because they changed while being written. Nil uses DefaultLogger.
+func (p *Persister) SetLogger(l *slog.Logger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logger = l
+}
+
+func (p *Persister) currentLogger() *slog.Logger {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return loggerOrDefault(p.logger)
+}
+
+// SetDeltaPayloads enables or disables partial updates.
+// Disabling drops all cached snapshots.
+func (p *Persister) SetDeltaPayloads(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.deltas = enabled
+	if !enabled {
+		clear(p.snapshots)
+	}
+}
+
+// Forget drops cached snapshots for an entity, forcing full writes next time.
+func (p *Persister) Forget(entityId string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key := range p.snapshots {
+		if key.id == entityId {
+			delete(p.snapshots, key)
+		}
+	}
+}
+
+// AttachPersister drops p's snapshots for entities removed from m.
+// The returned func detaches p from m.
+func AttachPersister[T Entity](m EntityManager[T], p *Persister) func() {
+	return m.OnRemove(func(ent T) {
+		p.Forget(ent.Id())
+	})
+}
+
+// FlushAll flushes every entity in m.
+// It reports its duration and the dirty backlog it found.
+func FlushAll[T DataEntity](ctx context.Context, m EntityManager[T], p *Persister) (FlushStats, error) {
+	start := time.Now()
+	ctx, span := p.currentTracer().Start(ctx, SpanFlush)
+	var total FlushStats
+	err := m.ForEach(ctx, func(ent T) error {
+		stats, err := p.FlushEntity(ctx, ent)
+		total.add(stats)
+		return err
+	})
+	span.SetAttributes(total.spanAttrs()...)
+	endSpan(span, err)
+	metrics := p.currentMetrics()
+	metrics.ObserveHistogram(MetricFlushSeconds, time.Since(start).Seconds())
+	metrics.SetGauge(MetricDirtyComponents, float64(total.Dirty))
+	return total, err
+}
+
+// ResourceRecord is one persistent resource payload handed to a ResourceStore.
+type ResourceRecord struct {
+	StorageKey string
+	Version    uint64
+	// Payload is the resource encoded with JSONCodec, including its payload
+	// header; decode it with RestoreResource or DecodePayload.
+	Payload []byte
+}
+
+// ResourceStore persists world resources.
+type ResourceStore interface {
+	SaveResource(ctx context.Context, rec ResourceRecord) error
+}
+
+// FlushResources writes the dirty persistent resources of w to store and
+// clears their dirty flags. Resources are encoded under their read lock and
+// written outside of it; like FlushAll, a resource written to while being
+// saved stays dirty. It returns the number of resources written.
+func FlushResources(ctx context.Context, w ResourceHolder, store ResourceStore) (int, error) {
+	res := w.Resources()
+	records, err := res.encodeDirty()
+	if err != nil {
+		return 0, err
+	}
+	written := 0
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		if err := store.SaveResource(ctx, rec.ResourceRecord); err != nil {
+			return written, fmt.Errorf("save resource %s: %w", rec.StorageKey, err)
+		}
+		res.ClearDirty(rec.typ, rec.Version)
+		written++
+	}
+	return written, nil
+}
+
+// FlushEntity writes the dirty DataComponents of ent and clears their dirty flags.
+func (p *Persister) FlushEntity(ctx context.Context, ent DataEntity) (FlushStats, error) {
+	tracer := p.currentTracer()
+	var stats FlushStats
+	var err error
+	if isNopTracer(tracer) {
+		stats, err = p.flushEntity(ctx, tracer, ent)
+	} else {
+		var span Span
+		ctx, span = tracer.Start(ctx, SpanFlushEntity, SpanAttr{Key: AttrEntityId, Value: ent.Id()})
+		stats, err = p.flushEntity(ctx, tracer, ent)
+		span.SetAttributes(stats.spanAttrs()...)
+		endSpan(span, err)
+	}
+	if err != nil {
+		p.currentLogger().LogAttrs(ctx, slog.LevelError, "flush entity failed", LogEntity(ent), slog.Any("error", err))
+	}
+	metrics := p.currentMetrics()
+	if isNopMetrics(metrics) {
+		return stats, err
+	}
+	if err != nil {
+		metrics.AddCounter(MetricFlushErrors, 1)
+	}
+	if stats.Full > 0 {
+		metrics.AddCounter(MetricFlushedComponents, float64(stats.Full), "mode", "full")
+	}
+	if stats.Patches > 0 {
+		metrics.AddCounter(MetricFlushedComponents, float64(stats.Patches), "mode", "patch")
+	}
+	if stats.Bytes > 0 {
+		metrics.AddCounter(MetricFlushedBytes, float64(stats.Bytes))
+	}
+	return stats, err
+}
+
+func (p *Persister) flushEntity(ctx context.Context, tracer Tracer, ent DataEntity) (FlushStats, error) {
+	var stats FlushStats
+	if p.store == nil {
+		return stats, fmt.Errorf("persist: nil store")
+	}
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+
+	var writes []pendingWrite
+	var missing []ComponentType
+	if err := txContext(ctx, tracer, ent, func(ctx context.Context, tx DataEntity) error {
+		dirtyTypes := tx.DirtyTypes()
+		if len(dirtyTypes) == 0 {
+			return nil
+		}
+		stats.Dirty = len(dirtyTypes)
+		writes = make([]pendingWrite, 0, len(dirtyTypes))
+		for _, t := range dirtyTypes {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			component, ok := tx.Get(t)
+			if !ok {
+				missing = append(missing, t)
+				continue
+			}
+			dataComponent, ok := component.(DataComponent)
+			if !ok {
+				return fmt.Errorf("persist: component %d is not a DataComponent", t)
+			}
+			full, err := p.encode(component)
+			if err != nil {
+				return fmt.Errorf("persist: marshal component %d: %w", t, err)
+			}
+			header, _, err := SplitPayload(full)
+			if err != nil {
+				return fmt.Errorf("persist: marshal component %d: %w", t, err)
+			}
+			w := pendingWrite{
+				rec: ComponentRecord{
+					EntityId:   tx.Id(),
+					Type:       t,
+					StorageKey: dataComponent.StorageKey(),
+					Version:    dataComponent.Version(),
+					Name:       header.Name,
+					Codec:      header.Codec,
+					Payload:    full,
+				},
+				full: full,
+			}
+			if patch, ok := p.deltaPayload(tx.Id(), component, full); ok {
+				w.rec.Payload = patch
+				w.patch = true
+				w.skip = IsEmptyMergePatch(patch)
+			}
+			writes = append(writes, w)
+		}
+		return nil
+	}); err != nil {
+		return stats, err
+	}
+
+	for _, w := range writes {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		if !w.skip {
+			var err error
+			if w.patch {
+				err = p.store.(PatchStore).PatchComponent(ctx, w.rec)
+			} else {
+				err = p.store.SaveComponent(ctx, w.rec)
+			}
+			if err != nil {
+				return stats, fmt.Errorf("persist: entity %s component %d: %w", w.rec.EntityId, w.rec.Type, err)
+			}
+			if w.patch {
+				stats.Patches++
+			} else {
+				stats.Full++
+			}
+			stats.Bytes += len(w.rec.Payload)
+		}
+		p.rememberSnapshot(w.rec.EntityId, w.rec.Type, w.full)
+	}
+	if stats.Full+stats.Patches > 0 {
+		stats.Entities = 1
+	}
+
+	if len(writes) == 0 && len(missing) == 0 {
+		return stats, nil
+	}
+	var conflicts []versionConflict
+	err := txContext(ctx, tracer, ent, func(ctx context.Context, tx DataEntity) error {
+		toClear := make([]ComponentType, 0, len(writes)+len(missing))
+		for _, t := range missing {
+			if _, ok := tx.Get(t); !ok {
+				toClear = append(toClear, t)
+				p.forgetSnapshot(tx.Id(), t)
+			}
+		}
+		for _, w := range writes {
+			component, ok := tx.Get(w.rec.Type)
+			if !ok {
+				continue
+			}
+			dataComponent, ok := component.(DataComponent)
+			if !ok {
+				continue
+			}
+			if version := dataComponent.Version(); version != w.rec.Version {
+				conflicts = append(conflicts, versionConflict{typ: w.rec.Type, written: w.rec.Version, current: version})
+				continue
+			}
+			if reporter, ok := component.(FieldChangeReporter); ok {
+				reporter.ClearChangedFields()
+			}
+			toClear = append(toClear, w.rec.Type)
+		}
+		if len(toClear) > 0 {
+			tx.ClearDirty(toClear...)
+		}
+		return nil
+	})
+	if len(conflicts) > 0 {
+		p.logConflicts(ctx, ent, conflicts)
+	}
+	return stats, err
+}
+
+// versionConflict is a component whose version changed while it was written.
+type versionConflict struct {
+	typ     ComponentType
+	written uint64
+	current uint64
+}
+
+// logConflicts reports components left dirty because they changed during
+// the flush; the next flush writes their newer state.
+func (p *Persister) logConflicts(ctx context.Context, ent DataEntity, conflicts []versionConflict) {
+	p.mu.Lock()
+	logger := loggerOrDefault(p.logger)
+	reg := p.registry
+	p.mu.Unlock()
+	if !logger.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+	for _, c := range conflicts {
+		logger.LogAttrs(ctx, slog.LevelInfo, "component changed during flush; left dirty",
+			LogEntity(ent),
+			LogComponentType(reg, c.typ),
+			slog.Uint64("written_version", c.written),
+			slog.Uint64("current_version", c.current),
+		)
+	}
+}
+
+func (p *Persister) deltaPayload(id string, c Component, full []byte) ([]byte, bool) {
+	if _, ok := p.store.(PatchStore); !ok {
+		return nil, false
+	}
+	p.mu.Lock()
+	enabled := p.deltas
+	snapshot := p.snapshots[persistKey{id: id, typ: c.ComponentType()}]
+	p.mu.Unlock()
+	if !enabled || snapshot == nil {
+		return nil, false
+	}
+	header, body, err := SplitPayload(full)
+	if err != nil || header.Codec != JSONCodec.Name() || !isJSONObject(body) {
+		return nil, false
+	}
+	prevHeader, prevBody, err := SplitPayload(snapshot)
+	if err != nil || prevHeader != header {
+		return nil, false
+	}
+
+	var patch []byte
+	if reporter, ok := c.(FieldChangeReporter); ok && len(reporter.ChangedFields()) > 0 {
+		patch, err = pickJSONFields(body, append(reporter.ChangedFields(), versionFieldName))
+	} else {
+		patch, err = CreateMergePatch(prevBody, body)
+	}
+	if err != nil || len(patch) >= len(full) {
+		return nil, false
+	}
+	return patch, true
+}
+
+func (p *Persister) rememberSnapshot(id string, t ComponentType, full []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.deltas {
+		return
+	}
+	p.snapshots[persistKey{id: id, typ: t}] = full
+}
+
+func (p *Persister) forgetSnapshot(id string, t ComponentType) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.snapshots, persistKey{id: id, typ: t})
+}
+
+func (s FlushStats) spanAttrs() []SpanAttr {
+	return []SpanAttr{
+		{Key: AttrFlushFull, Value: s.Full},
+		{Key: AttrFlushPatches, Value: s.Patches},
+		{Key: AttrFlushBytes, Value: s.Bytes},
+		{Key: AttrFlushDirty, Value: s.Dirty},
+	}
+}
+
+func (s *FlushStats) add(other FlushStats) {
+	s.Entities += other.Entities
+	s.Full += other.Full
+	s.Patches += other.Patches
+	s.Bytes += other.Bytes
+	s.Dirty += other.Dirty
+}
+
+func (p *Persister) encode(c Component) ([]byte, error) {
+	if m, ok := c.(ComponentMarshaler); ok {
+		return m.Marshal()
+	}
+	p.mu.Lock()
+	reg := p.registry
+	p.mu.Unlock()
+	return reg.EncodeComponent(c)
+}
+
+// pickJSONFields builds a merge patch holding only the named top-level fields of doc.
+// Fields missing from doc are set to null, which deletes them.
+func pickJSONFields(doc []byte, fields []string) ([]byte, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &obj); err != nil {
+		return nil, err
+	}
+	patch := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		if v, ok := obj[f]; ok {
+			patch[f] = v
+		} else {
+			patch[f] = json.RawMessage("null")
+		}
+	}
+	return json.Marshal(patch)
+}
+
+func isJSONObject(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
@@ -0,0 +1,177 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+)
+
+const testInventoryComponentType ComponentType = 10004
+
+type testInventoryComponent struct {
+	DataComponentCore
+	FieldTracker
+	Gold  int64          `json:"gold"`
+	Items map[string]int `json:"items"`
+}
+
+func newTestInventoryComponent() *testInventoryComponent {
+	return &testInventoryComponent{
+		DataComponentCore: NewDataComponentCore(testInventoryComponentType),
+		Items:             map[string]int{"sword": 1, "potion": 5, "shield": 1, "arrow": 99},
+	}
+}
+
+func (c *testInventoryComponent) StorageKey() string {
+	return "inventory"
+}
+
+type memoryPatchStore struct {
+	mu      sync.Mutex
+	docs    map[string][]byte
+	full    int
+	patches []string
+	fail    error
+}
+
+func newMemoryPatchStore() *memoryPatchStore {
+	return &memoryPatchStore{docs: make(map[string][]byte)}
+}
+
+func (s *memoryPatchStore) SaveComponent(ctx context.Context, rec ComponentRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		return s.fail
+	}
+	s.full++
+	s.docs[rec.EntityId+"/"+rec.StorageKey] = rec.Payload
+	return nil
+}
+
+func (s *memoryPatchStore) PatchComponent(ctx context.Context, rec ComponentRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		return s.fail
+	}
+	key := rec.EntityId + "/" + rec.StorageKey
+	patched, err := ApplyPayloadPatch(s.docs[key], rec.Payload)
+	if err != nil {
+		return err
+	}
+	s.patches = append(s.patches, string(rec.Payload))
+	s.docs[key] = patched
+	return nil
+}
+
+func TestMergePatch_RoundTrip(t *testing.T) {
+	original := []byte(`{"a":1,"b":{"c":2,"d":3},"e":[1,2],"f":"x"}`)
+	modified := []byte(`{"a":1,"b":{"c":2,"d":4},"e":[1],"g":true}`)
+	patch, err := CreateMergePatch(original, modified)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if string(patch) != `{"b":{"d":4},"e":[1],"f":null,"g":true}` {
+		t.Fatalf("patch = %s", patch)
+	}
+	applied, err := ApplyMergePatch(original, patch)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	var got, want any
+	_ = json.Unmarshal(applied, &got)
+	_ = json.Unmarshal(modified, &want)
+	if string(mustJSON(t, got)) != string(mustJSON(t, want)) {
+		t.Fatalf("applied = %s", applied)
+	}
+}
+
+func TestPersister_FullThenPatches(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryPatchStore()
+	p := NewPersister(store)
+	p.SetDeltaPayloads(true)
+
+	ent := NewDataEntityCore("1", "entity", 1)
+	inv := newTestInventoryComponent()
+	_ = ent.Add(inv)
+	ent.GetForUpdate(testInventoryComponentType)
+
+	stats, err := p.FlushEntity(ctx, ent)
+	if err != nil {
+		t.Fatalf("first flush: %v", err)
+	}
+	if stats.Full != 1 || stats.Patches != 0 || len(ent.DirtyTypes()) != 0 {
+		t.Fatalf("unexpected first flush: %+v dirty=%v", stats, ent.DirtyTypes())
+	}
+
+	// Reported fields build the patch without diffing.
+	_ = ent.Tx(func(tx DataEntity) error {
+		c, _ := GetForUpdate[*testInventoryComponent](tx, testInventoryComponentType)
+		c.Gold = 10
+		c.MarkFieldChanged("gold")
+		return nil
+	})
+	if _, err := p.FlushEntity(ctx, ent); err != nil {
+		t.Fatalf("second flush: %v", err)
+	}
+	if len(store.patches) != 1 || store.patches[0] != `{"gold":10,"version":2}` {
+		t.Fatalf("patches = %v", store.patches)
+	}
+	if len(inv.ChangedFields()) != 0 {
+		t.Fatalf("changed fields should be cleared after flush")
+	}
+
+	// Without reported fields the payload is diffed against the snapshot.
+	_ = ent.Tx(func(tx DataEntity) error {
+		c, _ := GetForUpdate[*testInventoryComponent](tx, testInventoryComponentType)
+		c.Items["potion"] = 4
+		return nil
+	})
+	if _, err := p.FlushEntity(ctx, ent); err != nil {
+		t.Fatalf("third flush: %v", err)
+	}
+	if len(store.patches) != 2 || store.patches[1] != `{"items":{"potion":4},"version":3}` {
+		t.Fatalf("patches = %v", store.patches)
+	}
+
+	full, _ := json.Marshal(inv)
+	var disk, mem any
+	if _, err := DecodePayload(store.docs["1/inventory"], &disk); err != nil {
+		t.Fatalf("decode stored payload: %v", err)
+	}
+	_ = json.Unmarshal(full, &mem)
+	if string(mustJSON(t, disk)) != string(mustJSON(t, mem)) {
+		t.Fatalf("store = %s, component = %s", store.docs["1/inventory"], full)
+	}
+}
+
+func TestPersister_FailedWriteKeepsDirty(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryPatchStore()
+	store.fail = errors.New("disk full")
+	p := NewPersister(store)
+
+	ent := NewDataEntityCore("1", "entity", 1)
+	_ = ent.Add(newTestInventoryComponent())
+	ent.GetForUpdate(testInventoryComponentType)
+
+	if _, err := p.FlushEntity(ctx, ent); !errors.Is(err, store.fail) {
+		t.Fatalf("expected store error, got %v", err)
+	}
+	if len(ent.DirtyTypes()) != 1 {
+		t.Fatalf("dirty flag should survive a failed write")
+	}
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return data
+}
@@ -0,0 +1,207 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ComponentFactory builds a fresh component for a spawned entity.
+type ComponentFactory func() (Component, error)
+
+// Cloner is implemented by components that can copy themselves.
+// Clone must return a component that shares no mutable state with the
+// receiver, so every entity spawned from a Template gets its own copy.
+// ginka-gen generates such a Clone and rejects fields it cannot copy.
+type Cloner interface {
+	Clone() Component
+}
+
+// Prefab describes a pre-configured entity: its type, default tags and
+// the components every spawned instance starts with.
+type Prefab struct {
+	Name       string
+	EntityType EntityType
+	Tags       []Tag
+	Components []ComponentFactory
+	// MarkDirty bumps the version of spawned DataComponents and marks them
+	// dirty so the next flush persists the new entity.
+	MarkDirty bool
+}
+
+// PrefabSpec is the serializable description of a Prefab.
+// Components are referenced by their registered name and initialized from Data.
+type PrefabSpec struct {
+	Name       string                `json:"name"`
+	EntityType EntityType            `json:"entity_type"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	MarkDirty  bool                  `json:"mark_dirty,omitempty"`
+	Components []PrefabComponentSpec `json:"components"`
+}
+
+// PrefabComponentSpec is one component of a PrefabSpec.
+type PrefabComponentSpec struct {
+	// Type is the registered component name.
+	Type string `json:"type"`
+	// Data is decoded into a freshly constructed component for every spawn.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Template returns a factory that clones c for every spawn.
+func Template(c Cloner) ComponentFactory {
+	return func() (Component, error) {
+		if isNil(c) {
+			return nil, ErrNilComponent
+		}
+		return c.Clone(), nil
+	}
+}
+
+// LoadPrefab decodes a JSON prefab description using reg to construct components.
+// A nil reg uses DefaultComponentRegistry.
+func LoadPrefab(data []byte, reg *ComponentRegistry) (*Prefab, error) {
+	var spec PrefabSpec
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("load prefab: %w", err)
+	}
+	return NewPrefab(spec, reg)
+}
+
+// NewPrefab builds a Prefab from spec using reg to construct components.
+// Every component is built once up front so unknown types and bad data fail early.
+// A nil reg uses DefaultComponentRegistry.
+func NewPrefab(spec PrefabSpec, reg *ComponentRegistry) (*Prefab, error) {
+	if reg == nil {
+		reg = DefaultComponentRegistry
+	}
+	prefab := &Prefab{
+		Name:       spec.Name,
+		EntityType: spec.EntityType,
+		Tags:       append([]Tag(nil), spec.Tags...),
+		MarkDirty:  spec.MarkDirty,
+		Components: make([]ComponentFactory, 0, len(spec.Components)),
+	}
+	seen := make(map[ComponentType]struct{}, len(spec.Components))
+	for _, cs := range spec.Components {
+		componentSpec, ok := reg.LookupName(cs.Type)
+		if !ok {
+			return nil, fmt.Errorf("prefab %s: component %s: %w", spec.Name, cs.Type, ErrUnknownComponentType)
+		}
+		if _, dup := seen[componentSpec.Type]; dup {
+			return nil, fmt.Errorf("prefab %s: component %s: %w", spec.Name, cs.Type, ErrComponentAlreadyExists)
+		}
+		seen[componentSpec.Type] = struct{}{}
+
+		data := append(json.RawMessage(nil), cs.Data...)
+		factory := func() (Component, error) {
+			c, err := componentSpec.build()
+			if err != nil {
+				return nil, err
+			}
+			if len(data) > 0 {
+				if err := json.Unmarshal(data, c); err != nil {
+					return nil, fmt.Errorf("component %s: %w", componentSpec.Name, err)
+				}
+			}
+			return c, nil
+		}
+		if _, err := factory(); err != nil {
+			return nil, fmt.Errorf("prefab %s: %w", spec.Name, err)
+		}
+		prefab.Components = append(prefab.Components, factory)
+	}
+	return prefab, nil
+}
+
+// Spawn creates an entity from prefab and registers it with m in one step.
+//
+// The entity is fully built (tags, components, dirty marks) before it becomes
+// visible through m. Overrides replace the prefab component of the same
+// ComponentType, or are added if the prefab has none. Under a context
+// returned by WithTxObserver, the attached components are reported to the
+// observers as one transaction once the entity is registered.
+func Spawn[T Entity](ctx context.Context, m EntityManager[T], prefab *Prefab, id string, name string, overrides ...Component) (T, error) {
+	var zero T
+	if prefab == nil {
+		return zero, fmt.Errorf("spawn %s: nil prefab", id)
+	}
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	ent, err := m.NewEntity(id, name, prefab.EntityType, prefab.Tags...)
+	if err != nil {
+		return zero, fmt.Errorf("spawn %s from %s: %w", id, prefab.Name, err)
+	}
+
+	pending := make(map[ComponentType]Component, len(overrides))
+	order := make([]ComponentType, 0, len(overrides))
+	for _, c := range overrides {
+		if isNil(c) {
+			return zero, fmt.Errorf("spawn %s from %s: override: %w", id, prefab.Name, ErrNilComponent)
+		}
+		if _, dup := pending[c.ComponentType()]; !dup {
+			order = append(order, c.ComponentType())
+		}
+		pending[c.ComponentType()] = c
+	}
+
+	components := make([]Component, 0, len(prefab.Components)+len(overrides))
+	for _, factory := range prefab.Components {
+		c, err := factory()
+		if err != nil {
+			return zero, fmt.Errorf("spawn %s from %s: %w", id, prefab.Name, err)
+		}
+		if isNil(c) {
+			return zero, fmt.Errorf("spawn %s from %s: %w", id, prefab.Name, ErrNilComponent)
+		}
+		if override, ok := pending[c.ComponentType()]; ok {
+			c = override
+			delete(pending, c.ComponentType())
+		}
+		components = append(components, c)
+	}
+	for _, t := range order {
+		if c, ok := pending[t]; ok {
+			components = append(components, c)
+		}
+	}
+
+	var change *TxChange
+	if dataEnt, ok := any(ent).(DataEntity); ok {
+		change, err = journalTx(ctx, dataEnt, func(tx DataEntity) error {
+			return attachSpawned(tx, components, prefab.MarkDirty)
+		})
+	} else {
+		err = attachSpawned(ent, components, false)
+	}
+	if err != nil {
+		return zero, fmt.Errorf("spawn %s from %s: %w", id, prefab.Name, err)
+	}
+
+	if err := m.Add(ctx, ent); err != nil {
+		return zero, fmt.Errorf("spawn %s from %s: %w", id, prefab.Name, err)
+	}
+	reportTx(ctx, change)
+	return ent, nil
+}
+
+// attachSpawned adds components to ent and, with markDirty, marks them
+// dirty through ent, which must then be a DataEntity.
+func attachSpawned(ent Entity, components []Component, markDirty bool) error {
+	for _, c := range components {
+		if err := ent.Add(c); err != nil {
+			return fmt.Errorf("component %d: %w", c.ComponentType(), err)
+		}
+	}
+	if markDirty {
+		dataEnt := ent.(DataEntity)
+		for _, c := range components {
+			dataEnt.GetForUpdate(c.ComponentType())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const testLabelComponentType ComponentType = 10003
+
+type testLabelComponent struct {
+	DataComponentCore
+	Label string `json:"label"`
+}
+
+func newTestLabelComponent(label string) *testLabelComponent {
+	return &testLabelComponent{DataComponentCore: NewDataComponentCore(testLabelComponentType), Label: label}
+}
+
+func (c *testLabelComponent) StorageKey() string {
+	return "label"
+}
+
+func (c *testLabelComponent) Clone() Component {
+	out := *c
+	return &out
+}
+
+func newTestComponentRegistry(t *testing.T) *ComponentRegistry {
+	t.Helper()
+	reg := NewComponentRegistry()
+	specs := []ComponentSpec{
+		{Name: "test", Type: testDataComponentType, New: func() Component { return newTestDataComponent() }},
+		{Name: "label", Type: testLabelComponentType, New: func() Component { return newTestLabelComponent("") }},
+	}
+	for _, spec := range specs {
+		if err := reg.Register(spec); err != nil {
+			t.Fatalf("register %s: %v", spec.Name, err)
+		}
+	}
+	return reg
+}
+
+func TestComponentRegistry_RejectsDuplicates(t *testing.T) {
+	reg := newTestComponentRegistry(t)
+	err := reg.Register(ComponentSpec{Name: "other", Type: testLabelComponentType, New: func() Component { return newTestLabelComponent("") }})
+	if !errors.Is(err, ErrDuplicateComponentType) {
+		t.Fatalf("expected duplicate type error, got %v", err)
+	}
+	err = reg.Register(ComponentSpec{Name: "label", Type: 99, New: func() Component { return newTestLabelComponent("") }})
+	if !errors.Is(err, ErrDuplicateComponentType) {
+		t.Fatalf("expected duplicate name error, got %v", err)
+	}
+}
+
+func TestSpawn_TemplateOverridesAndDirty(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	template := newTestLabelComponent("default")
+	prefab := &Prefab{
+		Name:       "thing",
+		EntityType: 2,
+		Tags:       []Tag{"npc"},
+		Components: []ComponentFactory{
+			Template(template),
+			func() (Component, error) { return newTestDataComponent(), nil },
+		},
+		MarkDirty: true,
+	}
+
+	ent, err := Spawn[DataEntity](ctx, m, prefab, "e1", "first", newTestLabelComponent("custom"))
+	if err != nil {
+		t.Fatalf("spawn: %v", err)
+	}
+	if ent.Type() != 2 || !ent.HasTag("npc") {
+		t.Fatalf("unexpected entity type/tags: %d %v", ent.Type(), ent.Tags())
+	}
+	label, ok := Get[*testLabelComponent](ent, testLabelComponentType)
+	if !ok || label.Label != "custom" {
+		t.Fatalf("expected override, got %+v", label)
+	}
+	if len(ent.DirtyTypes()) != 2 || label.Version() != 1 {
+		t.Fatalf("expected both components dirty, got %v (version %d)", ent.DirtyTypes(), label.Version())
+	}
+
+	second, err := Spawn[DataEntity](ctx, m, prefab, "e2", "second")
+	if err != nil {
+		t.Fatalf("spawn: %v", err)
+	}
+	cloned, _ := Get[*testLabelComponent](second, testLabelComponentType)
+	if cloned == template || cloned.Label != "default" {
+		t.Fatalf("expected a fresh clone of the template")
+	}
+
+	if _, err := Spawn[DataEntity](ctx, m, prefab, "e1", "dup"); !errors.Is(err, ErrEntityAlreadyExists) {
+		t.Fatalf("expected ErrEntityAlreadyExists, got %v", err)
+	}
+}
+
+func TestSpawn_ReportsAttachedComponentsToObservers(t *testing.T) {
+	obs := &recordingTxObserver{}
+	ctx := WithTxObserver(context.Background(), newTestComponentRegistry(t), obs)
+	m := newTestDataEntityManager()
+	prefab := &Prefab{
+		Name:       "thing",
+		EntityType: 2,
+		Components: []ComponentFactory{
+			func() (Component, error) { return newTestDataComponent(), nil },
+			func() (Component, error) { return newTestLabelComponent("default"), nil },
+		},
+		MarkDirty: true,
+	}
+
+	if _, err := Spawn[DataEntity](ctx, m, prefab, "e1", "first"); err != nil {
+		t.Fatalf("spawn: %v", err)
+	}
+	if len(obs.changes) != 1 {
+		t.Fatalf("changes = %d", len(obs.changes))
+	}
+	change := obs.changes[0]
+	if change.EntityId != "e1" || change.EntityType != 2 || len(change.Components) != 2 {
+		t.Fatalf("change = %+v", change)
+	}
+	for _, cc := range change.Components {
+		if !cc.Added() || cc.After.Version != 1 {
+			t.Fatalf("component change = %+v", cc)
+		}
+	}
+
+	// A spawn that fails to register reports nothing.
+	if _, err := Spawn[DataEntity](ctx, m, prefab, "e1", "dup"); !errors.Is(err, ErrEntityAlreadyExists) {
+		t.Fatalf("expected ErrEntityAlreadyExists, got %v", err)
+	}
+	if len(obs.changes) != 1 {
+		t.Fatalf("failed spawn reported %d changes", len(obs.changes)-1)
+	}
+}
+
+func TestLoadPrefab(t *testing.T) {
+	ctx := context.Background()
+	reg := newTestComponentRegistry(t)
+	prefab, err := LoadPrefab([]byte(`{
+		"name": "labelled",
+		"entity_type": 3,
+		"tags": ["a", "b"],
+		"components": [{"type": "label", "data": {"label": "from-json"}}, {"type": "test"}]
+	}`), reg)
+	if err != nil {
+		t.Fatalf("load prefab: %v", err)
+	}
+
+	m := newTestDataEntityManager()
+	ent, err := Spawn[DataEntity](ctx, m, prefab, "e1", "first")
+	if err != nil {
+		t.Fatalf("spawn: %v", err)
+	}
+	label, ok := Get[*testLabelComponent](ent, testLabelComponentType)
+	if !ok || label.Label != "from-json" {
+		t.Fatalf("unexpected label: %+v", label)
+	}
+	if !ent.Has(testDataComponentType) || len(ent.DirtyTypes()) != 0 {
+		t.Fatalf("expected test component without dirty marks")
+	}
+
+	_, err = LoadPrefab([]byte(`{"name": "bad", "components": [{"type": "missing"}]}`), reg)
+	if !errors.Is(err, ErrUnknownComponentType) {
+		t.Fatalf("expected ErrUnknownComponentType, got %v", err)
+	}
+}
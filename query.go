@@ -0,0 +1,84 @@
+package ginka_ecs_go
+
+import "context"
+
+// Filter reports whether an entity matches a query.
+// Filters are evaluated outside of Tx and may call locking entity methods.
+type Filter func(ent Entity) bool
+
+// WithComponents matches entities that have all the given component types.
+func WithComponents(types ...ComponentType) Filter {
+	return func(ent Entity) bool {
+		for _, t := range types {
+			if !ent.Has(t) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// WithoutComponents matches entities that have none of the given component types.
+func WithoutComponents(types ...ComponentType) Filter {
+	return func(ent Entity) bool {
+		for _, t := range types {
+			if ent.Has(t) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// WithTags matches entities that have all the given tags.
+func WithTags(tags ...Tag) Filter {
+	return func(ent Entity) bool {
+		for _, tag := range tags {
+			if !ent.HasTag(tag) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// EnabledOnly matches enabled entities.
+func EnabledOnly() Filter {
+	return func(ent Entity) bool {
+		return ent.Enabled()
+	}
+}
+
+// Not inverts a filter.
+func Not(f Filter) Filter {
+	return func(ent Entity) bool {
+		return !f(ent)
+	}
+}
+
+// Matches reports whether ent passes every filter.
+// Nil filters are ignored.
+func Matches(ent Entity, filters ...Filter) bool {
+	for _, f := range filters {
+		if f == nil {
+			continue
+		}
+		if !f(ent) {
+			return false
+		}
+	}
+	return true
+}
+
+// ForEachMatching runs fn on entities in m that pass every filter.
+func ForEachMatching[T Entity](ctx context.Context, m EntityManager[T], filters []Filter, fn func(ent T) error) error {
+	if len(filters) == 0 {
+		return m.ForEach(ctx, fn)
+	}
+	return m.ForEach(ctx, func(ent T) error {
+		if !Matches(ent, filters...) {
+			return nil
+		}
+		return fn(ent)
+	})
+}
@@ -0,0 +1,205 @@
+package ginka_ecs_go
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// RelationKind names a relation between two entities (e.g. "friend-of", "member-of").
+type RelationKind string
+
+// Relations stores directed (kind, source, target) pairs between entity ids
+// with an optional payload of type P per pair.
+//
+// It is indexed in both directions, so Targets and Sources are map lookups.
+// Use struct{} as P when pairs carry no payload.
+type Relations[P any] struct {
+	mu      sync.RWMutex
+	forward map[RelationKind]map[string]map[string]P
+	reverse map[RelationKind]map[string]map[string]struct{}
+	count   int
+}
+
+// NewRelations creates an empty relation store.
+func NewRelations[P any]() *Relations[P] {
+	return &Relations[P]{
+		forward: make(map[RelationKind]map[string]map[string]P),
+		reverse: make(map[RelationKind]map[string]map[string]struct{}),
+	}
+}
+
+// AttachRelations removes every pair involving an entity when m removes it.
+// The returned func detaches the store from m.
+func AttachRelations[T Entity, P any](m EntityManager[T], r *Relations[P]) func() {
+	return m.OnRemove(func(ent T) {
+		r.RemoveEntity(ent.Id())
+	})
+}
+
+// Add relates source to target, replacing the payload if the pair already exists.
+func (r *Relations[P]) Add(kind RelationKind, source string, target string, payload P) error {
+	if source == "" || target == "" {
+		return fmt.Errorf("add relation %s: %w", kind, ErrInvalidEntityId)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	targets := r.forward[kind]
+	if targets == nil {
+		targets = make(map[string]map[string]P)
+		r.forward[kind] = targets
+	}
+	bySource := targets[source]
+	if bySource == nil {
+		bySource = make(map[string]P)
+		targets[source] = bySource
+	}
+	if _, exists := bySource[target]; !exists {
+		r.count++
+	}
+	bySource[target] = payload
+
+	sources := r.reverse[kind]
+	if sources == nil {
+		sources = make(map[string]map[string]struct{})
+		r.reverse[kind] = sources
+	}
+	byTarget := sources[target]
+	if byTarget == nil {
+		byTarget = make(map[string]struct{})
+		sources[target] = byTarget
+	}
+	byTarget[source] = struct{}{}
+	return nil
+}
+
+// Remove deletes the pair, returning true if it existed.
+func (r *Relations[P]) Remove(kind RelationKind, source string, target string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.removeUnlocked(kind, source, target)
+}
+
+// Has reports whether source is related to target.
+func (r *Relations[P]) Has(kind RelationKind, source string, target string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.forward[kind][source][target]
+	return ok
+}
+
+// Payload returns the payload stored for the pair.
+func (r *Relations[P]) Payload(kind RelationKind, source string, target string) (P, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.forward[kind][source][target]
+	return p, ok
+}
+
+// Targets returns the sorted ids that source is related to.
+func (r *Relations[P]) Targets(kind RelationKind, source string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedKeys(r.forward[kind][source])
+}
+
+// Sources returns the sorted ids related to target.
+func (r *Relations[P]) Sources(kind RelationKind, target string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedKeys(r.reverse[kind][target])
+}
+
+// RemoveEntity deletes every pair where id is the source or the target.
+// Returns the number of pairs removed.
+func (r *Relations[P]) RemoveEntity(id string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := 0
+	for kind, targets := range r.forward {
+		for target := range targets[id] {
+			if r.removeUnlocked(kind, id, target) {
+				removed++
+			}
+		}
+	}
+	for kind, sources := range r.reverse {
+		for source := range sources[id] {
+			if r.removeUnlocked(kind, source, id) {
+				removed++
+			}
+		}
+	}
+	return removed
+}
+
+// Len returns the total number of pairs.
+func (r *Relations[P]) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.count
+}
+
+// RelatedTo matches entities that are the source of a kind pair pointing at target.
+func (r *Relations[P]) RelatedTo(kind RelationKind, target string) Filter {
+	return func(ent Entity) bool {
+		return r.Has(kind, ent.Id(), target)
+	}
+}
+
+// RelatedFrom matches entities that are the target of a kind pair from source.
+func (r *Relations[P]) RelatedFrom(kind RelationKind, source string) Filter {
+	return func(ent Entity) bool {
+		return r.Has(kind, source, ent.Id())
+	}
+}
+
+// HasRelation matches entities that are the source of at least one kind pair.
+func (r *Relations[P]) HasRelation(kind RelationKind) Filter {
+	return func(ent Entity) bool {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return len(r.forward[kind][ent.Id()]) > 0
+	}
+}
+
+func (r *Relations[P]) removeUnlocked(kind RelationKind, source string, target string) bool {
+	targets := r.forward[kind]
+	bySource := targets[source]
+	if _, ok := bySource[target]; !ok {
+		return false
+	}
+	delete(bySource, target)
+	if len(bySource) == 0 {
+		delete(targets, source)
+	}
+	if len(targets) == 0 {
+		delete(r.forward, kind)
+	}
+
+	sources := r.reverse[kind]
+	byTarget := sources[target]
+	delete(byTarget, source)
+	if len(byTarget) == 0 {
+		delete(sources, target)
+	}
+	if len(sources) == 0 {
+		delete(r.reverse, kind)
+	}
+	r.count--
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
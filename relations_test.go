@@ -0,0 +1,103 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const (
+	relationFriendOf RelationKind = "friend-of"
+	relationMemberOf RelationKind = "member-of"
+)
+
+func TestRelations_BothDirections(t *testing.T) {
+	r := NewRelations[int]()
+	if err := r.Add(relationMemberOf, "p1", "guild", 3); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := r.Add(relationMemberOf, "p2", "guild", 1); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := r.Add(relationFriendOf, "p1", "p2", 0); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	if got := r.Sources(relationMemberOf, "guild"); !reflect.DeepEqual(got, []string{"p1", "p2"}) {
+		t.Fatalf("sources = %v", got)
+	}
+	if got := r.Targets(relationFriendOf, "p1"); !reflect.DeepEqual(got, []string{"p2"}) {
+		t.Fatalf("targets = %v", got)
+	}
+	if rank, ok := r.Payload(relationMemberOf, "p1", "guild"); !ok || rank != 3 {
+		t.Fatalf("payload = %d, %v", rank, ok)
+	}
+	if !r.Remove(relationMemberOf, "p1", "guild") {
+		t.Fatalf("expected remove success")
+	}
+	if r.Has(relationMemberOf, "p1", "guild") {
+		t.Fatalf("expected pair removed")
+	}
+	if r.Len() != 2 {
+		t.Fatalf("len = %d", r.Len())
+	}
+}
+
+func TestRelations_CleanupOnEntityRemove(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	for _, id := range []string{"p1", "p2", "p3"} {
+		if _, err := m.Create(ctx, id, id, 1); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	r := NewRelations[struct{}]()
+	detach := AttachRelations[DataEntity](m, r)
+	defer detach()
+
+	_ = r.Add(relationFriendOf, "p1", "p2", struct{}{})
+	_ = r.Add(relationFriendOf, "p2", "p3", struct{}{})
+	_ = r.Add(relationFriendOf, "p3", "p1", struct{}{})
+
+	m.Remove("p2")
+	if r.Len() != 1 || !r.Has(relationFriendOf, "p3", "p1") {
+		t.Fatalf("expected only p3->p1 left, len = %d", r.Len())
+	}
+	if got := r.Sources(relationFriendOf, "p2"); got != nil {
+		t.Fatalf("expected no sources for removed entity, got %v", got)
+	}
+}
+
+func TestRelations_QueryFilter(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	for _, id := range []string{"p1", "p2", "p3"} {
+		ent, err := m.Create(ctx, id, id, 1)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if id != "p3" {
+			if err := ent.Add(newTestDataComponent()); err != nil {
+				t.Fatalf("add component: %v", err)
+			}
+		}
+	}
+	r := NewRelations[struct{}]()
+	_ = r.Add(relationMemberOf, "p1", "guild", struct{}{})
+	_ = r.Add(relationMemberOf, "p3", "guild", struct{}{})
+
+	var got []string
+	filters := []Filter{WithComponents(testDataComponentType), r.RelatedTo(relationMemberOf, "guild")}
+	err := ForEachMatching[DataEntity](ctx, m, filters, func(ent DataEntity) error {
+		got = append(got, ent.Id())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("for each: %v", err)
+	}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"p1"}) {
+		t.Fatalf("matched = %v", got)
+	}
+}
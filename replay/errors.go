@@ -0,0 +1,14 @@
+package replay
+
+import "errors"
+
+var (
+	// ErrUnknownInput indicates an input kind with no registered handler.
+	ErrUnknownInput = errors.New("unknown input kind")
+	// ErrTickOrder indicates a tick lower than one already recorded.
+	ErrTickOrder = errors.New("tick went backwards")
+	// ErrBadLog indicates a log that cannot be read.
+	ErrBadLog = errors.New("malformed replay log")
+	// ErrDiverged indicates replayed state that differs from the recorded state.
+	ErrDiverged = errors.New("replay diverged")
+)
@@ -0,0 +1,94 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// Result summarizes a Replay.
+type Result struct {
+	// Inputs is the number of inputs applied.
+	Inputs int
+	// Checkpoints is the number of checkpoints that matched.
+	Checkpoints int
+	// Diverged reports whether replayed state differed from the log.
+	Diverged bool
+	// DivergedTick is the first tick that differed; 0 means the restored
+	// snapshot itself. DivergedSeq is the input whose outcome differed,
+	// if the divergence was a handler succeeding or failing differently.
+	DivergedTick uint64
+	DivergedSeq  uint64
+	// Want and Got describe the recorded and replayed state hash or outcome.
+	Want string
+	Got  string
+}
+
+// Replay restores log's snapshot into m, which should be empty, and
+// applies its inputs with handlers, which must act on the world owning m.
+// After each checkpoint it compares state hashes; each input's success or
+// failure must also match the recording.
+//
+// It stops at the first divergence and returns an error wrapping
+// ErrDiverged together with a Result describing where it happened.
+// A nil reg uses ginka_ecs_go.DefaultComponentRegistry.
+func Replay[T ginka_ecs_go.DataEntity](ctx context.Context, log *Log, m ginka_ecs_go.EntityManager[T], reg *ginka_ecs_go.ComponentRegistry, handlers *Handlers) (Result, error) {
+	var res Result
+	if err := Restore(ctx, m, reg, log.Header.Snapshot); err != nil {
+		return res, err
+	}
+	hasher := ginka_ecs_go.NewWorldHasher(m, reg)
+	hash, err := hashString(hasher.Sum(ctx))
+	if err != nil {
+		return res, err
+	}
+	if hash != log.Header.Hash {
+		return res.diverge(0, 0, log.Header.Hash, hash)
+	}
+
+	for _, e := range log.Entries {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		if e.IsCheckpoint() {
+			hash, err := hashString(hasher.Sum(ctx))
+			if err != nil {
+				return res, err
+			}
+			if hash != e.Hash {
+				return res.diverge(e.Tick, 0, e.Hash, hash)
+			}
+			res.Checkpoints++
+			continue
+		}
+		applyErr := handlers.apply(ctx, log.Header.Seed, e)
+		res.Inputs++
+		if (applyErr != nil) != (e.Error != "") {
+			return res.diverge(e.Tick, e.Seq, outcome(e.Error), outcome(errorText(applyErr)))
+		}
+	}
+	return res, nil
+}
+
+func (r Result) diverge(tick uint64, seq uint64, want string, got string) (Result, error) {
+	r.Diverged, r.DivergedTick, r.DivergedSeq, r.Want, r.Got = true, tick, seq, want, got
+	if seq != 0 {
+		return r, fmt.Errorf("replay: tick %d input %d: recorded %s, replayed %s: %w", tick, seq, want, got, ErrDiverged)
+	}
+	return r, fmt.Errorf("replay: tick %d: recorded state %s, replayed %s: %w", tick, want, got, ErrDiverged)
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func outcome(errText string) string {
+	if errText == "" {
+		return "success"
+	}
+	return fmt.Sprintf("error %q", errText)
+}
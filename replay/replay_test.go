@@ -0,0 +1,197 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+	"github.com/Shigure42/ginka-ecs-go/ecstest"
+)
+
+const testWalletType ginka_ecs_go.ComponentType = 1
+
+type testWallet struct {
+	ginka_ecs_go.DataComponentCore
+	Gold int64 `json:"gold"`
+}
+
+func (c *testWallet) StorageKey() string {
+	return "wallet"
+}
+
+func newTestWallet() *testWallet {
+	return &testWallet{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testWalletType)}
+}
+
+// newTestWorld builds an empty world registering the test wallet.
+func newTestWorld(t *testing.T) *ecstest.World {
+	return ecstest.NewWorld(t).Components(ginka_ecs_go.ComponentSpec{
+		Name: "wallet", Type: testWalletType, New: func() ginka_ecs_go.Component { return newTestWallet() },
+	}).Build()
+}
+
+type spawnRequest struct {
+	Id string `json:"id"`
+}
+
+type lootRequest struct {
+	Id string `json:"id"`
+}
+
+// newTestHandlers registers a spawn input and a loot input that adds a
+// random amount of gold plus bonus.
+func newTestHandlers(m *ginka_ecs_go.MapEntityManager[ginka_ecs_go.DataEntity], bonus int64) *Handlers {
+	h := NewHandlers()
+	Handle(h, "spawn", func(ctx context.Context, in Input[spawnRequest]) error {
+		ent, err := m.NewEntity(in.Request.Id, in.Request.Id, 1)
+		if err != nil {
+			return err
+		}
+		if err := ent.Add(newTestWallet()); err != nil {
+			return err
+		}
+		return m.Add(ctx, ent)
+	})
+	Handle(h, "loot", func(ctx context.Context, in Input[lootRequest]) error {
+		ent, ok := m.Get(in.Request.Id)
+		if !ok {
+			return fmt.Errorf("loot %s: %w", in.Request.Id, ginka_ecs_go.ErrEntityNotFound)
+		}
+		return ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+			c, _ := tx.GetForUpdate(testWalletType)
+			c.(*testWallet).Gold += int64(in.Rand.Intn(1000)) + bonus
+			return nil
+		})
+	})
+	return h
+}
+
+// record plays a short session against a world holding p1 and returns the
+// log and the final world.
+func record(t *testing.T) ([]byte, *ecstest.World) {
+	t.Helper()
+	ctx := context.Background()
+	w := newTestWorld(t)
+	m, reg := w.Entities, w.Registry
+	p1, _ := m.NewEntity("p1", "p1", 1, "player")
+	_ = p1.Add(newTestWallet())
+	if err := m.Add(ctx, p1); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	var buf bytes.Buffer
+	s, err := NewSession[ginka_ecs_go.DataEntity](ctx, &buf, "test", m, reg, newTestHandlers(m, 0), 42)
+	if err != nil {
+		t.Fatalf("session: %v", err)
+	}
+	steps := []struct {
+		kind    string
+		req     any
+		wantErr bool
+	}{
+		{"loot", lootRequest{Id: "p1"}, false},
+		{"spawn", spawnRequest{Id: "p2"}, false},
+		{"loot", lootRequest{Id: "p2"}, false},
+		{"loot", lootRequest{Id: "p3"}, true},
+		{"loot", lootRequest{Id: "p1"}, false},
+	}
+	for _, step := range steps {
+		if err := s.Apply(ctx, step.kind, step.req); (err != nil) != step.wantErr {
+			t.Fatalf("apply %s %v: %v", step.kind, step.req, err)
+		}
+		if err := s.Advance(ctx); err != nil {
+			t.Fatalf("advance: %v", err)
+		}
+	}
+	if err := s.Apply(ctx, "teleport", lootRequest{}); !errors.Is(err, ErrUnknownInput) {
+		t.Fatalf("unknown input err = %v", err)
+	}
+	if err := s.AdvanceTo(ctx, s.Tick()); !errors.Is(err, ErrTickOrder) {
+		t.Fatalf("advance to current tick err = %v", err)
+	}
+	return buf.Bytes(), w
+}
+
+func TestReplay_ReproducesRecordedState(t *testing.T) {
+	ctx := context.Background()
+	data, recorded := record(t)
+
+	log, err := ReadLog(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if log.Header.Seed != 42 || log.Header.World != "test" || len(log.Header.Snapshot.Entities) != 1 {
+		t.Fatalf("header = %+v", log.Header)
+	}
+
+	w := newTestWorld(t)
+	m := w.Entities
+	res, err := Replay[ginka_ecs_go.DataEntity](ctx, log, m, w.Registry, newTestHandlers(m, 0))
+	if err != nil {
+		t.Fatalf("replay: %v (%+v)", err, res)
+	}
+	if res.Diverged || res.Inputs != 5 || res.Checkpoints != 5 {
+		t.Fatalf("result = %+v", res)
+	}
+
+	var want, got bytes.Buffer
+	_ = ginka_ecs_go.DumpEntities[ginka_ecs_go.DataEntity](ctx, &want, recorded.Entities, recorded.Registry)
+	_ = ginka_ecs_go.DumpEntities[ginka_ecs_go.DataEntity](ctx, &got, m, w.Registry)
+	if want.String() != got.String() {
+		t.Fatalf("replayed state differs:\n%s\n---\n%s", want.String(), got.String())
+	}
+	p1, _ := m.Get("p1")
+	c, _ := p1.Get(testWalletType)
+	if c.(*testWallet).Gold == 0 {
+		t.Fatalf("loot gave no gold")
+	}
+}
+
+func TestReplay_ReportsFirstDivergence(t *testing.T) {
+	ctx := context.Background()
+	data, _ := record(t)
+
+	log, err := ReadLog(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	w := newTestWorld(t)
+	res, err := Replay[ginka_ecs_go.DataEntity](ctx, log, w.Entities, w.Registry, newTestHandlers(w.Entities, 1))
+	if !errors.Is(err, ErrDiverged) {
+		t.Fatalf("err = %v", err)
+	}
+	if !res.Diverged || res.DivergedTick != 1 || res.DivergedSeq != 0 || res.Inputs != 1 || res.Want == res.Got {
+		t.Fatalf("result = %+v", res)
+	}
+
+	// An input that failed when recorded but succeeds on replay.
+	log, _ = ReadLog(bytes.NewReader(data))
+	for i, e := range log.Entries {
+		if e.Error != "" {
+			log.Entries[i].Data = []byte(`{"id":"p1"}`)
+		}
+	}
+	w = newTestWorld(t)
+	res, err = Replay[ginka_ecs_go.DataEntity](ctx, log, w.Entities, w.Registry, newTestHandlers(w.Entities, 0))
+	if !errors.Is(err, ErrDiverged) || res.DivergedTick != 4 || res.DivergedSeq != 4 || res.Got != "success" {
+		t.Fatalf("result = %+v, err = %v", res, err)
+	}
+}
+
+func TestReadLog_RejectsMalformedInput(t *testing.T) {
+	for _, data := range []string{
+		``,
+		`{"format":1,"seed":1,"hash":"","snapshot":{}}`,
+		`{"format":3,"seed":1,"hash":"","snapshot":{}}`,
+		`{"format":2,"seed":1,"hash":""}`,
+		`{"format":2,"seed":1,"hash":"","snapshot":{}}` + "\n{",
+	} {
+		if _, err := ReadLog(strings.NewReader(data)); !errors.Is(err, ErrBadLog) {
+			t.Fatalf("ReadLog(%q) err = %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// Snapshot is the state of every entity in a manager. Components are
+// stored as payloads encoded with their registered codec. World resources
+// are not included.
+type Snapshot struct {
+	Entities []EntitySnapshot `json:"entities"`
+}
+
+// EntitySnapshot is one entity of a Snapshot.
+type EntitySnapshot struct {
+	Id         string                  `json:"id"`
+	Name       string                  `json:"name"`
+	Type       ginka_ecs_go.EntityType `json:"type"`
+	Tags       []ginka_ecs_go.Tag      `json:"tags,omitempty"`
+	Disabled   bool                    `json:"disabled,omitempty"`
+	Components []ComponentSnapshot     `json:"components,omitempty"`
+}
+
+// ComponentSnapshot is one component of an EntitySnapshot.
+type ComponentSnapshot struct {
+	Type     ginka_ecs_go.ComponentType `json:"type"`
+	Tags     []ginka_ecs_go.Tag         `json:"tags,omitempty"`
+	Disabled bool                       `json:"disabled,omitempty"`
+	Payload  []byte                     `json:"payload"`
+}
+
+// Capture snapshots every entity of m, sorted by id with components sorted
+// by type. A nil reg uses ginka_ecs_go.DefaultComponentRegistry.
+func Capture[T ginka_ecs_go.DataEntity](ctx context.Context, m ginka_ecs_go.EntityManager[T], reg *ginka_ecs_go.ComponentRegistry) (*Snapshot, error) {
+	if reg == nil {
+		reg = ginka_ecs_go.DefaultComponentRegistry
+	}
+	snap := &Snapshot{}
+	err := m.ForEach(ctx, func(ent T) error {
+		return ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+			es := EntitySnapshot{
+				Id:       tx.Id(),
+				Name:     tx.Name(),
+				Type:     tx.Type(),
+				Tags:     sortedTags(tx.Tags()),
+				Disabled: !tx.Enabled(),
+			}
+			for _, c := range tx.AllComponents() {
+				payload, err := encode(reg, c)
+				if err != nil {
+					return fmt.Errorf("capture %s: component %d: %w", tx.Id(), c.ComponentType(), err)
+				}
+				es.Components = append(es.Components, ComponentSnapshot{
+					Type:     c.ComponentType(),
+					Tags:     sortedTags(c.Tags()),
+					Disabled: !c.Enabled(),
+					Payload:  payload,
+				})
+			}
+			sort.Slice(es.Components, func(i, j int) bool { return es.Components[i].Type < es.Components[j].Type })
+			snap.Entities = append(snap.Entities, es)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(snap.Entities, func(i, j int) bool { return snap.Entities[i].Id < snap.Entities[j].Id })
+	return snap, nil
+}
+
+// Restore adds the entities of snap to m, decoding components with reg.
+// Restored components are not dirty. A nil reg uses
+// ginka_ecs_go.DefaultComponentRegistry.
+func Restore[T ginka_ecs_go.DataEntity](ctx context.Context, m ginka_ecs_go.EntityManager[T], reg *ginka_ecs_go.ComponentRegistry, snap *Snapshot) error {
+	if reg == nil {
+		reg = ginka_ecs_go.DefaultComponentRegistry
+	}
+	for _, es := range snap.Entities {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ent, err := m.NewEntity(es.Id, es.Name, es.Type, es.Tags...)
+		if err != nil {
+			return fmt.Errorf("restore %s: %w", es.Id, err)
+		}
+		ent.SetEnabled(!es.Disabled)
+		for _, cs := range es.Components {
+			c, err := reg.DecodeComponent(cs.Type, cs.Payload)
+			if err != nil {
+				return fmt.Errorf("restore %s: %w", es.Id, err)
+			}
+			c.SetEnabled(!cs.Disabled)
+			for _, tag := range cs.Tags {
+				c.AddTag(tag)
+			}
+			if err := ent.Add(c); err != nil {
+				return fmt.Errorf("restore %s: component %d: %w", es.Id, cs.Type, err)
+			}
+		}
+		if err := m.Add(ctx, ent); err != nil {
+			return fmt.Errorf("restore %s: %w", es.Id, err)
+		}
+	}
+	return nil
+}
+
+// StateHash returns the ginka_ecs_go.WorldHasher sum of m as 16 hex
+// digits, the form logs store. Equal state gives equal hashes. It hashes
+// every entity; sessions and replays keep a WorldHasher instead, so a
+// checkpoint only rehashes the entities that changed.
+func StateHash[T ginka_ecs_go.Entity](ctx context.Context, m ginka_ecs_go.EntityManager[T], reg *ginka_ecs_go.ComponentRegistry) (string, error) {
+	return hashString(ginka_ecs_go.NewWorldHasher(m, reg).Sum(ctx))
+}
+
+func hashString(sum uint64, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%016x", sum), nil
+}
+
+func encode(reg *ginka_ecs_go.ComponentRegistry, c ginka_ecs_go.Component) ([]byte, error) {
+	if m, ok := c.(ginka_ecs_go.ComponentMarshaler); ok {
+		return m.Marshal()
+	}
+	return reg.EncodeComponent(c)
+}
+
+func sortedTags(tags []ginka_ecs_go.Tag) []ginka_ecs_go.Tag {
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+	return tags
+}
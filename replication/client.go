@@ -0,0 +1,134 @@
+package replication
+
+import (
+	"fmt"
+	"sort"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// Client mirrors replicated state on the receiving side.
+// It is not safe for concurrent use.
+type Client struct {
+	registry *ginka_ecs_go.ComponentRegistry
+	lastSeq  uint64
+	entities map[string]*ClientEntity
+}
+
+// ClientEntity is the mirrored state of one entity.
+type ClientEntity struct {
+	Id         string
+	Type       ginka_ecs_go.EntityType
+	Components map[ginka_ecs_go.ComponentType]ginka_ecs_go.Component
+}
+
+// NewClient creates a Client decoding payloads with reg.
+// Nil uses DefaultComponentRegistry.
+func NewClient(reg *ginka_ecs_go.ComponentRegistry) *Client {
+	if reg == nil {
+		reg = ginka_ecs_go.DefaultComponentRegistry
+	}
+	return &Client{
+		registry: reg,
+		entities: make(map[string]*ClientEntity),
+	}
+}
+
+// Apply applies msg and reports whether it was newer than the last applied
+// message. Stale messages are ignored. On error the state is unchanged.
+func (c *Client) Apply(msg *Message) (bool, error) {
+	if msg.Seq <= c.lastSeq {
+		return false, nil
+	}
+
+	spawned := make([]*ClientEntity, 0, len(msg.Spawned))
+	for _, state := range msg.Spawned {
+		ent := &ClientEntity{Id: state.Id, Type: state.Type, Components: make(map[ginka_ecs_go.ComponentType]ginka_ecs_go.Component, len(state.Components))}
+		if err := c.decodeInto(ent, state.Components); err != nil {
+			return false, err
+		}
+		spawned = append(spawned, ent)
+	}
+	changed := make([]*ClientEntity, 0, len(msg.Changed))
+	for _, state := range msg.Changed {
+		ent := &ClientEntity{Id: state.Id, Type: state.Type, Components: make(map[ginka_ecs_go.ComponentType]ginka_ecs_go.Component, len(state.Components))}
+		if err := c.decodeInto(ent, state.Components); err != nil {
+			return false, err
+		}
+		for _, name := range state.Removed {
+			spec, ok := c.registry.LookupName(name)
+			if !ok {
+				return false, fmt.Errorf("apply message %d: entity %s: component %q: %w", msg.Seq, state.Id, name, ginka_ecs_go.ErrUnknownComponentType)
+			}
+			ent.Components[spec.Type] = nil
+		}
+		changed = append(changed, ent)
+	}
+
+	if msg.Full {
+		clear(c.entities)
+	}
+	for _, id := range msg.Despawned {
+		delete(c.entities, id)
+	}
+	for _, ent := range spawned {
+		c.entities[ent.Id] = ent
+	}
+	for _, update := range changed {
+		ent, ok := c.entities[update.Id]
+		if !ok {
+			ent = &ClientEntity{Id: update.Id, Type: update.Type, Components: make(map[ginka_ecs_go.ComponentType]ginka_ecs_go.Component)}
+			c.entities[update.Id] = ent
+		}
+		for t, component := range update.Components {
+			if component == nil {
+				delete(ent.Components, t)
+				continue
+			}
+			ent.Components[t] = component
+		}
+	}
+	c.lastSeq = msg.Seq
+	return true, nil
+}
+
+// LastSeq returns the sequence number of the last applied message.
+func (c *Client) LastSeq() uint64 {
+	return c.lastSeq
+}
+
+// Entity returns the mirrored entity with id.
+func (c *Client) Entity(id string) (*ClientEntity, bool) {
+	ent, ok := c.entities[id]
+	return ent, ok
+}
+
+// Ids returns the mirrored entity ids, sorted.
+func (c *Client) Ids() []string {
+	out := make([]string, 0, len(c.entities))
+	for id := range c.entities {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Len returns the number of mirrored entities.
+func (c *Client) Len() int {
+	return len(c.entities)
+}
+
+func (c *Client) decodeInto(ent *ClientEntity, states []ComponentState) error {
+	for _, state := range states {
+		spec, ok := c.registry.LookupName(state.Name)
+		if !ok {
+			return fmt.Errorf("entity %s: component %q: %w", ent.Id, state.Name, ginka_ecs_go.ErrUnknownComponentType)
+		}
+		component, err := c.registry.DecodeComponent(spec.Type, state.Payload)
+		if err != nil {
+			return fmt.Errorf("entity %s: %w", ent.Id, err)
+		}
+		ent.Components[spec.Type] = component
+	}
+	return nil
+}
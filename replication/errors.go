@@ -0,0 +1,12 @@
+package replication
+
+import "errors"
+
+var (
+	// ErrUnknownObserver indicates the replicator has no observer with the given id.
+	ErrUnknownObserver = errors.New("unknown observer")
+	// ErrObserverExists indicates an observer with the given id is already registered.
+	ErrObserverExists = errors.New("observer already exists")
+	// ErrNotReplicable indicates a component type cannot be replicated (unregistered or not a DataComponent).
+	ErrNotReplicable = errors.New("component type is not replicable")
+)
@@ -0,0 +1,178 @@
+package replication
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// Interest decides which entities an observer receives.
+//
+// Begin runs once per tick for every observer using the policy, before any
+// entity is checked and without entity locks held, so it may read other
+// entities (e.g. the observer's avatar). Interested runs inside the
+// entity's transaction and must not lock other entities.
+type Interest interface {
+	Begin(ctx context.Context, observer string) error
+	Interested(observer string, ent ginka_ecs_go.Entity) bool
+}
+
+// InterestEvent reports an entity entering or leaving an observer's interest set.
+// Entities that are removed from the world while visible are reported as leaving.
+type InterestEvent struct {
+	Observer string
+	EntityId string
+	Entered  bool
+}
+
+// InterestFunc adapts a predicate to Interest.
+type InterestFunc func(observer string, ent ginka_ecs_go.Entity) bool
+
+// Begin implements Interest.
+func (f InterestFunc) Begin(ctx context.Context, observer string) error { return nil }
+
+// Interested implements Interest.
+func (f InterestFunc) Interested(observer string, ent ginka_ecs_go.Entity) bool {
+	return f(observer, ent)
+}
+
+// ByTag selects entities carrying any of tags.
+func ByTag(tags ...ginka_ecs_go.Tag) Interest {
+	return InterestFunc(func(observer string, ent ginka_ecs_go.Entity) bool {
+		for _, tag := range tags {
+			if ent.HasTag(tag) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ByOwner selects entities whose owner, as reported by ownerOf, is the observer.
+func ByOwner(ownerOf func(ent ginka_ecs_go.Entity) string) Interest {
+	return InterestFunc(func(observer string, ent ginka_ecs_go.Entity) bool {
+		return ownerOf(ent) == observer
+	})
+}
+
+// AnyOf selects entities matching at least one policy.
+func AnyOf(policies ...Interest) Interest {
+	return composite{policies: policies, any: true}
+}
+
+// AllOf selects entities matching every policy.
+func AllOf(policies ...Interest) Interest {
+	return composite{policies: policies}
+}
+
+type composite struct {
+	policies []Interest
+	any      bool
+}
+
+func (c composite) Begin(ctx context.Context, observer string) error {
+	for _, p := range c.policies {
+		if err := p.Begin(ctx, observer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c composite) Interested(observer string, ent ginka_ecs_go.Entity) bool {
+	for _, p := range c.policies {
+		if p.Interested(observer, ent) == c.any {
+			return c.any
+		}
+	}
+	return !c.any
+}
+
+// GridInterest selects entities in grid cells near the observer.
+//
+// The plane is divided into square cells of CellSize; an entity is visible
+// when its cell is at most Radius cells away from the observer's cell on
+// either axis. Entities without a position and observers without a center
+// see nothing through this policy.
+type GridInterest struct {
+	cellSize float64
+	radius   int
+	position func(ent ginka_ecs_go.Entity) (x, y float64, ok bool)
+	center   func(ctx context.Context, observer string) (x, y float64, ok bool)
+
+	mu      sync.Mutex
+	centers map[string]gridCell
+}
+
+type gridCell struct {
+	x, y int
+}
+
+// NewGridInterest creates a GridInterest.
+// position reads an entity's coordinates inside its transaction; center
+// reports an observer's coordinates at the start of every tick.
+func NewGridInterest(cellSize float64, radius int, position func(ent ginka_ecs_go.Entity) (x, y float64, ok bool), center func(ctx context.Context, observer string) (x, y float64, ok bool)) *GridInterest {
+	if cellSize <= 0 {
+		cellSize = 1
+	}
+	if radius < 0 {
+		radius = 0
+	}
+	return &GridInterest{
+		cellSize: cellSize,
+		radius:   radius,
+		position: position,
+		center:   center,
+		centers:  make(map[string]gridCell),
+	}
+}
+
+// Cell returns the grid cell containing (x, y).
+func (g *GridInterest) Cell(x, y float64) (cx, cy int) {
+	return int(math.Floor(x / g.cellSize)), int(math.Floor(y / g.cellSize))
+}
+
+// Begin implements Interest by caching the observer's cell.
+func (g *GridInterest) Begin(ctx context.Context, observer string) error {
+	x, y, ok := g.center(ctx, observer)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !ok {
+		delete(g.centers, observer)
+		return nil
+	}
+	cx, cy := g.Cell(x, y)
+	g.centers[observer] = gridCell{x: cx, y: cy}
+	return nil
+}
+
+// Interested implements Interest.
+func (g *GridInterest) Interested(observer string, ent ginka_ecs_go.Entity) bool {
+	g.mu.Lock()
+	center, ok := g.centers[observer]
+	g.mu.Unlock()
+	if !ok {
+		return false
+	}
+	x, y, ok := g.position(ent)
+	if !ok {
+		return false
+	}
+	cx, cy := g.Cell(x, y)
+	return abs(cx-center.x) <= g.radius && abs(cy-center.y) <= g.radius
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+var (
+	_ Interest = InterestFunc(nil)
+	_ Interest = composite{}
+	_ Interest = (*GridInterest)(nil)
+)
@@ -0,0 +1,131 @@
+package replication
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+func positionOf(ent ginka_ecs_go.Entity) (float64, float64, bool) {
+	c, ok := ent.Get(testPositionType)
+	if !ok {
+		return 0, 0, false
+	}
+	pos := c.(*testPosition)
+	return float64(pos.X), float64(pos.Y), true
+}
+
+func TestReplicator_GridInterestEntersAndLeaves(t *testing.T) {
+	h := newHarness(t)
+	h.spawn("avatar", 0)
+	h.spawn("near", 5)
+	h.spawn("far", 50)
+
+	grid := NewGridInterest(10, 1, positionOf, func(ctx context.Context, observer string) (float64, float64, bool) {
+		ent, ok := h.m.Get("avatar")
+		if !ok {
+			return 0, 0, false
+		}
+		var x, y float64
+		var found bool
+		_ = ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+			x, y, found = positionOf(tx)
+			return nil
+		})
+		return x, y, found
+	})
+	if err := h.rep.SetInterest("a", grid); err != nil {
+		t.Fatalf("set interest: %v", err)
+	}
+	var events []InterestEvent
+	h.rep.OnInterestChange(func(ev InterestEvent) { events = append(events, ev) })
+
+	h.tick(true)
+	if !reflect.DeepEqual(h.client.Ids(), []string{"avatar", "near"}) {
+		t.Fatalf("client ids = %v", h.client.Ids())
+	}
+	want := []InterestEvent{{Observer: "a", EntityId: "avatar", Entered: true}, {Observer: "a", EntityId: "near", Entered: true}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %+v", events)
+	}
+
+	// The avatar walks towards far: near leaves the window, far enters.
+	events = nil
+	h.move("avatar", 40)
+	msg := h.tick(true)
+	if msg == nil || !reflect.DeepEqual(msg.Despawned, []string{"near"}) || len(msg.Spawned) != 1 || msg.Spawned[0].Id != "far" {
+		t.Fatalf("message = %+v", msg)
+	}
+	want = []InterestEvent{{Observer: "a", EntityId: "near"}, {Observer: "a", EntityId: "far", Entered: true}}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %+v", events)
+	}
+	if !reflect.DeepEqual(h.client.Ids(), []string{"avatar", "far"}) {
+		t.Fatalf("client ids = %v", h.client.Ids())
+	}
+
+	// Changes outside the window are not sent.
+	events = nil
+	h.move("near", 6)
+	if msg := h.tick(true); msg != nil {
+		t.Fatalf("expected no message, got %+v", msg)
+	}
+	if len(events) != 0 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	// Removing a visible entity reports it as leaving.
+	h.m.Remove("far")
+	h.tick(true)
+	if !reflect.DeepEqual(events, []InterestEvent{{Observer: "a", EntityId: "far"}}) {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestReplicator_PoliciesPerObserver(t *testing.T) {
+	h := newHarness(t)
+	if err := h.rep.AddObserver("b"); err != nil {
+		t.Fatalf("add observer: %v", err)
+	}
+	for _, id := range []string{"a:ship", "b:ship", "world:rock"} {
+		h.spawn(id, 0)
+	}
+	_ = h.m.MustGet("world:rock").AddTag("public")
+
+	owner := ByOwner(func(ent ginka_ecs_go.Entity) string {
+		owner, _, _ := strings.Cut(ent.Id(), ":")
+		return owner
+	})
+	_ = h.rep.SetInterest("a", AnyOf(owner, ByTag("public")))
+	_ = h.rep.SetInterest("b", AllOf(owner, InterestFunc(func(observer string, ent ginka_ecs_go.Entity) bool {
+		return ent.Type() == 1
+	})))
+	if err := h.rep.SetInterest("missing", owner); err == nil {
+		t.Fatalf("expected error for unknown observer")
+	}
+
+	h.tick(true)
+	if !reflect.DeepEqual(h.client.Ids(), []string{"a:ship", "world:rock"}) {
+		t.Fatalf("observer a sees %v", h.client.Ids())
+	}
+	b := NewClient(h.reg)
+	for _, msg := range h.transport.take("b") {
+		if _, err := b.Apply(msg); err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+	}
+	if !reflect.DeepEqual(b.Ids(), []string{"b:ship"}) {
+		t.Fatalf("observer b sees %v", b.Ids())
+	}
+
+	// Dropping the policy sends everything.
+	_ = h.rep.SetInterest("a", nil)
+	msg := h.tick(true)
+	if msg == nil || len(msg.Spawned) != 1 || msg.Spawned[0].Id != "b:ship" {
+		t.Fatalf("message = %+v", msg)
+	}
+	h.assertMirrored()
+}
@@ -0,0 +1,59 @@
+// Package replication streams entity state to remote observers as deltas.
+//
+// A Replicator tracks, per observer, the component versions the observer has
+// acknowledged and sends each tick only what differs from that baseline.
+// Messages carry full component payloads, so a lost message is covered by the
+// next one; a Client applies messages in sequence order and ignores stale ones.
+package replication
+
+import (
+	"context"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// Message is one delta sent to an observer.
+type Message struct {
+	// Seq increases with every message sent to the observer.
+	// Clients acknowledge it with Replicator.Ack.
+	Seq uint64 `json:"seq"`
+	// Full marks a message relative to an empty baseline. Clients drop
+	// their state before applying it.
+	Full bool `json:"full,omitempty"`
+	// Spawned lists entities the observer has not acknowledged yet,
+	// with all their replicated components.
+	Spawned []EntityState `json:"spawned,omitempty"`
+	// Changed lists known entities with changed or removed components.
+	Changed []EntityState `json:"changed,omitempty"`
+	// Despawned lists ids of entities that left the observer's view.
+	Despawned []string `json:"despawned,omitempty"`
+}
+
+// EntityState is the replicated state of one entity.
+type EntityState struct {
+	Id         string                  `json:"id"`
+	Type       ginka_ecs_go.EntityType `json:"type"`
+	Components []ComponentState        `json:"components,omitempty"`
+	// Removed lists names of components no longer present.
+	Removed []string `json:"removed,omitempty"`
+}
+
+// ComponentState is a full component payload.
+type ComponentState struct {
+	// Name is the registered component name.
+	Name    string `json:"name"`
+	Version uint64 `json:"version"`
+	// Payload is encoded by the ComponentRegistry and carries its own header.
+	Payload []byte `json:"payload"`
+}
+
+// Transport delivers messages to observers.
+// Delivery may be lossy; unacknowledged changes are sent again.
+type Transport interface {
+	Send(ctx context.Context, observer string, msg *Message) error
+}
+
+// IsEmpty reports whether msg carries no changes.
+func (msg *Message) IsEmpty() bool {
+	return len(msg.Spawned) == 0 && len(msg.Changed) == 0 && len(msg.Despawned) == 0
+}
@@ -0,0 +1,522 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// DefaultMaxPending is the number of unacknowledged messages after which an
+// observer is resynchronized from an empty baseline.
+const DefaultMaxPending = 32
+
+// Replicator sends entity state deltas to observers.
+//
+// Only component types enabled with Replicate are sent; entities without any
+// of them are not replicated. Each Tick compares the current component
+// versions with what the observer acknowledged and sends the difference.
+// Until an observer acknowledges a message, later messages keep including
+// the same changes. An observer with an Interest policy only receives the
+// entities the policy selects.
+type Replicator[T ginka_ecs_go.DataEntity] struct {
+	entities  ginka_ecs_go.EntityManager[T]
+	registry  *ginka_ecs_go.ComponentRegistry
+	transport Transport
+
+	mu         sync.Mutex
+	types      []ginka_ecs_go.ComponentType
+	names      map[ginka_ecs_go.ComponentType]string
+	maxPending int
+	observers  map[string]*observer
+
+	hooksMu    sync.RWMutex
+	nextHookId uint64
+	hooks      []interestHook
+}
+
+type interestHook struct {
+	id uint64
+	fn func(ev InterestEvent)
+}
+
+// entityView is the replicated shape of an entity at one tick.
+type entityView struct {
+	gen   uint64
+	typ   ginka_ecs_go.EntityType
+	comps map[ginka_ecs_go.ComponentType]uint64
+}
+
+// view maps entity ids to their replicated shape. Views are immutable once
+// built and shared between observers.
+type view map[string]*entityView
+
+type componentKey struct {
+	id  string
+	typ ginka_ecs_go.ComponentType
+}
+
+type pendingMessage struct {
+	seq  uint64
+	view view
+}
+
+// knownEntity records the last message that included an entity and each of
+// its components, so removals can be sent until they are acknowledged.
+type knownEntity struct {
+	gen   uint64
+	seq   uint64
+	comps map[ginka_ecs_go.ComponentType]uint64
+}
+
+type observer struct {
+	id       string
+	interest Interest
+	nextSeq  uint64
+	acked    view
+	ackedSeq uint64
+	pending  []pendingMessage
+	known    map[string]*knownEntity
+	// visible is the view sent on the last tick, used for interest events.
+	visible view
+}
+
+// NewReplicator creates a Replicator for the entities in m.
+// Payloads are encoded and named with reg; nil uses DefaultComponentRegistry.
+func NewReplicator[T ginka_ecs_go.DataEntity](m ginka_ecs_go.EntityManager[T], reg *ginka_ecs_go.ComponentRegistry, transport Transport) *Replicator[T] {
+	if reg == nil {
+		reg = ginka_ecs_go.DefaultComponentRegistry
+	}
+	return &Replicator[T]{
+		entities:   m,
+		registry:   reg,
+		transport:  transport,
+		names:      make(map[ginka_ecs_go.ComponentType]string),
+		maxPending: DefaultMaxPending,
+		observers:  make(map[string]*observer),
+	}
+}
+
+// SetMaxPending sets how many unacknowledged messages an observer may have
+// before it is resynchronized. Values below 1 restore DefaultMaxPending.
+func (r *Replicator[T]) SetMaxPending(n int) {
+	if n < 1 {
+		n = DefaultMaxPending
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxPending = n
+}
+
+// Replicate opts component types into replication.
+// Types must be registered DataComponents.
+func (r *Replicator[T]) Replicate(types ...ginka_ecs_go.ComponentType) error {
+	for _, t := range types {
+		spec, ok := r.registry.Lookup(t)
+		if !ok {
+			return fmt.Errorf("replicate component %d: %w", t, ErrNotReplicable)
+		}
+		c, err := r.registry.New(t)
+		if err != nil {
+			return fmt.Errorf("replicate component %s: %w", spec.Name, err)
+		}
+		if _, ok := c.(ginka_ecs_go.DataComponent); !ok {
+			return fmt.Errorf("replicate component %s: not a DataComponent: %w", spec.Name, ErrNotReplicable)
+		}
+
+		r.mu.Lock()
+		if _, ok := r.names[t]; !ok {
+			r.names[t] = spec.Name
+			r.types = append(r.types, t)
+			sort.Slice(r.types, func(i, j int) bool { return r.types[i] < r.types[j] })
+		}
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// AddObserver registers an observer. Its first message holds every
+// replicated entity.
+func (r *Replicator[T]) AddObserver(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.observers[id]; ok {
+		return fmt.Errorf("add observer %s: %w", id, ErrObserverExists)
+	}
+	r.observers[id] = &observer{id: id, known: make(map[string]*knownEntity)}
+	return nil
+}
+
+// RemoveObserver unregisters an observer.
+func (r *Replicator[T]) RemoveObserver(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.observers[id]; !ok {
+		return false
+	}
+	delete(r.observers, id)
+	return true
+}
+
+// SetInterest sets the policy deciding which entities the observer receives.
+// Nil sends every replicated entity. Entities leaving the interest set are
+// despawned on the observer's side.
+func (r *Replicator[T]) SetInterest(id string, interest Interest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o, ok := r.observers[id]
+	if !ok {
+		return fmt.Errorf("set interest %s: %w", id, ErrUnknownObserver)
+	}
+	o.interest = interest
+	return nil
+}
+
+// OnInterestChange registers fn to run when an entity enters or leaves an
+// observer's interest set. Events are delivered after Tick releases the
+// replicator, sorted by observer and entity id. The returned func
+// unregisters the hook.
+func (r *Replicator[T]) OnInterestChange(fn func(ev InterestEvent)) func() {
+	if fn == nil {
+		return func() {}
+	}
+	r.hooksMu.Lock()
+	r.nextHookId++
+	hookId := r.nextHookId
+	r.hooks = append(r.hooks, interestHook{id: hookId, fn: fn})
+	r.hooksMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.hooksMu.Lock()
+			defer r.hooksMu.Unlock()
+			for i, hook := range r.hooks {
+				if hook.id == hookId {
+					r.hooks = append(r.hooks[:i:i], r.hooks[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// Resync drops the observer's acknowledged baseline. The next message is a
+// full snapshot.
+func (r *Replicator[T]) Resync(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o, ok := r.observers[id]
+	if !ok {
+		return fmt.Errorf("resync %s: %w", id, ErrUnknownObserver)
+	}
+	o.resync()
+	return nil
+}
+
+// Ack records that the observer applied message seq.
+// Acks for unknown or already acknowledged messages are ignored.
+func (r *Replicator[T]) Ack(id string, seq uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o, ok := r.observers[id]
+	if !ok {
+		return fmt.Errorf("ack %s: %w", id, ErrUnknownObserver)
+	}
+	o.ack(seq)
+	return nil
+}
+
+// Tick sends one message to every observer whose view changed and returns
+// the number of messages sent. Send errors do not stop other observers;
+// the changes are sent again on the next tick.
+func (r *Replicator[T]) Tick(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	sent, events, err := r.tickLocked(ctx)
+	r.mu.Unlock()
+
+	if len(events) > 0 {
+		r.hooksMu.RLock()
+		hooks := r.hooks
+		r.hooksMu.RUnlock()
+		for _, ev := range events {
+			for _, hook := range hooks {
+				hook.fn(ev)
+			}
+		}
+	}
+	return sent, err
+}
+
+func (r *Replicator[T]) tickLocked(ctx context.Context) (int, []InterestEvent, error) {
+	if len(r.observers) == 0 || len(r.types) == 0 {
+		return 0, nil, nil
+	}
+
+	// Observers too far behind are resynchronized before the snapshot, which
+	// encodes payloads against the acknowledged baselines.
+	ids := make([]string, 0, len(r.observers))
+	for id, o := range r.observers {
+		ids = append(ids, id)
+		if len(o.pending) >= r.maxPending {
+			o.resync()
+		}
+		if o.interest != nil {
+			if err := o.interest.Begin(ctx, id); err != nil {
+				return 0, nil, fmt.Errorf("interest for %s: %w", id, err)
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	world, views, payloads, err := r.snapshotLocked(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var sent int
+	var events []InterestEvent
+	var errs []error
+	for _, id := range ids {
+		o := r.observers[id]
+		v := world
+		if o.interest != nil {
+			v = views[id]
+		}
+		events = appendInterestEvents(events, id, o.visible, v)
+		o.visible = v
+
+		msg := r.diff(o, v, payloads)
+		if msg.IsEmpty() {
+			continue
+		}
+		o.nextSeq++
+		msg.Seq = o.nextSeq
+		o.pending = append(o.pending, pendingMessage{seq: msg.Seq, view: v})
+		o.markSent(v, msg.Seq)
+		sent++
+		if err := r.transport.Send(ctx, id, msg); err != nil {
+			errs = append(errs, fmt.Errorf("replicate to %s: %w", id, err))
+		}
+	}
+	return sent, events, errors.Join(errs...)
+}
+
+// snapshotLocked builds the current view of the world and of every observer
+// with an interest policy, and encodes the payloads that at least one
+// observer seeing the entity has not acknowledged.
+func (r *Replicator[T]) snapshotLocked(ctx context.Context) (view, map[string]view, map[componentKey]ComponentState, error) {
+	world := make(view)
+	views := make(map[string]view)
+	payloads := make(map[componentKey]ComponentState)
+	for id, o := range r.observers {
+		if o.interest != nil {
+			views[id] = make(view)
+		}
+	}
+
+	err := r.entities.ForEach(ctx, func(ent T) error {
+		// Read the entity again with its generation so a concurrent
+		// re-create is reported with the incarnation it belongs to.
+		ent, gen, ok := r.entities.GetWithGeneration(ent.Id())
+		if !ok {
+			return nil
+		}
+		return ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+			var ev *entityView
+			var comps []ginka_ecs_go.Component
+			for _, t := range r.types {
+				c, ok := tx.Get(t)
+				if !ok {
+					continue
+				}
+				dc, ok := c.(ginka_ecs_go.DataComponent)
+				if !ok {
+					continue
+				}
+				if ev == nil {
+					ev = &entityView{gen: gen, typ: tx.Type(), comps: make(map[ginka_ecs_go.ComponentType]uint64)}
+				}
+				ev.comps[t] = dc.Version()
+				comps = append(comps, c)
+			}
+			if ev == nil {
+				return nil
+			}
+			world[tx.Id()] = ev
+
+			seeing := make([]*observer, 0, len(r.observers))
+			for id, o := range r.observers {
+				if o.interest == nil {
+					seeing = append(seeing, o)
+					continue
+				}
+				if o.interest.Interested(id, tx) {
+					views[id][tx.Id()] = ev
+					seeing = append(seeing, o)
+				}
+			}
+
+			for _, c := range comps {
+				t := c.ComponentType()
+				version := ev.comps[t]
+				if !needsPayload(seeing, tx.Id(), gen, t, version) {
+					continue
+				}
+				payload, err := r.registry.EncodeComponent(c)
+				if err != nil {
+					return fmt.Errorf("replicate entity %s component %d: %w", tx.Id(), t, err)
+				}
+				payloads[componentKey{id: tx.Id(), typ: t}] = ComponentState{Name: r.names[t], Version: version, Payload: payload}
+			}
+			return nil
+		})
+	})
+	return world, views, payloads, err
+}
+
+func needsPayload(observers []*observer, id string, gen uint64, t ginka_ecs_go.ComponentType, version uint64) bool {
+	for _, o := range observers {
+		base := o.acked[id]
+		if base == nil || base.gen != gen {
+			return true
+		}
+		if v, ok := base.comps[t]; !ok || v != version {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Replicator[T]) diff(o *observer, world view, payloads map[componentKey]ComponentState) *Message {
+	msg := &Message{Full: o.acked == nil}
+
+	ids := make([]string, 0, len(world))
+	for id := range world {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		ev := world[id]
+		state := EntityState{Id: id, Type: ev.typ}
+		base := o.acked[id]
+		if base == nil || base.gen != ev.gen {
+			for _, t := range r.types {
+				if _, ok := ev.comps[t]; ok {
+					state.Components = append(state.Components, payloads[componentKey{id: id, typ: t}])
+				}
+			}
+			msg.Spawned = append(msg.Spawned, state)
+			continue
+		}
+		for _, t := range r.types {
+			version, ok := ev.comps[t]
+			if !ok {
+				continue
+			}
+			if v, ok := base.comps[t]; !ok || v != version {
+				state.Components = append(state.Components, payloads[componentKey{id: id, typ: t}])
+			}
+		}
+		if known := o.known[id]; known != nil && known.gen == ev.gen {
+			for _, t := range r.types {
+				_, sentOnce := known.comps[t]
+				if _, present := ev.comps[t]; sentOnce && !present {
+					state.Removed = append(state.Removed, r.names[t])
+				}
+			}
+		}
+		if len(state.Components) > 0 || len(state.Removed) > 0 {
+			msg.Changed = append(msg.Changed, state)
+		}
+	}
+
+	for id := range o.known {
+		if _, ok := world[id]; !ok {
+			msg.Despawned = append(msg.Despawned, id)
+		}
+	}
+	sort.Strings(msg.Despawned)
+	return msg
+}
+
+// appendInterestEvents appends the entities that left and entered an
+// observer's view between two ticks.
+func appendInterestEvents(events []InterestEvent, observer string, prev, next view) []InterestEvent {
+	var left, entered []string
+	for id, ev := range prev {
+		if cur, ok := next[id]; !ok || cur.gen != ev.gen {
+			left = append(left, id)
+		}
+	}
+	for id, ev := range next {
+		if old, ok := prev[id]; !ok || old.gen != ev.gen {
+			entered = append(entered, id)
+		}
+	}
+	sort.Strings(left)
+	sort.Strings(entered)
+	for _, id := range left {
+		events = append(events, InterestEvent{Observer: observer, EntityId: id})
+	}
+	for _, id := range entered {
+		events = append(events, InterestEvent{Observer: observer, EntityId: id, Entered: true})
+	}
+	return events
+}
+
+func (o *observer) resync() {
+	o.acked = nil
+	o.pending = nil
+}
+
+func (o *observer) markSent(world view, seq uint64) {
+	for id, ev := range world {
+		k := o.known[id]
+		if k == nil || k.gen != ev.gen {
+			k = &knownEntity{gen: ev.gen, comps: make(map[ginka_ecs_go.ComponentType]uint64, len(ev.comps))}
+			o.known[id] = k
+		}
+		k.seq = seq
+		for t := range ev.comps {
+			k.comps[t] = seq
+		}
+	}
+}
+
+func (o *observer) ack(seq uint64) {
+	idx := -1
+	for i, p := range o.pending {
+		if p.seq == seq {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return
+	}
+	o.acked = o.pending[idx].view
+	o.ackedSeq = seq
+	o.pending = o.pending[idx+1:]
+
+	// Anything missing from the acknowledged view was removed by this
+	// message or an earlier one, unless it was sent again afterwards.
+	for id, k := range o.known {
+		ev := o.acked[id]
+		if ev == nil || ev.gen != k.gen {
+			if k.seq <= seq {
+				delete(o.known, id)
+			}
+			continue
+		}
+		for t, s := range k.comps {
+			if _, ok := ev.comps[t]; !ok && s <= seq {
+				delete(k.comps, t)
+			}
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+const (
+	testPositionType ginka_ecs_go.ComponentType = iota + 1
+	testHealthType
+	testSecretType
+)
+
+type testPosition struct {
+	ginka_ecs_go.DataComponentCore
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type testHealth struct {
+	ginka_ecs_go.DataComponentCore
+	HP int `json:"hp"`
+}
+
+type testSecret struct {
+	ginka_ecs_go.DataComponentCore
+	Token string `json:"token"`
+}
+
+func (c *testPosition) StorageKey() string { return "position" }
+func (c *testHealth) StorageKey() string   { return "health" }
+func (c *testSecret) StorageKey() string   { return "secret" }
+
+func newTestRegistry(t *testing.T) *ginka_ecs_go.ComponentRegistry {
+	t.Helper()
+	reg := ginka_ecs_go.NewComponentRegistry()
+	specs := []ginka_ecs_go.ComponentSpec{
+		{Name: "position", Type: testPositionType, New: func() ginka_ecs_go.Component {
+			return &testPosition{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testPositionType)}
+		}, Codec: ginka_ecs_go.BinaryCodec},
+		{Name: "health", Type: testHealthType, New: func() ginka_ecs_go.Component {
+			return &testHealth{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testHealthType)}
+		}},
+		{Name: "secret", Type: testSecretType, New: func() ginka_ecs_go.Component {
+			return &testSecret{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testSecretType)}
+		}},
+	}
+	for _, spec := range specs {
+		if err := reg.Register(spec); err != nil {
+			t.Fatalf("register %s: %v", spec.Name, err)
+		}
+	}
+	return reg
+}
+
+func newTestManager() *ginka_ecs_go.MapEntityManager[ginka_ecs_go.DataEntity] {
+	return ginka_ecs_go.NewEntityManager(func(id string, name string, typ ginka_ecs_go.EntityType, tags ...ginka_ecs_go.Tag) (ginka_ecs_go.DataEntity, error) {
+		return ginka_ecs_go.NewDataEntityCore(id, name, typ, tags...), nil
+	}, 4)
+}
+
+// memoryTransport queues messages per observer and can drop them.
+type memoryTransport struct {
+	mu    sync.Mutex
+	inbox map[string][]*Message
+	drop  bool
+}
+
+func newMemoryTransport() *memoryTransport {
+	return &memoryTransport{inbox: make(map[string][]*Message)}
+}
+
+func (tr *memoryTransport) Send(ctx context.Context, observer string, msg *Message) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.drop {
+		return nil
+	}
+	tr.inbox[observer] = append(tr.inbox[observer], msg)
+	return nil
+}
+
+func (tr *memoryTransport) take(observer string) []*Message {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	out := tr.inbox[observer]
+	tr.inbox[observer] = nil
+	return out
+}
+
+type harness struct {
+	t         *testing.T
+	ctx       context.Context
+	reg       *ginka_ecs_go.ComponentRegistry
+	m         *ginka_ecs_go.MapEntityManager[ginka_ecs_go.DataEntity]
+	transport *memoryTransport
+	rep       *Replicator[ginka_ecs_go.DataEntity]
+	client    *Client
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	h := &harness{t: t, ctx: context.Background(), reg: newTestRegistry(t), m: newTestManager(), transport: newMemoryTransport()}
+	h.rep = NewReplicator[ginka_ecs_go.DataEntity](h.m, h.reg, h.transport)
+	if err := h.rep.Replicate(testPositionType, testHealthType); err != nil {
+		t.Fatalf("replicate: %v", err)
+	}
+	if err := h.rep.AddObserver("a"); err != nil {
+		t.Fatalf("add observer: %v", err)
+	}
+	h.client = NewClient(h.reg)
+	return h
+}
+
+func (h *harness) spawn(id string, x int) {
+	h.t.Helper()
+	ent, err := h.m.Create(h.ctx, id, id, 1)
+	if err != nil {
+		h.t.Fatalf("create %s: %v", id, err)
+	}
+	_ = ent.Add(&testPosition{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testPositionType), X: x})
+	_ = ent.Add(&testSecret{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testSecretType), Token: "hidden"})
+}
+
+func (h *harness) move(id string, x int) {
+	h.t.Helper()
+	ent := h.m.MustGet(id)
+	pos, ok := ginka_ecs_go.GetForUpdate[*testPosition](ent, testPositionType)
+	if !ok {
+		h.t.Fatalf("%s has no position", id)
+	}
+	pos.X = x
+}
+
+// tick runs one replication tick and delivers the messages to the client.
+// It returns the last delivered message, if any.
+func (h *harness) tick(ack bool) *Message {
+	h.t.Helper()
+	if _, err := h.rep.Tick(h.ctx); err != nil {
+		h.t.Fatalf("tick: %v", err)
+	}
+	var last *Message
+	for _, msg := range h.transport.take("a") {
+		if _, err := h.client.Apply(msg); err != nil {
+			h.t.Fatalf("apply %d: %v", msg.Seq, err)
+		}
+		if ack {
+			_ = h.rep.Ack("a", msg.Seq)
+		}
+		last = msg
+	}
+	return last
+}
+
+// assertMirrored checks that the client holds exactly the replicated state.
+func (h *harness) assertMirrored() {
+	h.t.Helper()
+	want := make(map[string]map[ginka_ecs_go.ComponentType]uint64)
+	_ = h.m.ForEach(h.ctx, func(ent ginka_ecs_go.DataEntity) error {
+		for _, t := range []ginka_ecs_go.ComponentType{testPositionType, testHealthType} {
+			if c, ok := ent.Get(t); ok {
+				if want[ent.Id()] == nil {
+					want[ent.Id()] = make(map[ginka_ecs_go.ComponentType]uint64)
+				}
+				want[ent.Id()][t] = c.(ginka_ecs_go.DataComponent).Version()
+			}
+		}
+		return nil
+	})
+	got := make(map[string]map[ginka_ecs_go.ComponentType]uint64)
+	for _, id := range h.client.Ids() {
+		ent, _ := h.client.Entity(id)
+		got[id] = make(map[ginka_ecs_go.ComponentType]uint64)
+		for t, c := range ent.Components {
+			if t == testSecretType {
+				h.t.Fatalf("secret component replicated for %s", id)
+			}
+			got[id][t] = c.(ginka_ecs_go.DataComponent).Version()
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		h.t.Fatalf("client state %v, server state %v", got, want)
+	}
+}
+
+func TestReplicator_SpawnChangeDespawn(t *testing.T) {
+	h := newHarness(t)
+	h.spawn("e1", 1)
+	h.spawn("e2", 2)
+
+	msg := h.tick(true)
+	if msg == nil || !msg.Full || len(msg.Spawned) != 2 {
+		t.Fatalf("first message = %+v", msg)
+	}
+	h.assertMirrored()
+	pos, _ := h.client.Entity("e1")
+	if pos.Components[testPositionType].(*testPosition).X != 1 {
+		t.Fatalf("position not decoded: %+v", pos.Components[testPositionType])
+	}
+
+	if msg := h.tick(true); msg != nil {
+		t.Fatalf("expected no message without changes, got %+v", msg)
+	}
+
+	h.move("e1", 10)
+	msg = h.tick(true)
+	if msg == nil || msg.Full || len(msg.Changed) != 1 || msg.Changed[0].Id != "e1" || len(msg.Changed[0].Components) != 1 {
+		t.Fatalf("change message = %+v", msg)
+	}
+	h.assertMirrored()
+
+	// Adding and removing a component.
+	e2 := h.m.MustGet("e2")
+	_ = e2.Add(&testHealth{DataComponentCore: ginka_ecs_go.NewDataComponentCore(testHealthType), HP: 5})
+	h.tick(true)
+	h.assertMirrored()
+	e2.RemoveComponent(testHealthType)
+	msg = h.tick(true)
+	if msg == nil || len(msg.Changed) != 1 || !reflect.DeepEqual(msg.Changed[0].Removed, []string{"health"}) {
+		t.Fatalf("removal message = %+v", msg)
+	}
+	h.assertMirrored()
+
+	h.m.Remove("e2")
+	msg = h.tick(true)
+	if msg == nil || !reflect.DeepEqual(msg.Despawned, []string{"e2"}) {
+		t.Fatalf("despawn message = %+v", msg)
+	}
+	h.assertMirrored()
+}
+
+func TestReplicator_LostMessagesAreCoveredUntilAcked(t *testing.T) {
+	h := newHarness(t)
+	h.spawn("e1", 1)
+	h.tick(true)
+
+	// Neither message is acknowledged; the second is lost.
+	h.move("e1", 2)
+	h.spawn("e2", 0)
+	h.tick(false)
+	h.m.Remove("e2")
+	h.move("e1", 3)
+	h.transport.drop = true
+	h.tick(false)
+	h.transport.drop = false
+
+	h.move("e1", 4)
+	msg := h.tick(true)
+	if msg == nil || !reflect.DeepEqual(msg.Despawned, []string{"e2"}) {
+		t.Fatalf("expected e2 despawn to be repeated, got %+v", msg)
+	}
+	h.assertMirrored()
+	ent, _ := h.client.Entity("e1")
+	if ent.Components[testPositionType].(*testPosition).X != 4 {
+		t.Fatalf("client position = %+v", ent.Components[testPositionType])
+	}
+
+	// After the ack, e2 is forgotten.
+	if msg := h.tick(true); msg != nil {
+		t.Fatalf("expected nothing to send, got %+v", msg)
+	}
+}
+
+func TestReplicator_ResyncAfterMissingAcks(t *testing.T) {
+	h := newHarness(t)
+	h.rep.SetMaxPending(2)
+	h.spawn("e1", 1)
+	// e2 stays unchanged, so the resync must resend its acknowledged state.
+	h.spawn("e2", 9)
+	h.tick(true)
+
+	for x := 2; x <= 3; x++ {
+		h.move("e1", x)
+		if msg := h.tick(false); msg == nil || msg.Full {
+			t.Fatalf("expected delta, got %+v", msg)
+		}
+	}
+	h.move("e1", 4)
+	msg := h.tick(true)
+	if msg == nil || !msg.Full || len(msg.Spawned) != 2 {
+		t.Fatalf("expected full resync, got %+v", msg)
+	}
+	h.assertMirrored()
+
+	// Stale and duplicate messages are ignored by the client.
+	if applied, err := h.client.Apply(&Message{Seq: 1}); applied || err != nil {
+		t.Fatalf("stale message applied=%v err=%v", applied, err)
+	}
+}
+
+func TestReplicator_RecreatedIdIsRespawned(t *testing.T) {
+	h := newHarness(t)
+	h.spawn("e1", 1)
+	h.move("e1", 5)
+	h.tick(true)
+
+	h.m.Remove("e1")
+	h.spawn("e1", 7)
+	msg := h.tick(true)
+	if msg == nil || len(msg.Spawned) != 1 || msg.Spawned[0].Id != "e1" {
+		t.Fatalf("expected respawn, got %+v", msg)
+	}
+	h.assertMirrored()
+}
+
+func TestReplicator_Errors(t *testing.T) {
+	h := newHarness(t)
+	if err := h.rep.Replicate(99); !errors.Is(err, ErrNotReplicable) {
+		t.Fatalf("expected ErrNotReplicable, got %v", err)
+	}
+	if err := h.rep.AddObserver("a"); !errors.Is(err, ErrObserverExists) {
+		t.Fatalf("expected ErrObserverExists, got %v", err)
+	}
+	if err := h.rep.Ack("missing", 1); !errors.Is(err, ErrUnknownObserver) {
+		t.Fatalf("expected ErrUnknownObserver, got %v", err)
+	}
+	if !h.rep.RemoveObserver("a") || h.rep.RemoveObserver("a") {
+		t.Fatalf("unexpected RemoveObserver results")
+	}
+}
@@ -0,0 +1,314 @@
+package ginka_ecs_go
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Resources holds world-scoped singleton values keyed by their Go type
+// (season config, leaderboards, RNG seed, ...).
+//
+// Each resource has its own RWMutex, a version bumped on every write and,
+// when the value implements PersistentResource, a dirty flag cleared by
+// persistence in the same way as DataEntity.ClearDirty.
+type Resources struct {
+	mu      sync.RWMutex
+	entries map[reflect.Type]*resourceEntry
+}
+
+type resourceEntry struct {
+	mu      sync.RWMutex
+	value   any
+	version uint64
+	dirty   bool
+}
+
+// ResourceHolder is implemented by worlds that own Resources.
+// CoreWorld implements it, so business worlds embedding CoreWorld do too.
+type ResourceHolder interface {
+	Resources() *Resources
+}
+
+// PersistentResource is implemented by resources that should be persisted.
+// Only persistent resources are reported by DirtyResources.
+type PersistentResource interface {
+	// StorageKey is the storage mapping (table name, key prefix, etc).
+	StorageKey() string
+}
+
+// ResourceSnapshot is a dirty resource captured for persistence.
+type ResourceSnapshot struct {
+	Type       reflect.Type
+	StorageKey string
+	Value      any
+	Version    uint64
+}
+
+// NewResources creates an empty resource set.
+func NewResources() *Resources {
+	return &Resources{entries: make(map[reflect.Type]*resourceEntry)}
+}
+
+// ResourceTypeOf returns the key used for resources of type T.
+func ResourceTypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// SetResource stores v as the resource of type T, bumping its version and marking it dirty.
+func SetResource[T any](w ResourceHolder, v T) {
+	entry := w.Resources().entry(ResourceTypeOf[T](), true)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	entry.value = v
+	entry.touchUnlocked()
+}
+
+// LoadResource stores v with the given version without marking it dirty.
+// Use it when hydrating resources from storage.
+func LoadResource[T any](w ResourceHolder, v T, version uint64) {
+	entry := w.Resources().entry(ResourceTypeOf[T](), true)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	entry.value = v
+	entry.version = version
+	entry.dirty = false
+}
+
+// RestoreResource decodes rec into a T and loads it with rec.Version
+// without marking it dirty. It is the inverse of FlushResources.
+func RestoreResource[T any](w ResourceHolder, rec ResourceRecord) error {
+	var v T
+	if _, err := DecodePayload(rec.Payload, &v); err != nil {
+		return fmt.Errorf("restore resource %s: %w", rec.StorageKey, err)
+	}
+	LoadResource(w, v, rec.Version)
+	return nil
+}
+
+// Resource returns a copy of the resource of type T. Maps, slices and
+// pointers inside the copy are shared with the stored value, so resources
+// holding them must only be accessed through ReadResource and
+// UpdateResource.
+func Resource[T any](w ResourceHolder) (T, bool) {
+	var zero T
+	entry := w.Resources().entry(ResourceTypeOf[T](), false)
+	if entry == nil {
+		return zero, false
+	}
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+	if entry.value == nil {
+		return zero, false
+	}
+	v, ok := entry.value.(T)
+	return v, ok
+}
+
+// MustResource returns a copy of the resource of type T, panicking if
+// missing. Like Resource, it is only safe for values without references.
+func MustResource[T any](w ResourceHolder) T {
+	v, ok := Resource[T](w)
+	if !ok {
+		panic(fmt.Errorf("must resource %s: %w", ResourceTypeOf[T](), ErrResourceNotFound))
+	}
+	return v
+}
+
+// ReadResource runs fn with the resource of type T under its read lock.
+func ReadResource[T any](w ResourceHolder, fn func(v T) error) error {
+	entry := w.Resources().entry(ResourceTypeOf[T](), false)
+	if entry == nil {
+		return fmt.Errorf("read resource %s: %w", ResourceTypeOf[T](), ErrResourceNotFound)
+	}
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+	v, ok := entry.value.(T)
+	if !ok {
+		return fmt.Errorf("read resource %s: %w", ResourceTypeOf[T](), ErrResourceNotFound)
+	}
+	return fn(v)
+}
+
+// UpdateResource runs fn with a pointer to the resource of type T under its write lock.
+// The version is bumped and the resource marked dirty unless fn returns an error.
+func UpdateResource[T any](w ResourceHolder, fn func(v *T) error) error {
+	entry := w.Resources().entry(ResourceTypeOf[T](), false)
+	if entry == nil {
+		return fmt.Errorf("update resource %s: %w", ResourceTypeOf[T](), ErrResourceNotFound)
+	}
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	v, ok := entry.value.(T)
+	if !ok {
+		return fmt.Errorf("update resource %s: %w", ResourceTypeOf[T](), ErrResourceNotFound)
+	}
+	if err := fn(&v); err != nil {
+		return err
+	}
+	entry.value = v
+	entry.touchUnlocked()
+	return nil
+}
+
+// RemoveResource deletes the resource of type T, returning true if it existed.
+func RemoveResource[T any](w ResourceHolder) bool {
+	r := w.Resources()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := ResourceTypeOf[T]()
+	_, ok := r.entries[t]
+	delete(r.entries, t)
+	return ok
+}
+
+// ResourceVersion returns the current version of the resource of type T.
+func ResourceVersion[T any](w ResourceHolder) (uint64, bool) {
+	entry := w.Resources().entry(ResourceTypeOf[T](), false)
+	if entry == nil {
+		return 0, false
+	}
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+	return entry.version, true
+}
+
+// Has reports whether a resource of type t exists.
+func (r *Resources) Has(t reflect.Type) bool {
+	return r.entry(t, false) != nil
+}
+
+// Types returns the stored resource types sorted by name.
+func (r *Resources) Types() []reflect.Type {
+	r.mu.RLock()
+	out := make([]reflect.Type, 0, len(r.entries))
+	for t := range r.entries {
+		out = append(out, t)
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].String() < out[j].String()
+	})
+	return out
+}
+
+// DirtyResources returns snapshots of dirty persistent resources sorted by storage key.
+// Value is the stored value itself, not a copy; marshal it before releasing control.
+// FlushResources does this under the resource lock and is preferred.
+func (r *Resources) DirtyResources() []ResourceSnapshot {
+	var out []ResourceSnapshot
+	for _, t := range r.Types() {
+		entry := r.entry(t, false)
+		if entry == nil {
+			continue
+		}
+		entry.mu.RLock()
+		if entry.dirty {
+			if p, ok := persistentResource(entry.value); ok {
+				out = append(out, ResourceSnapshot{
+					Type:       t,
+					StorageKey: p.StorageKey(),
+					Value:      entry.value,
+					Version:    entry.version,
+				})
+			}
+		}
+		entry.mu.RUnlock()
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].StorageKey < out[j].StorageKey
+	})
+	return out
+}
+
+type dirtyResourceRecord struct {
+	ResourceRecord
+	typ reflect.Type
+}
+
+// encodeDirty encodes the dirty persistent resources, each under its read
+// lock, sorted by storage key.
+func (r *Resources) encodeDirty() ([]dirtyResourceRecord, error) {
+	var out []dirtyResourceRecord
+	for _, t := range r.Types() {
+		entry := r.entry(t, false)
+		if entry == nil {
+			continue
+		}
+		entry.mu.RLock()
+		p, ok := persistentResource(entry.value)
+		if !entry.dirty || !ok {
+			entry.mu.RUnlock()
+			continue
+		}
+		payload, err := EncodePayload(JSONCodec, entry.value)
+		rec := ResourceRecord{StorageKey: p.StorageKey(), Version: entry.version, Payload: payload}
+		entry.mu.RUnlock()
+		if err != nil {
+			return nil, fmt.Errorf("encode resource %s: %w", t, err)
+		}
+		out = append(out, dirtyResourceRecord{ResourceRecord: rec, typ: t})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].StorageKey < out[j].StorageKey
+	})
+	return out, nil
+}
+
+// ClearDirty clears the dirty flag of resource t if its version still equals version.
+// Returns true if the flag was cleared.
+func (r *Resources) ClearDirty(t reflect.Type, version uint64) bool {
+	entry := r.entry(t, false)
+	if entry == nil {
+		return false
+	}
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.version != version {
+		return false
+	}
+	entry.dirty = false
+	return true
+}
+
+func (r *Resources) entry(t reflect.Type, create bool) *resourceEntry {
+	r.mu.RLock()
+	entry := r.entries[t]
+	r.mu.RUnlock()
+	if entry != nil || !create {
+		return entry
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.entries == nil {
+		r.entries = make(map[reflect.Type]*resourceEntry)
+	}
+	if entry = r.entries[t]; entry == nil {
+		entry = &resourceEntry{}
+		r.entries[t] = entry
+	}
+	return entry
+}
+
+func (e *resourceEntry) touchUnlocked() {
+	e.version++
+	if _, ok := persistentResource(e.value); ok {
+		e.dirty = true
+	}
+}
+
+func persistentResource(v any) (PersistentResource, bool) {
+	if p, ok := v.(PersistentResource); ok {
+		return p, true
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || rv.Kind() == reflect.Pointer {
+		return nil, false
+	}
+	ptr := reflect.New(rv.Type())
+	ptr.Elem().Set(rv)
+	p, ok := ptr.Interface().(PersistentResource)
+	return p, ok
+}
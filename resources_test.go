@@ -0,0 +1,179 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testSeasonConfig struct {
+	Season int
+}
+
+func (c testSeasonConfig) StorageKey() string {
+	return "season"
+}
+
+type testRngSeed uint64
+
+type testResourceSystem struct {
+	name   string
+	access ResourceAccess
+}
+
+func (s *testResourceSystem) Name() string {
+	return s.name
+}
+
+func (s *testResourceSystem) ResourceAccess() ResourceAccess {
+	return s.access
+}
+
+func TestResources_SetGetUpdate(t *testing.T) {
+	w := NewCoreWorld("test")
+	if _, ok := Resource[testSeasonConfig](w); ok {
+		t.Fatalf("expected missing resource")
+	}
+	SetResource(w, testSeasonConfig{Season: 1})
+	SetResource(w, testRngSeed(42))
+
+	if got := MustResource[testRngSeed](w); got != 42 {
+		t.Fatalf("seed = %d", got)
+	}
+	err := UpdateResource(w, func(c *testSeasonConfig) error {
+		c.Season++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	cfg, ok := Resource[testSeasonConfig](w)
+	if !ok || cfg.Season != 2 {
+		t.Fatalf("season = %+v, %v", cfg, ok)
+	}
+	if v, _ := ResourceVersion[testSeasonConfig](w); v != 2 {
+		t.Fatalf("version = %d", v)
+	}
+	if err := UpdateResource(w, func(*struct{}) error { return nil }); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("expected ErrResourceNotFound, got %v", err)
+	}
+}
+
+func TestResources_DirtyTracking(t *testing.T) {
+	w := NewCoreWorld("test")
+	SetResource(w, testSeasonConfig{Season: 1})
+	SetResource(w, testRngSeed(7))
+
+	dirty := w.Resources().DirtyResources()
+	if len(dirty) != 1 || dirty[0].StorageKey != "season" {
+		t.Fatalf("unexpected dirty resources: %+v", dirty)
+	}
+	snapshot := dirty[0]
+
+	_ = UpdateResource(w, func(c *testSeasonConfig) error {
+		c.Season = 3
+		return nil
+	})
+	if w.Resources().ClearDirty(snapshot.Type, snapshot.Version) {
+		t.Fatalf("clear should fail after a newer write")
+	}
+	dirty = w.Resources().DirtyResources()
+	if !w.Resources().ClearDirty(dirty[0].Type, dirty[0].Version) {
+		t.Fatalf("clear should succeed for the current version")
+	}
+	if len(w.Resources().DirtyResources()) != 0 {
+		t.Fatalf("expected no dirty resources")
+	}
+
+	LoadResource(w, testSeasonConfig{Season: 9}, 20)
+	if len(w.Resources().DirtyResources()) != 0 {
+		t.Fatalf("loaded resources should not be dirty")
+	}
+}
+
+func TestScheduleBatches(t *testing.T) {
+	season := ResourceTypeOf[testSeasonConfig]()
+	seed := ResourceTypeOf[testRngSeed]()
+	readSeason := &testResourceSystem{name: "read-season", access: ResourceAccess{Reads: []reflect.Type{season}}}
+	readBoth := &testResourceSystem{name: "read-both", access: ResourceAccess{Reads: []reflect.Type{season, seed}}}
+	writeSeason := &testResourceSystem{name: "write-season", access: ResourceAccess{Writes: []reflect.Type{season}}}
+	writeSeed := &testResourceSystem{name: "write-seed", access: ResourceAccess{Writes: []reflect.Type{seed}}}
+
+	batches := ScheduleBatches(readSeason, readBoth, writeSeason, writeSeed)
+	var names [][]string
+	for _, batch := range batches {
+		var row []string
+		for _, sys := range batch {
+			row = append(row, sys.Name())
+		}
+		names = append(names, row)
+	}
+	want := [][]string{{"read-season", "read-both"}, {"write-season", "write-seed"}}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("batches = %v, want %v", names, want)
+	}
+
+	w := NewCoreWorld("test")
+	SetResource(w, testSeasonConfig{})
+	if err := CheckSystemResources(w, readSeason, readBoth); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("expected missing seed, got %v", err)
+	}
+}
+
+type testResourceStore struct {
+	records []ResourceRecord
+	err     error
+}
+
+func (s *testResourceStore) SaveResource(_ context.Context, rec ResourceRecord) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func TestFlushResources(t *testing.T) {
+	ctx := context.Background()
+	w := NewCoreWorld("test")
+	SetResource(w, testSeasonConfig{Season: 4})
+	SetResource(w, testRngSeed(7))
+
+	failing := &testResourceStore{err: errors.New("disk full")}
+	if _, err := FlushResources(ctx, w, failing); err == nil {
+		t.Fatalf("expected the store error")
+	}
+	if len(w.Resources().DirtyResources()) != 1 {
+		t.Fatalf("a failed save must leave the resource dirty")
+	}
+
+	store := &testResourceStore{}
+	n, err := FlushResources(ctx, w, store)
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if n != 1 || len(store.records) != 1 || store.records[0].StorageKey != "season" || store.records[0].Version != 1 {
+		t.Fatalf("unexpected records: n=%d %+v", n, store.records)
+	}
+	if len(w.Resources().DirtyResources()) != 0 {
+		t.Fatalf("expected no dirty resources after flush")
+	}
+	if n, _ := FlushResources(ctx, w, store); n != 0 {
+		t.Fatalf("clean resources should not be written again, wrote %d", n)
+	}
+
+	loaded := NewCoreWorld("loaded")
+	if err := RestoreResource[testSeasonConfig](loaded, store.records[0]); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if got := MustResource[testSeasonConfig](loaded); got.Season != 4 {
+		t.Fatalf("restored %+v", got)
+	}
+	if v, _ := ResourceVersion[testSeasonConfig](loaded); v != 1 {
+		t.Fatalf("restored version = %d", v)
+	}
+	if len(loaded.Resources().DirtyResources()) != 0 {
+		t.Fatalf("restored resources should not be dirty")
+	}
+}
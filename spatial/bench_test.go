@@ -0,0 +1,105 @@
+package spatial
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+const benchWorldSize = 2000
+
+func newBenchIndex(b *testing.B, n int, backend Backend) (*ginka_ecs_go.MapEntityManager[ginka_ecs_go.DataEntity], *Index[ginka_ecs_go.DataEntity]) {
+	b.Helper()
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(1))
+	m := newTestManager()
+	idx := NewIndex[ginka_ecs_go.DataEntity](m, backend)
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("e%d", i)
+		ent, err := m.Create(ctx, id, id, 1)
+		if err != nil {
+			b.Fatalf("create: %v", err)
+		}
+		if err := idx.SetPosition(ent, rng.Float64()*benchWorldSize, rng.Float64()*benchWorldSize); err != nil {
+			b.Fatalf("set position: %v", err)
+		}
+	}
+	return m, idx
+}
+
+func benchBackends() map[string]func() Backend {
+	return map[string]func() Backend{
+		"grid":     func() Backend { return NewGrid(50) },
+		"quadtree": func() Backend { return NewQuadtree(Rect{MaxX: benchWorldSize, MaxY: benchWorldSize}, DefaultMaxItems) },
+	}
+}
+
+func BenchmarkInRadius(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		for name, newBackend := range benchBackends() {
+			b.Run(fmt.Sprintf("%s/%d", name, n), func(b *testing.B) {
+				_, idx := newBenchIndex(b, n, newBackend())
+				rng := rand.New(rand.NewSource(2))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					idx.InRadius(Point{X: rng.Float64() * benchWorldSize, Y: rng.Float64() * benchWorldSize}, 50)
+				}
+			})
+		}
+		b.Run(fmt.Sprintf("scan/%d", n), func(b *testing.B) {
+			m, _ := newBenchIndex(b, n, nil)
+			rng := rand.New(rand.NewSource(2))
+			ctx := context.Background()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				center := Point{X: rng.Float64() * benchWorldSize, Y: rng.Float64() * benchWorldSize}
+				var found []ginka_ecs_go.DataEntity
+				_ = m.ForEach(ctx, func(ent ginka_ecs_go.DataEntity) error {
+					if pos, ok := ginka_ecs_go.Get[*Position](ent, PositionType); ok && pos.Point().DistSq(center) <= 50*50 {
+						found = append(found, ent)
+					}
+					return nil
+				})
+			}
+		})
+	}
+}
+
+func BenchmarkNearest(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		for name, newBackend := range benchBackends() {
+			b.Run(fmt.Sprintf("%s/%d", name, n), func(b *testing.B) {
+				_, idx := newBenchIndex(b, n, newBackend())
+				rng := rand.New(rand.NewSource(2))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					idx.Nearest(Point{X: rng.Float64() * benchWorldSize, Y: rng.Float64() * benchWorldSize}, 8)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkSetPosition(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		for name, newBackend := range benchBackends() {
+			b.Run(fmt.Sprintf("%s/%d", name, n), func(b *testing.B) {
+				m, idx := newBenchIndex(b, n, newBackend())
+				rng := rand.New(rand.NewSource(2))
+				ents := make([]ginka_ecs_go.DataEntity, 0, n)
+				_ = m.ForEach(context.Background(), func(ent ginka_ecs_go.DataEntity) error {
+					ents = append(ents, ent)
+					return nil
+				})
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					ent := ents[i%len(ents)]
+					_ = idx.SetPosition(ent, rng.Float64()*benchWorldSize, rng.Float64()*benchWorldSize)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package spatial
+
+import "math"
+
+// Point is a position on the plane.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Item is an indexed entity id and its position.
+type Item struct {
+	Id    string
+	Point Point
+}
+
+// Rect is an axis-aligned rectangle. Both edges are inclusive.
+type Rect struct {
+	MinX float64 `json:"min_x"`
+	MinY float64 `json:"min_y"`
+	MaxX float64 `json:"max_x"`
+	MaxY float64 `json:"max_y"`
+}
+
+// RectAround returns the square of half-width r centered on p.
+func RectAround(p Point, r float64) Rect {
+	return Rect{MinX: p.X - r, MinY: p.Y - r, MaxX: p.X + r, MaxY: p.Y + r}
+}
+
+// Contains reports whether p lies inside r.
+func (r Rect) Contains(p Point) bool {
+	return p.X >= r.MinX && p.X <= r.MaxX && p.Y >= r.MinY && p.Y <= r.MaxY
+}
+
+// Intersects reports whether r and o overlap.
+func (r Rect) Intersects(o Rect) bool {
+	return r.MinX <= o.MaxX && o.MinX <= r.MaxX && r.MinY <= o.MaxY && o.MinY <= r.MaxY
+}
+
+// distSq returns the squared distance from p to the nearest point of r.
+func (r Rect) distSq(p Point) float64 {
+	dx := math.Max(0, math.Max(r.MinX-p.X, p.X-r.MaxX))
+	dy := math.Max(0, math.Max(r.MinY-p.Y, p.Y-r.MaxY))
+	return dx*dx + dy*dy
+}
+
+// DistSq returns the squared distance between p and o.
+func (p Point) DistSq(o Point) float64 {
+	dx, dy := p.X-o.X, p.Y-o.Y
+	return dx*dx + dy*dy
+}
+
+// Dist returns the distance between p and o.
+func (p Point) Dist(o Point) float64 {
+	return math.Sqrt(p.DistSq(o))
+}
@@ -0,0 +1,182 @@
+package spatial
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultCellSize is the cell size of the grid used when NewIndex is given no backend.
+const DefaultCellSize = 64
+
+// Backend stores indexed points. Implementations need not be safe for
+// concurrent use; Index serializes access.
+type Backend interface {
+	// Insert adds id at p. The id is not yet indexed.
+	Insert(id string, p Point)
+	// Remove deletes id, which was inserted at p.
+	Remove(id string, p Point)
+	// Search calls fn for every item inside r until fn returns false.
+	Search(r Rect, fn func(it Item) bool)
+	// Nearest returns up to k items closest to p, nearest first.
+	Nearest(p Point, k int) []Item
+	// Len returns the number of indexed items.
+	Len() int
+}
+
+type cellKey struct {
+	x, y int
+}
+
+// Grid is a uniform grid backend. It suits worlds where entities are
+// spread fairly evenly and queries have a radius close to the cell size.
+type Grid struct {
+	cellSize float64
+	cells    map[cellKey]map[string]Point
+	n        int
+	// min and max bound every cell that ever held an item.
+	min, max cellKey
+}
+
+// NewGrid creates a Grid with square cells of cellSize.
+// Values below or equal to zero use DefaultCellSize.
+func NewGrid(cellSize float64) *Grid {
+	if cellSize <= 0 {
+		cellSize = DefaultCellSize
+	}
+	return &Grid{cellSize: cellSize, cells: make(map[cellKey]map[string]Point)}
+}
+
+func (g *Grid) cellOf(p Point) cellKey {
+	return cellKey{x: int(math.Floor(p.X / g.cellSize)), y: int(math.Floor(p.Y / g.cellSize))}
+}
+
+// Insert implements Backend.
+func (g *Grid) Insert(id string, p Point) {
+	key := g.cellOf(p)
+	cell := g.cells[key]
+	if cell == nil {
+		cell = make(map[string]Point)
+		g.cells[key] = cell
+		if g.n == 0 && len(g.cells) == 1 {
+			g.min, g.max = key, key
+		} else {
+			g.min = cellKey{x: min(g.min.x, key.x), y: min(g.min.y, key.y)}
+			g.max = cellKey{x: max(g.max.x, key.x), y: max(g.max.y, key.y)}
+		}
+	}
+	cell[id] = p
+	g.n++
+}
+
+// Remove implements Backend.
+func (g *Grid) Remove(id string, p Point) {
+	key := g.cellOf(p)
+	cell := g.cells[key]
+	if _, ok := cell[id]; !ok {
+		return
+	}
+	delete(cell, id)
+	g.n--
+	if len(cell) == 0 {
+		delete(g.cells, key)
+	}
+}
+
+// Search implements Backend.
+func (g *Grid) Search(r Rect, fn func(it Item) bool) {
+	lo := g.cellOf(Point{X: r.MinX, Y: r.MinY})
+	hi := g.cellOf(Point{X: r.MaxX, Y: r.MaxY})
+	span := float64(hi.x-lo.x+1) * float64(hi.y-lo.y+1)
+	if span > float64(len(g.cells)) {
+		// Sparse grid: visiting the occupied cells is cheaper.
+		for key, cell := range g.cells {
+			if key.x < lo.x || key.x > hi.x || key.y < lo.y || key.y > hi.y {
+				continue
+			}
+			if !searchCell(cell, r, fn) {
+				return
+			}
+		}
+		return
+	}
+	for x := lo.x; x <= hi.x; x++ {
+		for y := lo.y; y <= hi.y; y++ {
+			if !searchCell(g.cells[cellKey{x: x, y: y}], r, fn) {
+				return
+			}
+		}
+	}
+}
+
+func searchCell(cell map[string]Point, r Rect, fn func(it Item) bool) bool {
+	for id, p := range cell {
+		if r.Contains(p) && !fn(Item{Id: id, Point: p}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Nearest implements Backend by scanning rings of cells around p until no
+// unvisited cell can hold a closer item.
+func (g *Grid) Nearest(p Point, k int) []Item {
+	if k <= 0 || g.n == 0 {
+		return nil
+	}
+	center := g.cellOf(p)
+	maxRing := max(center.x-g.min.x, g.max.x-center.x, center.y-g.min.y, g.max.y-center.y)
+
+	var found []Item
+	visit := func(key cellKey) {
+		for id, q := range g.cells[key] {
+			found = append(found, Item{Id: id, Point: q})
+		}
+	}
+	for ring := 0; ring <= maxRing; ring++ {
+		if ring == 0 {
+			visit(center)
+		} else {
+			for d := -ring; d <= ring; d++ {
+				visit(cellKey{x: center.x + d, y: center.y - ring})
+				visit(cellKey{x: center.x + d, y: center.y + ring})
+			}
+			for d := -ring + 1; d <= ring-1; d++ {
+				visit(cellKey{x: center.x - ring, y: center.y + d})
+				visit(cellKey{x: center.x + ring, y: center.y + d})
+			}
+		}
+		if len(found) < k {
+			continue
+		}
+		sortByDistance(found, p)
+		found = found[:k]
+		// Unvisited cells are at least ring cells away.
+		reach := float64(ring) * g.cellSize
+		if found[k-1].Point.DistSq(p) <= reach*reach {
+			return found
+		}
+	}
+	sortByDistance(found, p)
+	if len(found) > k {
+		found = found[:k]
+	}
+	return found
+}
+
+// Len implements Backend.
+func (g *Grid) Len() int {
+	return g.n
+}
+
+// sortByDistance orders items by distance to p, then by id.
+func sortByDistance(items []Item, p Point) {
+	sort.Slice(items, func(i, j int) bool {
+		di, dj := items[i].Point.DistSq(p), items[j].Point.DistSq(p)
+		if di != dj {
+			return di < dj
+		}
+		return items[i].Id < items[j].Id
+	})
+}
+
+var _ Backend = (*Grid)(nil)
@@ -0,0 +1,229 @@
+// Package spatial indexes entity positions for range and nearest-neighbour
+// queries.
+//
+// Entities carry the built-in Position component. An Index keeps their
+// positions in a Backend (a uniform Grid or a Quadtree) and answers InRadius,
+// InRect and Nearest with entities from the EntityManager, so systems no
+// longer scan every entity per frame.
+package spatial
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// Index maintains the positions of the entities in an EntityManager.
+//
+// SetPosition moves an entity and updates the index in one step. Positions
+// written elsewhere (GetForUpdate, loaders, prefabs) are picked up by Sync,
+// which visits only entities whose Position changed since the previous call.
+// Removed entities leave the index automatically.
+type Index[T ginka_ecs_go.DataEntity] struct {
+	entities ginka_ecs_go.EntityManager[T]
+
+	mu      sync.RWMutex
+	backend Backend
+	points  map[string]Point
+
+	cursor ginka_ecs_go.ChangeCursor
+	detach func()
+}
+
+// NewIndex creates an Index over m stored in backend.
+// Nil uses a Grid with DefaultCellSize. Call Close to detach it from m.
+func NewIndex[T ginka_ecs_go.DataEntity](m ginka_ecs_go.EntityManager[T], backend Backend) *Index[T] {
+	if backend == nil {
+		backend = NewGrid(DefaultCellSize)
+	}
+	idx := &Index[T]{
+		entities: m,
+		backend:  backend,
+		points:   make(map[string]Point),
+	}
+	idx.detach = m.OnRemove(func(ent T) {
+		idx.Remove(ent.Id())
+	})
+	return idx
+}
+
+// Close stops tracking entity removals.
+func (idx *Index[T]) Close() {
+	idx.detach()
+}
+
+// SetPosition moves ent to (x, y), adding a Position component if it has none,
+// and updates the index. The component is written and marked dirty in one
+// transaction, so a concurrent flush never sees the move half done.
+func (idx *Index[T]) SetPosition(ent T, x, y float64) error {
+	err := ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+		if !tx.Has(PositionType) {
+			if err := tx.Add(NewPosition(x, y)); err != nil {
+				return err
+			}
+		}
+		c, ok := tx.GetForUpdate(PositionType)
+		if !ok {
+			return fmt.Errorf("component %d: %w", PositionType, ginka_ecs_go.ErrComponentNotFound)
+		}
+		pos, ok := c.(*Position)
+		if !ok {
+			return fmt.Errorf("component %d is %T, not *Position", PositionType, c)
+		}
+		pos.X, pos.Y = x, y
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("set position of %s: %w", ent.Id(), err)
+	}
+	idx.Update(ent.Id(), Point{X: x, Y: y})
+	return nil
+}
+
+// Update records that the entity with id is at p without touching its component.
+func (idx *Index[T]) Update(id string, p Point) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.updateLocked(id, p)
+}
+
+func (idx *Index[T]) updateLocked(id string, p Point) {
+	if old, ok := idx.points[id]; ok {
+		if old == p {
+			return
+		}
+		idx.backend.Remove(id, old)
+	}
+	idx.points[id] = p
+	idx.backend.Insert(id, p)
+}
+
+// Remove drops the entity with id from the index, returning true if it was indexed.
+func (idx *Index[T]) Remove(id string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	p, ok := idx.points[id]
+	if !ok {
+		return false
+	}
+	delete(idx.points, id)
+	idx.backend.Remove(id, p)
+	return true
+}
+
+// Sync indexes Position components changed since the previous Sync and drops
+// entities that no longer have one. It returns the number of entries updated.
+func (idx *Index[T]) Sync(ctx context.Context) (int, error) {
+	since := idx.cursor.Begin()
+	changed := ginka_ecs_go.Changed(PositionType, since)
+
+	type update struct {
+		id  string
+		p   Point
+		del bool
+	}
+	var updates []update
+	err := idx.entities.ForEach(ctx, func(ent T) error {
+		return ent.Tx(func(tx ginka_ecs_go.DataEntity) error {
+			c, ok := tx.Get(PositionType)
+			if !ok {
+				if _, indexed := idx.Position(tx.Id()); indexed {
+					updates = append(updates, update{id: tx.Id(), del: true})
+				}
+				return nil
+			}
+			pos, ok := c.(*Position)
+			if !ok || !changed(tx) {
+				return nil
+			}
+			updates = append(updates, update{id: tx.Id(), p: pos.Point()})
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	var n int
+	for _, u := range updates {
+		old, indexed := idx.points[u.id]
+		switch {
+		case u.del && indexed:
+			delete(idx.points, u.id)
+			idx.backend.Remove(u.id, old)
+			n++
+		case !u.del && (!indexed || old != u.p):
+			idx.updateLocked(u.id, u.p)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Position returns the indexed position of the entity with id.
+func (idx *Index[T]) Position(id string) (Point, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	p, ok := idx.points[id]
+	return p, ok
+}
+
+// Len returns the number of indexed entities.
+func (idx *Index[T]) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.points)
+}
+
+// InRadius returns the entities within r of center, nearest first.
+func (idx *Index[T]) InRadius(center Point, r float64) []T {
+	rsq := r * r
+	var items []Item
+	idx.mu.RLock()
+	idx.backend.Search(RectAround(center, r), func(it Item) bool {
+		if it.Point.DistSq(center) <= rsq {
+			items = append(items, it)
+		}
+		return true
+	})
+	idx.mu.RUnlock()
+	sortByDistance(items, center)
+	return idx.resolve(items)
+}
+
+// InRect returns the entities inside rect, ordered by id.
+func (idx *Index[T]) InRect(rect Rect) []T {
+	var items []Item
+	idx.mu.RLock()
+	idx.backend.Search(rect, func(it Item) bool {
+		items = append(items, it)
+		return true
+	})
+	idx.mu.RUnlock()
+	sort.Slice(items, func(i, j int) bool { return items[i].Id < items[j].Id })
+	return idx.resolve(items)
+}
+
+// Nearest returns up to k entities closest to p, nearest first.
+func (idx *Index[T]) Nearest(p Point, k int) []T {
+	idx.mu.RLock()
+	items := idx.backend.Nearest(p, k)
+	idx.mu.RUnlock()
+	return idx.resolve(items)
+}
+
+// resolve looks up entities by id, skipping those removed since the query.
+func (idx *Index[T]) resolve(items []Item) []T {
+	out := make([]T, 0, len(items))
+	for _, it := range items {
+		if ent, ok := idx.entities.Get(it.Id); ok {
+			out = append(out, ent)
+		}
+	}
+	return out
+}
@@ -0,0 +1,254 @@
+package spatial
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+	"github.com/Shigure42/ginka-ecs-go/ecstest"
+)
+
+func newTestManager() *ginka_ecs_go.MapEntityManager[ginka_ecs_go.DataEntity] {
+	return ginka_ecs_go.NewEntityManager(func(id string, name string, typ ginka_ecs_go.EntityType, tags ...ginka_ecs_go.Tag) (ginka_ecs_go.DataEntity, error) {
+		return ginka_ecs_go.NewDataEntityCore(id, name, typ, tags...), nil
+	}, 16)
+}
+
+func ids(ents []ginka_ecs_go.DataEntity) []string {
+	out := make([]string, 0, len(ents))
+	for _, ent := range ents {
+		out = append(out, ent.Id())
+	}
+	return out
+}
+
+func backends() map[string]func() Backend {
+	return map[string]func() Backend{
+		"grid":     func() Backend { return NewGrid(10) },
+		"quadtree": func() Backend { return NewQuadtree(Rect{MaxX: 100, MaxY: 100}, 4) },
+	}
+}
+
+// TestIndex_MatchesBruteForce compares queries with a linear scan while
+// entities are added, moved and removed, including points outside the
+// quadtree bounds.
+func TestIndex_MatchesBruteForce(t *testing.T) {
+	ctx := context.Background()
+	for name, newBackend := range backends() {
+		t.Run(name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			m := newTestManager()
+			idx := NewIndex[ginka_ecs_go.DataEntity](m, newBackend())
+			defer idx.Close()
+
+			points := make(map[string]Point)
+			randomPoint := func() Point {
+				return Point{X: rng.Float64()*140 - 20, Y: rng.Float64()*140 - 20}
+			}
+			for i := 0; i < 300; i++ {
+				id := fmt.Sprintf("e%03d", i)
+				ent, err := m.Create(ctx, id, id, 1)
+				if err != nil {
+					t.Fatalf("create: %v", err)
+				}
+				p := randomPoint()
+				if err := idx.SetPosition(ent, p.X, p.Y); err != nil {
+					t.Fatalf("set position: %v", err)
+				}
+				points[id] = p
+			}
+			for round := 0; round < 5; round++ {
+				for id := range points {
+					switch rng.Intn(4) {
+					case 0:
+						p := randomPoint()
+						_ = idx.SetPosition(m.MustGet(id), p.X, p.Y)
+						points[id] = p
+					case 1:
+						if rng.Intn(10) == 0 {
+							m.Remove(id)
+							delete(points, id)
+						}
+					}
+				}
+				if idx.Len() != len(points) {
+					t.Fatalf("len = %d, want %d", idx.Len(), len(points))
+				}
+
+				center, r := randomPoint(), rng.Float64()*30
+				var wantRadius []Item
+				var wantRect []string
+				rect := Rect{MinX: center.X - 15, MinY: center.Y - 5, MaxX: center.X + 15, MaxY: center.Y + 25}
+				var all []Item
+				for id, p := range points {
+					all = append(all, Item{Id: id, Point: p})
+					if p.Dist(center) <= r {
+						wantRadius = append(wantRadius, Item{Id: id, Point: p})
+					}
+					if rect.Contains(p) {
+						wantRect = append(wantRect, id)
+					}
+				}
+				sortByDistance(wantRadius, center)
+				sort.Strings(wantRect)
+				sortByDistance(all, center)
+
+				if got, want := ids(idx.InRadius(center, r)), itemIds(wantRadius); !reflect.DeepEqual(got, want) {
+					t.Fatalf("InRadius = %v, want %v", got, want)
+				}
+				if got := ids(idx.InRect(rect)); !reflect.DeepEqual(got, nilIfEmpty(wantRect)) {
+					t.Fatalf("InRect = %v, want %v", got, wantRect)
+				}
+				for _, k := range []int{1, 7, len(all) + 5} {
+					want := all
+					if k < len(all) {
+						want = all[:k]
+					}
+					if got := ids(idx.Nearest(center, k)); !reflect.DeepEqual(got, itemIds(want)) {
+						t.Fatalf("Nearest(%d) = %v, want %v", k, got, itemIds(want))
+					}
+				}
+			}
+		})
+	}
+}
+
+func itemIds(items []Item) []string {
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		out = append(out, it.Id)
+	}
+	return out
+}
+
+func nilIfEmpty(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func TestIndex_SyncPicksUpExternalChanges(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager()
+	idx := NewIndex[ginka_ecs_go.DataEntity](m, nil)
+	defer idx.Close()
+
+	a, _ := m.Create(ctx, "a", "a", 1)
+	b, _ := m.Create(ctx, "b", "b", 1)
+	_ = a.Add(NewPosition(1, 1))
+	_ = b.Add(NewPosition(500, 500))
+	if n, err := idx.Sync(ctx); err != nil || n != 2 {
+		t.Fatalf("first sync = %d, %v", n, err)
+	}
+	if n, _ := idx.Sync(ctx); n != 0 {
+		t.Fatalf("idle sync updated %d entries", n)
+	}
+
+	pos, _ := ginka_ecs_go.GetForUpdate[*Position](b, PositionType)
+	pos.X, pos.Y = 2, 2
+	a.RemoveComponent(PositionType)
+	if n, err := idx.Sync(ctx); err != nil || n != 2 {
+		t.Fatalf("second sync = %d, %v", n, err)
+	}
+	if got := ids(idx.InRadius(Point{}, 5)); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("InRadius = %v", got)
+	}
+	if _, ok := idx.Position("a"); ok {
+		t.Fatalf("a still indexed")
+	}
+
+	m.Remove("b")
+	if idx.Len() != 0 {
+		t.Fatalf("removed entity still indexed")
+	}
+}
+
+// TestIndex_SetPositionWithConcurrentFlush moves entities while they are
+// flushed and checks that the store ends up with every final position.
+func TestIndex_SetPositionWithConcurrentFlush(t *testing.T) {
+	ctx := context.Background()
+	w := ecstest.NewWorld(t).Components(PositionSpec()).Build()
+	idx := NewIndex[ginka_ecs_go.DataEntity](w.Entities, nil)
+	defer idx.Close()
+	for i := 0; i < 4; i++ {
+		id := fmt.Sprintf("e%d", i)
+		if _, err := w.Entities.Create(ctx, id, id, 1); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		ent := w.Entity(fmt.Sprintf("e%d", i))
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for step := 1; step <= 200; step++ {
+				if err := idx.SetPosition(ent, float64(i), float64(step)); err != nil {
+					t.Errorf("set position: %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+	moved := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, err := ginka_ecs_go.FlushAll[ginka_ecs_go.DataEntity](ctx, w.Entities, w.Persister); err != nil {
+				t.Errorf("flush: %v", err)
+				return
+			}
+			select {
+			case <-moved:
+				return
+			default:
+			}
+		}
+	}()
+	wg.Wait()
+	close(moved)
+	<-done
+	w.Flush()
+
+	for i := 0; i < 4; i++ {
+		id := fmt.Sprintf("e%d", i)
+		rec, ok := w.Store.Record(id, "position")
+		if !ok {
+			t.Fatalf("%s: position was never stored", id)
+		}
+		c, err := w.Registry.DecodeComponent(PositionType, rec.Payload)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got, want := c.(*Position).Point(), (Point{X: float64(i), Y: 200}); got != want {
+			t.Fatalf("%s: stored %+v, want %+v", id, got, want)
+		}
+		ecstest.AssertClean(t, w.Entity(id))
+	}
+}
+
+func TestPosition_Registers(t *testing.T) {
+	reg := ginka_ecs_go.NewComponentRegistry()
+	if err := RegisterPosition(reg); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	data, err := reg.EncodeComponent(NewPosition(3, 4))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	c, err := reg.DecodeComponent(PositionType, data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got := c.(*Position).Point(); got != (Point{X: 3, Y: 4}) {
+		t.Fatalf("decoded %+v", got)
+	}
+}
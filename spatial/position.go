@@ -0,0 +1,62 @@
+package spatial
+
+import ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+
+// PositionType is the component type of Position, reserved in the
+// built-in list as ginka_ecs_go.SpatialPositionType.
+const PositionType = ginka_ecs_go.SpatialPositionType
+
+// PositionName is the registered name of Position.
+const PositionName = "spatial.position"
+
+// Position is the built-in position component maintained by Index.
+type Position struct {
+	ginka_ecs_go.DataComponentCore
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// NewPosition creates a Position at (x, y).
+func NewPosition(x, y float64) *Position {
+	return &Position{DataComponentCore: ginka_ecs_go.NewDataComponentCore(PositionType), X: x, Y: y}
+}
+
+// PositionSpec returns the registry spec of Position.
+func PositionSpec() ginka_ecs_go.ComponentSpec {
+	return ginka_ecs_go.ComponentSpec{
+		Name: PositionName,
+		Type: PositionType,
+		New:  func() ginka_ecs_go.Component { return NewPosition(0, 0) },
+	}
+}
+
+// RegisterPosition registers Position in reg.
+// Nil uses DefaultComponentRegistry.
+func RegisterPosition(reg *ginka_ecs_go.ComponentRegistry) error {
+	if reg == nil {
+		reg = ginka_ecs_go.DefaultComponentRegistry
+	}
+	return reg.Register(PositionSpec())
+}
+
+// StorageKey implements ginka_ecs_go.DataComponent.
+func (p *Position) StorageKey() string {
+	return "position"
+}
+
+// Point returns the position as a Point.
+func (p *Position) Point() Point {
+	return Point{X: p.X, Y: p.Y}
+}
+
+// Clone implements ginka_ecs_go.Cloner.
+func (p *Position) Clone() ginka_ecs_go.Component {
+	out := *p
+	out.SetTags(p.Tags()...)
+	return &out
+}
+
+var (
+	_ ginka_ecs_go.DataComponent = (*Position)(nil)
+	_ ginka_ecs_go.Cloner        = (*Position)(nil)
+)
@@ -0,0 +1,239 @@
+package spatial
+
+import "container/heap"
+
+// DefaultMaxItems is the number of items a quadtree leaf holds before it splits.
+const DefaultMaxItems = 16
+
+// maxQuadDepth bounds splitting when many items share a position.
+const maxQuadDepth = 24
+
+// Quadtree is a point quadtree backend. It adapts to clustered worlds where
+// a uniform grid would have crowded and empty cells. Items outside the
+// bounds are kept in a list that every query scans.
+type Quadtree struct {
+	maxItems int
+	root     *quadNode
+	outside  map[string]Point
+	n        int
+}
+
+type quadNode struct {
+	bounds   Rect
+	depth    int
+	items    []Item
+	children *[4]quadNode
+}
+
+// NewQuadtree creates a Quadtree covering bounds whose leaves split above
+// maxItems items. Values below 1 use DefaultMaxItems.
+func NewQuadtree(bounds Rect, maxItems int) *Quadtree {
+	if maxItems < 1 {
+		maxItems = DefaultMaxItems
+	}
+	return &Quadtree{
+		maxItems: maxItems,
+		root:     &quadNode{bounds: bounds},
+		outside:  make(map[string]Point),
+	}
+}
+
+// Insert implements Backend.
+func (q *Quadtree) Insert(id string, p Point) {
+	q.n++
+	if !q.root.bounds.Contains(p) {
+		q.outside[id] = p
+		return
+	}
+	node := q.root
+	for node.children != nil {
+		node = node.child(p)
+	}
+	node.items = append(node.items, Item{Id: id, Point: p})
+	if len(node.items) > q.maxItems && node.depth < maxQuadDepth {
+		node.split()
+	}
+}
+
+// Remove implements Backend.
+func (q *Quadtree) Remove(id string, p Point) {
+	if !q.root.bounds.Contains(p) {
+		if _, ok := q.outside[id]; ok {
+			delete(q.outside, id)
+			q.n--
+		}
+		return
+	}
+	var path []*quadNode
+	node := q.root
+	for node.children != nil {
+		path = append(path, node)
+		node = node.child(p)
+	}
+	for i, it := range node.items {
+		if it.Id == id {
+			node.items = append(node.items[:i], node.items[i+1:]...)
+			q.n--
+			break
+		}
+	}
+	// Collapse parents whose subtree fits in one leaf again.
+	for i := len(path) - 1; i >= 0; i-- {
+		parent := path[i]
+		if !parent.collapse(q.maxItems) {
+			break
+		}
+	}
+}
+
+func (n *quadNode) child(p Point) *quadNode {
+	midX := (n.bounds.MinX + n.bounds.MaxX) / 2
+	midY := (n.bounds.MinY + n.bounds.MaxY) / 2
+	i := 0
+	if p.X >= midX {
+		i |= 1
+	}
+	if p.Y >= midY {
+		i |= 2
+	}
+	return &n.children[i]
+}
+
+func (n *quadNode) split() {
+	b := n.bounds
+	midX := (b.MinX + b.MaxX) / 2
+	midY := (b.MinY + b.MaxY) / 2
+	n.children = &[4]quadNode{
+		{bounds: Rect{MinX: b.MinX, MinY: b.MinY, MaxX: midX, MaxY: midY}, depth: n.depth + 1},
+		{bounds: Rect{MinX: midX, MinY: b.MinY, MaxX: b.MaxX, MaxY: midY}, depth: n.depth + 1},
+		{bounds: Rect{MinX: b.MinX, MinY: midY, MaxX: midX, MaxY: b.MaxY}, depth: n.depth + 1},
+		{bounds: Rect{MinX: midX, MinY: midY, MaxX: b.MaxX, MaxY: b.MaxY}, depth: n.depth + 1},
+	}
+	items := n.items
+	n.items = nil
+	for _, it := range items {
+		c := n.child(it.Point)
+		c.items = append(c.items, it)
+	}
+}
+
+// collapse merges leaf children back into n when they fit.
+func (n *quadNode) collapse(maxItems int) bool {
+	total := 0
+	for i := range n.children {
+		c := &n.children[i]
+		if c.children != nil {
+			return false
+		}
+		total += len(c.items)
+	}
+	if total > maxItems {
+		return false
+	}
+	items := make([]Item, 0, total)
+	for i := range n.children {
+		items = append(items, n.children[i].items...)
+	}
+	n.items = items
+	n.children = nil
+	return true
+}
+
+// Search implements Backend.
+func (q *Quadtree) Search(r Rect, fn func(it Item) bool) {
+	for id, p := range q.outside {
+		if r.Contains(p) && !fn(Item{Id: id, Point: p}) {
+			return
+		}
+	}
+	q.root.search(r, fn)
+}
+
+func (n *quadNode) search(r Rect, fn func(it Item) bool) bool {
+	if !n.bounds.Intersects(r) {
+		return true
+	}
+	if n.children == nil {
+		for _, it := range n.items {
+			if r.Contains(it.Point) && !fn(it) {
+				return false
+			}
+		}
+		return true
+	}
+	for i := range n.children {
+		if !n.children[i].search(r, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Nearest implements Backend with a best-first traversal.
+func (q *Quadtree) Nearest(p Point, k int) []Item {
+	if k <= 0 || q.n == 0 {
+		return nil
+	}
+	queue := &nearestQueue{}
+	for id, pt := range q.outside {
+		heap.Push(queue, nearestEntry{dist: pt.DistSq(p), item: Item{Id: id, Point: pt}})
+	}
+	heap.Push(queue, nearestEntry{dist: q.root.bounds.distSq(p), node: q.root})
+
+	out := make([]Item, 0, k)
+	for queue.Len() > 0 && len(out) < k {
+		e := heap.Pop(queue).(nearestEntry)
+		if e.node == nil {
+			out = append(out, e.item)
+			continue
+		}
+		if e.node.children == nil {
+			for _, it := range e.node.items {
+				heap.Push(queue, nearestEntry{dist: it.Point.DistSq(p), item: it})
+			}
+			continue
+		}
+		for i := range e.node.children {
+			c := &e.node.children[i]
+			heap.Push(queue, nearestEntry{dist: c.bounds.distSq(p), node: c})
+		}
+	}
+	return out
+}
+
+// Len implements Backend.
+func (q *Quadtree) Len() int {
+	return q.n
+}
+
+// nearestEntry is either a node, keyed by the distance to its bounds, or an item.
+type nearestEntry struct {
+	dist float64
+	node *quadNode
+	item Item
+}
+
+type nearestQueue []nearestEntry
+
+func (h nearestQueue) Len() int { return len(h) }
+func (h nearestQueue) Less(i, j int) bool {
+	if h[i].dist != h[j].dist {
+		return h[i].dist < h[j].dist
+	}
+	// Expand nodes before emitting items at the same distance, and emit
+	// equidistant items by id so results are deterministic.
+	if (h[i].node == nil) != (h[j].node == nil) {
+		return h[i].node != nil
+	}
+	return h[i].item.Id < h[j].item.Id
+}
+func (h nearestQueue) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *nearestQueue) Push(x any)   { *h = append(*h, x.(nearestEntry)) }
+func (h *nearestQueue) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+var _ Backend = (*Quadtree)(nil)
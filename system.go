@@ -1,8 +1,116 @@
 package ginka_ecs_go
 
+import (
+	"fmt"
+	"reflect"
+)
+
 // System is a named business logic unit.
 // Execution and organization are handled by the caller.
 type System interface {
 	// Name identifies the system.
 	Name() string
 }
+
+// ResourceAccess declares which world resources a system reads and writes.
+// Build it with ResourceTypeOf.
+type ResourceAccess struct {
+	Reads  []reflect.Type
+	Writes []reflect.Type
+}
+
+// ResourceSystem is a System that declares its resource usage.
+// Schedulers use the declaration to validate worlds and to decide which
+// systems may run concurrently.
+type ResourceSystem interface {
+	System
+	ResourceAccess() ResourceAccess
+}
+
+// Conflicts reports whether a and b cannot run concurrently:
+// either writes a resource the other reads or writes.
+func (a ResourceAccess) Conflicts(b ResourceAccess) bool {
+	for _, w := range a.Writes {
+		if containsType(b.Reads, w) || containsType(b.Writes, w) {
+			return true
+		}
+	}
+	for _, w := range b.Writes {
+		if containsType(a.Reads, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSystemResources verifies that every resource declared by the given
+// ResourceSystems exists in w. Systems without a declaration are skipped.
+func CheckSystemResources(w ResourceHolder, systems ...System) error {
+	resources := w.Resources()
+	for _, sys := range systems {
+		rs, ok := sys.(ResourceSystem)
+		if !ok {
+			continue
+		}
+		access := rs.ResourceAccess()
+		for _, t := range append(append([]reflect.Type(nil), access.Reads...), access.Writes...) {
+			if !resources.Has(t) {
+				return fmt.Errorf("system %s: resource %s: %w", sys.Name(), t, ErrResourceNotFound)
+			}
+		}
+	}
+	return nil
+}
+
+// ScheduleBatches groups systems into ordered batches whose members do not
+// conflict on resources. Batches preserve the input order: a system is never
+// placed before a conflicting system that precedes it. Systems without a
+// declaration are treated as conflicting with everything and run alone.
+func ScheduleBatches(systems ...System) [][]System {
+	var batches [][]System
+	var accesses [][]ResourceAccess
+	for _, sys := range systems {
+		rs, declared := sys.(ResourceSystem)
+		if !declared {
+			batches = append(batches, []System{sys})
+			accesses = append(accesses, nil)
+			continue
+		}
+		access := rs.ResourceAccess()
+
+		// Walk back to the latest batch this system conflicts with; it may
+		// only join a batch after that one.
+		target := len(batches)
+		for i := len(batches) - 1; i >= 0; i-- {
+			if accesses[i] == nil || conflictsWithAny(access, accesses[i]) {
+				break
+			}
+			target = i
+		}
+		if target == len(batches) {
+			batches = append(batches, nil)
+			accesses = append(accesses, []ResourceAccess{})
+		}
+		batches[target] = append(batches[target], sys)
+		accesses[target] = append(accesses[target], access)
+	}
+	return batches
+}
+
+func conflictsWithAny(access ResourceAccess, others []ResourceAccess) bool {
+	for _, other := range others {
+		if access.Conflicts(other) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, existing := range types {
+		if existing == t {
+			return true
+		}
+	}
+	return false
+}
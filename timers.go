@@ -0,0 +1,540 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTimerResolution is the tick length of the timers NewCoreWorld creates.
+const DefaultTimerResolution = 100 * time.Millisecond
+
+// timerWheelSize is the number of slots in the wheel. Timers further out
+// than one turn wait in their slot for the remaining turns.
+const timerWheelSize = 512
+
+// TimersComponentName is the registered name of TimersComponent.
+const TimersComponentName = "ginka.timers"
+
+// TimerId identifies a timer within its Timers.
+type TimerId uint64
+
+// TimerKind is what a timer does when it fires.
+type TimerKind string
+
+const (
+	// TimerCallback runs the handler registered under Timer.Handler.
+	TimerCallback TimerKind = "callback"
+	// TimerRemoveComponent removes the component of type Timer.Component.
+	TimerRemoveComponent TimerKind = "remove_component"
+)
+
+// Timer is a pending timer of an entity, as persisted in TimersComponent.
+type Timer struct {
+	Id   TimerId   `json:"id"`
+	At   time.Time `json:"at"`
+	Kind TimerKind `json:"kind"`
+	// Handler names the callback of a TimerCallback timer.
+	Handler string `json:"handler,omitempty"`
+	// Component is the type removed by a TimerRemoveComponent timer.
+	Component ComponentType `json:"component,omitempty"`
+	// Data is passed to the handler as given to Schedule, encoded as JSON.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// TimerHandler is called when a TimerCallback timer fires, outside any
+// entity lock. The timer is already gone from the entity.
+type TimerHandler func(ctx context.Context, ent DataEntity, timer Timer) error
+
+// TimersComponent holds the pending timers of an entity so they are
+// persisted with it. Timers maintains it; register it with RegisterTimers so
+// it can be stored and loaded.
+type TimersComponent struct {
+	DataComponentCore
+	Timers []Timer `json:"timers"`
+}
+
+// NewTimersComponent creates an empty TimersComponent.
+func NewTimersComponent() *TimersComponent {
+	return &TimersComponent{DataComponentCore: NewDataComponentCore(TimersComponentType)}
+}
+
+// StorageKey implements DataComponent.
+func (c *TimersComponent) StorageKey() string {
+	return "timers"
+}
+
+// TimersSpec returns the registry spec of TimersComponent.
+func TimersSpec() ComponentSpec {
+	return ComponentSpec{
+		Name: TimersComponentName,
+		Type: TimersComponentType,
+		New:  func() Component { return NewTimersComponent() },
+	}
+}
+
+// RegisterTimers registers TimersComponent in reg.
+// Nil uses DefaultComponentRegistry.
+func RegisterTimers(reg *ComponentRegistry) error {
+	if reg == nil {
+		reg = DefaultComponentRegistry
+	}
+	return reg.Register(TimersSpec())
+}
+
+// Timers is a hashed timer wheel scheduling work on entities. Time is
+// divided into ticks of a fixed resolution counted from a start time, so a
+// tick and a time are two names for the same deadline; see TickTime.
+//
+// Step and AdvanceTo move the wheel and fire due timers in deadline order.
+// CoreWorld drives its own Timers while running once given an interval;
+// see SetTimerInterval.
+//
+// Pending timers are also kept in the entity's TimersComponent so they are
+// persisted with it; Restore schedules them again after loading. Use
+// AttachTimers to cancel the timers of entities removed from a manager.
+// Timers must not be scheduled or cancelled inside the entity's Tx.
+// It is safe for concurrent use.
+type Timers struct {
+	mu         sync.Mutex
+	resolution time.Duration
+	start      time.Time
+	tick       uint64
+	nextId     TimerId
+	slots      [timerWheelSize][]*timerEntry
+	byId       map[TimerId]*timerEntry
+	handlers   map[string]TimerHandler
+	// attached holds the membership checks of managers from AttachTimers.
+	attached     map[uint64]func(ent DataEntity) bool
+	nextAttachId uint64
+}
+
+type timerEntry struct {
+	timer Timer
+	ent   DataEntity
+	due   uint64
+}
+
+// NewTimers creates a wheel with ticks of resolution starting at start.
+// A resolution of 0 or less uses DefaultTimerResolution.
+func NewTimers(resolution time.Duration, start time.Time) *Timers {
+	if resolution <= 0 {
+		resolution = DefaultTimerResolution
+	}
+	return &Timers{
+		resolution: resolution,
+		start:      start,
+		byId:       make(map[TimerId]*timerEntry),
+		handlers:   make(map[string]TimerHandler),
+	}
+}
+
+// Handle registers fn as the callback named name. Callbacks are named so
+// persisted timers can find them again after a restart.
+func (t *Timers) Handle(name string, fn TimerHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers[name] = fn
+}
+
+// Tick returns the current tick; timers due at or before it have fired.
+func (t *Timers) Tick() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tick
+}
+
+// Now returns the time of the current tick.
+func (t *Timers) Now() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tickTimeLocked(t.tick)
+}
+
+// TickTime returns the time at which tick starts, for scheduling at a tick.
+func (t *Timers) TickTime(tick uint64) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tickTimeLocked(tick)
+}
+
+// Len returns the number of pending timers.
+func (t *Timers) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.byId)
+}
+
+// Pending returns the pending timers of entity id, soonest first.
+func (t *Timers) Pending(id string) []Timer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []Timer
+	for _, e := range t.byId {
+		if e.ent.Id() == id {
+			out = append(out, e.timer)
+		}
+	}
+	sortTimers(out)
+	return out
+}
+
+// Schedule runs the handler registered as handler for ent at time at.
+// data is encoded as JSON and handed back in Timer.Data. Deadlines that have
+// passed fire on the next tick.
+func (t *Timers) Schedule(ent DataEntity, at time.Time, handler string, data any) (TimerId, error) {
+	t.mu.Lock()
+	_, ok := t.handlers[handler]
+	t.mu.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("schedule %s: %q: %w", ent.Id(), handler, ErrUnknownTimerHandler)
+	}
+	timer := Timer{At: at, Kind: TimerCallback, Handler: handler}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return 0, fmt.Errorf("schedule %s: encode data: %w", ent.Id(), err)
+		}
+		timer.Data = raw
+	}
+	return t.schedule(ent, timer)
+}
+
+// ScheduleRemoval removes ent's component of type ct at time at, for
+// expiring buffs and similar. Removing a missing component is not an error.
+func (t *Timers) ScheduleRemoval(ent DataEntity, at time.Time, ct ComponentType) (TimerId, error) {
+	return t.schedule(ent, Timer{At: at, Kind: TimerRemoveComponent, Component: ct})
+}
+
+func (t *Timers) schedule(ent DataEntity, timer Timer) (TimerId, error) {
+	t.mu.Lock()
+	t.nextId++
+	timer.Id = t.nextId
+	t.mu.Unlock()
+
+	if err := ent.Tx(func(tx DataEntity) error {
+		return addPersistedTimer(tx, timer)
+	}); err != nil {
+		return 0, fmt.Errorf("schedule %s: %w", ent.Id(), err)
+	}
+	// The entity may have been removed since the Tx, after its remove hook
+	// already cancelled its timers; checking under t.mu closes that gap.
+	t.mu.Lock()
+	registered := t.registeredLocked(ent)
+	if registered {
+		t.insertLocked(&timerEntry{timer: timer, ent: ent})
+	}
+	t.mu.Unlock()
+	if !registered {
+		_ = ent.Tx(func(tx DataEntity) error {
+			removePersistedTimer(tx, timer.Id)
+			return nil
+		})
+		return 0, fmt.Errorf("schedule %s: %w", ent.Id(), ErrEntityNotFound)
+	}
+	return timer.Id, nil
+}
+
+// registeredLocked reports whether ent belongs to a manager the timers are
+// attached to. Unattached timers accept every entity.
+func (t *Timers) registeredLocked(ent DataEntity) bool {
+	if len(t.attached) == 0 {
+		return true
+	}
+	for _, registered := range t.attached {
+		if registered(ent) {
+			return true
+		}
+	}
+	return false
+}
+
+// Restore schedules the timers persisted in ent's TimersComponent, for
+// entities loaded from storage. Timers already scheduled for ent are
+// skipped; timers whose id another entity took get a new one. It returns
+// the number of timers scheduled. Once the timers are attached to a
+// manager, ent must be added to it first.
+func (t *Timers) Restore(ent DataEntity) (int, error) {
+	restored := 0
+	err := ent.Tx(func(tx DataEntity) error {
+		c, ok := tx.Get(TimersComponentType)
+		if !ok {
+			return nil
+		}
+		tc, ok := c.(*TimersComponent)
+		if !ok {
+			return fmt.Errorf("timers component is %T", c)
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if !t.registeredLocked(ent) {
+			return ErrEntityNotFound
+		}
+		renumbered := false
+		for i := range tc.Timers {
+			existing, taken := t.byId[tc.Timers[i].Id]
+			if taken && existing.ent == ent {
+				continue
+			}
+			if taken || tc.Timers[i].Id == 0 {
+				t.nextId++
+				tc.Timers[i].Id = t.nextId
+				renumbered = true
+			}
+			t.nextId = max(t.nextId, tc.Timers[i].Id)
+			t.insertLocked(&timerEntry{timer: tc.Timers[i], ent: ent})
+			restored++
+		}
+		if renumbered {
+			tx.GetForUpdate(TimersComponentType)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("restore timers of %s: %w", ent.Id(), err)
+	}
+	return restored, nil
+}
+
+// Cancel removes a pending timer. It reports whether the timer was pending.
+func (t *Timers) Cancel(id TimerId) bool {
+	t.mu.Lock()
+	e, ok := t.byId[id]
+	if ok {
+		t.removeLocked(e)
+	}
+	t.mu.Unlock()
+	if ok {
+		_ = e.ent.Tx(func(tx DataEntity) error {
+			removePersistedTimer(tx, id)
+			return nil
+		})
+	}
+	return ok
+}
+
+// CancelEntity removes every pending timer of entity id and returns how
+// many there were. The entity's TimersComponent is left as it is, since
+// the entity is usually gone.
+func (t *Timers) CancelEntity(id string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cancelLocked(func(e *timerEntry) bool { return e.ent.Id() == id })
+}
+
+func (t *Timers) cancelLocked(match func(e *timerEntry) bool) int {
+	n := 0
+	for _, e := range t.byId {
+		if match(e) {
+			t.removeLocked(e)
+			n++
+		}
+	}
+	return n
+}
+
+// Step advances the wheel by one tick and fires the timers due by then.
+// It returns how many fired and the errors of failed ones, joined.
+func (t *Timers) Step(ctx context.Context) (int, error) {
+	t.mu.Lock()
+	t.tick++
+	due := t.collectLocked()
+	t.mu.Unlock()
+	return t.fire(ctx, due)
+}
+
+// AdvanceTo steps the wheel until the current tick reaches the time now and
+// fires every timer due by then. It never moves the wheel backwards.
+func (t *Timers) AdvanceTo(ctx context.Context, now time.Time) (int, error) {
+	t.mu.Lock()
+	target := t.tickAtLocked(now, false)
+	var due []*timerEntry
+	for t.tick < target {
+		if len(t.byId) == 0 {
+			t.tick = target
+			break
+		}
+		t.tick++
+		due = append(due, t.collectLocked()...)
+	}
+	t.mu.Unlock()
+	return t.fire(ctx, due)
+}
+
+// collectLocked removes and returns the timers of the current tick's slot
+// that are due.
+func (t *Timers) collectLocked() []*timerEntry {
+	slot := &t.slots[t.tick%timerWheelSize]
+	var due []*timerEntry
+	kept := (*slot)[:0]
+	for _, e := range *slot {
+		if e.due <= t.tick {
+			due = append(due, e)
+			delete(t.byId, e.timer.Id)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	clear((*slot)[len(kept):])
+	*slot = kept
+	return due
+}
+
+func (t *Timers) fire(ctx context.Context, due []*timerEntry) (int, error) {
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].due != due[j].due {
+			return due[i].due < due[j].due
+		}
+		return due[i].timer.Id < due[j].timer.Id
+	})
+	var errs []error
+	for _, e := range due {
+		if err := t.fireOne(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("timer %d of %s: %w", e.timer.Id, e.ent.Id(), err))
+		}
+	}
+	return len(due), errors.Join(errs...)
+}
+
+func (t *Timers) fireOne(ctx context.Context, e *timerEntry) error {
+	timer := e.timer
+	err := TxContext(ctx, e.ent, func(ctx context.Context, tx DataEntity) error {
+		removePersistedTimer(tx, timer.Id)
+		if timer.Kind == TimerRemoveComponent {
+			tx.RemoveComponent(timer.Component)
+		}
+		return nil
+	})
+	if err != nil || timer.Kind != TimerCallback {
+		return err
+	}
+	t.mu.Lock()
+	fn, ok := t.handlers[timer.Handler]
+	t.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%q: %w", timer.Handler, ErrUnknownTimerHandler)
+	}
+	return fn(ctx, e.ent, timer)
+}
+
+func (t *Timers) insertLocked(e *timerEntry) {
+	e.due = max(t.tickAtLocked(e.timer.At, true), t.tick+1)
+	slot := &t.slots[e.due%timerWheelSize]
+	*slot = append(*slot, e)
+	t.byId[e.timer.Id] = e
+}
+
+func (t *Timers) removeLocked(e *timerEntry) {
+	delete(t.byId, e.timer.Id)
+	slot := &t.slots[e.due%timerWheelSize]
+	for i, other := range *slot {
+		if other == e {
+			*slot = append((*slot)[:i], (*slot)[i+1:]...)
+			break
+		}
+	}
+}
+
+// tickAtLocked converts at to a tick, rounding up for deadlines so timers
+// never fire early, and down for the current time.
+func (t *Timers) tickAtLocked(at time.Time, roundUp bool) uint64 {
+	d := at.Sub(t.start)
+	if d <= 0 {
+		return 0
+	}
+	tick := uint64(d / t.resolution)
+	if roundUp && d%t.resolution != 0 {
+		tick++
+	}
+	return tick
+}
+
+func (t *Timers) tickTimeLocked(tick uint64) time.Time {
+	return t.start.Add(time.Duration(tick) * t.resolution)
+}
+
+// AttachTimers cancels the pending timers of every entity m removes.
+// While attached, Schedule and Restore refuse entities that no attached
+// manager holds, so a timer cannot outlive a concurrent removal.
+// The returned func detaches the timers from m.
+func AttachTimers[T DataEntity](m EntityManager[T], t *Timers) func() {
+	t.mu.Lock()
+	if t.attached == nil {
+		t.attached = make(map[uint64]func(ent DataEntity) bool)
+	}
+	t.nextAttachId++
+	attachId := t.nextAttachId
+	t.attached[attachId] = func(ent DataEntity) bool {
+		got, ok := m.Get(ent.Id())
+		return ok && DataEntity(got) == ent
+	}
+	t.mu.Unlock()
+
+	unhook := m.OnRemove(func(ent T) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.cancelLocked(func(e *timerEntry) bool { return e.ent == DataEntity(ent) })
+	})
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unhook()
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			delete(t.attached, attachId)
+		})
+	}
+}
+
+func addPersistedTimer(tx DataEntity, timer Timer) error {
+	if !tx.Has(TimersComponentType) {
+		if err := tx.Add(NewTimersComponent()); err != nil {
+			return err
+		}
+	}
+	c, _ := tx.GetForUpdate(TimersComponentType)
+	tc, ok := c.(*TimersComponent)
+	if !ok {
+		return fmt.Errorf("timers component is %T", c)
+	}
+	tc.Timers = append(tc.Timers, timer)
+	sortTimers(tc.Timers)
+	return nil
+}
+
+// removePersistedTimer drops timer id from the entity's TimersComponent.
+// The component stays, empty, so the deletion is persisted too.
+func removePersistedTimer(tx DataEntity, id TimerId) {
+	c, ok := tx.Get(TimersComponentType)
+	if !ok {
+		return
+	}
+	tc, ok := c.(*TimersComponent)
+	if !ok {
+		return
+	}
+	for i, timer := range tc.Timers {
+		if timer.Id != id {
+			continue
+		}
+		tx.GetForUpdate(TimersComponentType)
+		tc.Timers = append(tc.Timers[:i], tc.Timers[i+1:]...)
+		return
+	}
+}
+
+func sortTimers(timers []Timer) {
+	sort.Slice(timers, func(i, j int) bool {
+		if !timers[i].At.Equal(timers[j].At) {
+			return timers[i].At.Before(timers[j].At)
+		}
+		return timers[i].Id < timers[j].Id
+	})
+}
+
+// Must satisfy DataComponent.
+var _ DataComponent = (*TimersComponent)(nil)
@@ -0,0 +1,181 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func persistedTimers(t *testing.T, ent DataEntity) []Timer {
+	t.Helper()
+	tc, ok := Get[*TimersComponent](ent, TimersComponentType)
+	if !ok {
+		t.Fatalf("no timers component")
+	}
+	return tc.Timers
+}
+
+func TestTimers_FireCancelAndPersist(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	timers := NewTimers(10*time.Millisecond, start)
+	var fired []Timer
+	timers.Handle("ping", func(ctx context.Context, ent DataEntity, timer Timer) error {
+		fired = append(fired, timer)
+		return nil
+	})
+
+	ent := NewDataEntityCore("p1", "p1", 1)
+	_ = ent.Add(newTestLabelComponent("buff"))
+	if _, err := timers.ScheduleRemoval(ent, start.Add(25*time.Millisecond), testLabelComponentType); err != nil {
+		t.Fatalf("schedule removal: %v", err)
+	}
+	if _, err := timers.Schedule(ent, timers.TickTime(5), "ping", map[string]int{"n": 7}); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	cancelled, _ := timers.Schedule(ent, timers.TickTime(4), "ping", nil)
+	if _, err := timers.Schedule(ent, start, "missing", nil); !errors.Is(err, ErrUnknownTimerHandler) {
+		t.Fatalf("unknown handler err = %v", err)
+	}
+	if len(timers.Pending("p1")) != 3 || len(persistedTimers(t, ent)) != 3 {
+		t.Fatalf("pending = %v", timers.Pending("p1"))
+	}
+	if dirty := ent.DirtyTypes(); len(dirty) != 1 || dirty[0] != TimersComponentType {
+		t.Fatalf("dirty = %v", dirty)
+	}
+	if !timers.Cancel(cancelled) || timers.Cancel(cancelled) || len(persistedTimers(t, ent)) != 2 {
+		t.Fatalf("cancel left %v", persistedTimers(t, ent))
+	}
+
+	for i := 0; i < 2; i++ {
+		if n, err := timers.Step(ctx); n != 0 || err != nil {
+			t.Fatalf("step %d fired %d, %v", i+1, n, err)
+		}
+	}
+	if n, err := timers.Step(ctx); n != 1 || err != nil || ent.Has(testLabelComponentType) {
+		t.Fatalf("step 3 fired %d, %v; label present %v", n, err, ent.Has(testLabelComponentType))
+	}
+	if n, err := timers.AdvanceTo(ctx, start.Add(55*time.Millisecond)); n != 1 || err != nil {
+		t.Fatalf("advance fired %d, %v", n, err)
+	}
+	if len(fired) != 1 || string(fired[0].Data) != `{"n":7}` || timers.Tick() != 5 {
+		t.Fatalf("fired = %+v at tick %d", fired, timers.Tick())
+	}
+	if timers.Len() != 0 || len(persistedTimers(t, ent)) != 0 {
+		t.Fatalf("timers left: %d, persisted %v", timers.Len(), persistedTimers(t, ent))
+	}
+}
+
+func TestTimers_RestoreAndFarDeadlines(t *testing.T) {
+	ctx := context.Background()
+	reg := NewComponentRegistry()
+	if err := RegisterTimers(reg); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	timers := NewTimers(time.Second, start)
+	timers.Handle("expire", func(ctx context.Context, ent DataEntity, timer Timer) error { return nil })
+	ent := NewDataEntityCore("p1", "p1", 1)
+	far := timers.TickTime(timerWheelSize + 88)
+	if _, err := timers.Schedule(ent, far, "expire", nil); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	// Round trip the component as persistence would, into a fresh wheel.
+	tc, _ := ent.Get(TimersComponentType)
+	payload, err := reg.EncodeComponent(tc)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	decoded, err := reg.DecodeComponent(TimersComponentType, payload)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	loaded := NewDataEntityCore("p1", "p1", 1)
+	_ = loaded.Add(decoded)
+	restarted := NewTimers(time.Second, start)
+	if n, err := restarted.Restore(loaded); n != 1 || err != nil {
+		t.Fatalf("restore = %d, %v", n, err)
+	}
+	if n, _ := restarted.Restore(loaded); n != 0 {
+		t.Fatalf("second restore scheduled %d", n)
+	}
+	if pending := restarted.Pending("p1"); len(pending) != 1 || !pending[0].At.Equal(far) {
+		t.Fatalf("pending = %+v", pending)
+	}
+
+	if n, _ := restarted.AdvanceTo(ctx, far.Add(-time.Second)); n != 0 {
+		t.Fatalf("fired %d timers a turn early", n)
+	}
+	n, err := restarted.AdvanceTo(ctx, far)
+	if !errors.Is(err, ErrUnknownTimerHandler) || n != 1 {
+		t.Fatalf("fire without handler = %d, %v", n, err)
+	}
+}
+
+func TestAttachTimers_CancelsOnRemove(t *testing.T) {
+	ctx := context.Background()
+	m := newTestDataEntityManager()
+	timers := NewTimers(time.Second, time.Now())
+	timers.Handle("expire", func(ctx context.Context, ent DataEntity, timer Timer) error { return nil })
+	detach := AttachTimers[DataEntity](m, timers)
+	defer detach()
+
+	var removed DataEntity
+	for _, id := range []string{"p1", "p2"} {
+		ent, _ := m.Create(ctx, id, id, 1)
+		if _, err := timers.Schedule(ent, time.Now().Add(time.Hour), "expire", nil); err != nil {
+			t.Fatalf("schedule: %v", err)
+		}
+		if removed == nil {
+			removed = ent
+		}
+	}
+	m.Remove("p1")
+	if timers.Len() != 1 || len(timers.Pending("p2")) != 1 {
+		t.Fatalf("pending after remove: %d", timers.Len())
+	}
+
+	// A removed entity, such as one removed while Schedule ran, cannot
+	// get new timers.
+	if _, err := timers.Schedule(removed, time.Now().Add(time.Hour), "expire", nil); !errors.Is(err, ErrEntityNotFound) {
+		t.Fatalf("schedule on removed entity err = %v", err)
+	}
+	if timers.Len() != 1 || len(persistedTimers(t, removed)) != 1 {
+		t.Fatalf("timers after refused schedule: %d pending, %d persisted", timers.Len(), len(persistedTimers(t, removed)))
+	}
+	if _, err := timers.Restore(removed); !errors.Is(err, ErrEntityNotFound) {
+		t.Fatalf("restore removed entity err = %v", err)
+	}
+}
+
+func TestCoreWorld_DrivesTimers(t *testing.T) {
+	w := NewCoreWorld("timers")
+	w.SetTimerInterval(5 * time.Millisecond)
+	fired := make(chan string, 1)
+	w.Timers().Handle("invite_timeout", func(ctx context.Context, ent DataEntity, timer Timer) error {
+		fired <- ent.Id()
+		return nil
+	})
+	ent := NewDataEntityCore("invite-1", "invite", 2)
+	if _, err := w.Timers().Schedule(ent, time.Now(), "invite_timeout", nil); err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- w.Run() }()
+	waitForRunning(t, w)
+	select {
+	case id := <-fired:
+		if id != "invite-1" {
+			t.Fatalf("fired for %s", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timer did not fire")
+	}
+	_ = w.Stop()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
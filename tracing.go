@@ -0,0 +1,142 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Tracer starts spans. Its shape follows OpenTelemetry's trace.Tracer, so an
+// adapter only converts attributes and wraps the returned span.
+// Implementations must be safe for concurrent use.
+type Tracer interface {
+	// Start begins a span as a child of the span in ctx, if any, and returns
+	// a context carrying the new span.
+	Start(ctx context.Context, name string, attrs ...SpanAttr) (context.Context, Span)
+}
+
+// Span is one timed operation started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...SpanAttr)
+	RecordError(err error)
+	End()
+}
+
+// SpanAttr is a span attribute. Values are strings, ints, int64s, float64s or bools.
+type SpanAttr struct {
+	Key   string
+	Value any
+}
+
+// Span names reported by the package.
+const (
+	SpanTx           = "ginka.tx"
+	SpanCreateEntity = "ginka.entity.create"
+	SpanAddEntity    = "ginka.entity.add"
+	SpanForEach      = "ginka.entity.for_each"
+	SpanSystem       = "ginka.system"
+	SpanFlush        = "ginka.flush"
+	SpanFlushEntity  = "ginka.flush.entity"
+)
+
+// Span attribute keys reported by the package.
+const (
+	AttrEntityId        = "ginka.entity.id"
+	AttrEntityType      = "ginka.entity.type"
+	AttrSystem          = "ginka.system.name"
+	AttrTxWaitSeconds   = "ginka.tx.wait_seconds"
+	AttrFlushFull       = "ginka.flush.full"
+	AttrFlushPatches    = "ginka.flush.patches"
+	AttrFlushBytes      = "ginka.flush.bytes"
+	AttrFlushDirty      = "ginka.flush.dirty"
+	AttrEntitiesVisited = "ginka.entity.visited"
+)
+
+// NopTracer starts spans that record nothing. It is the default.
+var NopTracer Tracer = nopTracer{}
+
+type nopTracer struct{}
+
+type nopSpan struct{}
+
+func (nopTracer) Start(ctx context.Context, name string, attrs ...SpanAttr) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+func (nopSpan) SetAttributes(...SpanAttr) {}
+func (nopSpan) RecordError(error)         {}
+func (nopSpan) End()                      {}
+
+type tracerHolder struct {
+	t Tracer
+}
+
+var defaultTracer atomic.Pointer[tracerHolder]
+
+// SetDefaultTracer sets the Tracer used by TxContext, RunSystem and by
+// managers and persisters without their own. Nil restores NopTracer.
+func SetDefaultTracer(t Tracer) {
+	if t == nil {
+		t = NopTracer
+	}
+	defaultTracer.Store(&tracerHolder{t: t})
+}
+
+// DefaultTracer returns the Tracer set with SetDefaultTracer.
+func DefaultTracer() Tracer {
+	if h := defaultTracer.Load(); h != nil {
+		return h.t
+	}
+	return NopTracer
+}
+
+func isNopTracer(t Tracer) bool {
+	_, ok := t.(nopTracer)
+	return ok
+}
+
+// endSpan records err, if any, and ends span.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// TxContext runs fn inside ent.Tx within a SpanTx span. fn receives the
+// span's context so work it starts is traced as a child of the transaction.
+// The span records how long the entity lock took to acquire. Changes are
+// reported to the observers added to ctx with WithTxObserver.
+func TxContext(ctx context.Context, ent DataEntity, fn func(ctx context.Context, tx DataEntity) error) error {
+	return txContext(ctx, DefaultTracer(), ent, fn)
+}
+
+func txContext(ctx context.Context, tracer Tracer, ent DataEntity, fn func(ctx context.Context, tx DataEntity) error) error {
+	if isNopTracer(tracer) {
+		return observedTx(ctx, ent, func(tx DataEntity) error {
+			return fn(ctx, tx)
+		})
+	}
+
+	ctx, span := tracer.Start(ctx, SpanTx, SpanAttr{Key: AttrEntityId, Value: ent.Id()}, SpanAttr{Key: AttrEntityType, Value: int(ent.Type())})
+	start := time.Now()
+	err := observedTx(ctx, ent, func(tx DataEntity) error {
+		span.SetAttributes(SpanAttr{Key: AttrTxWaitSeconds, Value: time.Since(start).Seconds()})
+		return fn(ctx, tx)
+	})
+	endSpan(span, err)
+	return err
+}
+
+// RunSystem runs fn within a SpanSystem span named after sys.
+// Systems call it around their entry points so traces show which systems ran.
+func RunSystem(ctx context.Context, sys System, fn func(ctx context.Context) error) error {
+	tracer := DefaultTracer()
+	if isNopTracer(tracer) {
+		return fn(ctx)
+	}
+	ctx, span := tracer.Start(ctx, SpanSystem, SpanAttr{Key: AttrSystem, Value: sys.Name()})
+	err := fn(ctx)
+	endSpan(span, err)
+	return err
+}
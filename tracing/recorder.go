@@ -0,0 +1,139 @@
+// Package tracing provides an in-memory ginka_ecs_go.Tracer for tests and
+// local debugging.
+//
+// Production tracers wrap an OpenTelemetry tracer; the interfaces mirror its
+// Start/SetAttributes/RecordError/End shape, so the adapter only converts
+// ginka_ecs_go.SpanAttr values to attribute.KeyValue.
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+// Recorder records every span in memory. It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	nextId uint64
+	spans  []*span
+}
+
+// SpanRecord is a snapshot of one recorded span.
+type SpanRecord struct {
+	Id uint64
+	// ParentId is zero for root spans.
+	ParentId uint64
+	Name     string
+	Attrs    map[string]any
+	Errors   []error
+	Start    time.Time
+	End      time.Time
+	Ended    bool
+}
+
+// Duration returns the span's duration, or zero if it has not ended.
+func (s SpanRecord) Duration() time.Duration {
+	if !s.Ended {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+type span struct {
+	recorder *Recorder
+	record   SpanRecord
+}
+
+type spanKey struct {
+	recorder *Recorder
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start implements ginka_ecs_go.Tracer. The parent is the span of this
+// recorder carried by ctx.
+func (r *Recorder) Start(ctx context.Context, name string, attrs ...ginka_ecs_go.SpanAttr) (context.Context, ginka_ecs_go.Span) {
+	r.mu.Lock()
+	r.nextId++
+	s := &span{recorder: r, record: SpanRecord{Id: r.nextId, Name: name, Attrs: make(map[string]any, len(attrs)), Start: time.Now()}}
+	if parent, ok := ctx.Value(spanKey{recorder: r}).(*span); ok {
+		s.record.ParentId = parent.record.Id
+	}
+	for _, attr := range attrs {
+		s.record.Attrs[attr.Key] = attr.Value
+	}
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+	return context.WithValue(ctx, spanKey{recorder: r}, s), s
+}
+
+// SetAttributes implements ginka_ecs_go.Span.
+func (s *span) SetAttributes(attrs ...ginka_ecs_go.SpanAttr) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	for _, attr := range attrs {
+		s.record.Attrs[attr.Key] = attr.Value
+	}
+}
+
+// RecordError implements ginka_ecs_go.Span.
+func (s *span) RecordError(err error) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.record.Errors = append(s.record.Errors, err)
+}
+
+// End implements ginka_ecs_go.Span. Only the first call takes effect.
+func (s *span) End() {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	if s.record.Ended {
+		return
+	}
+	s.record.End = time.Now()
+	s.record.Ended = true
+}
+
+// Spans returns snapshots of the recorded spans in start order.
+func (r *Recorder) Spans() []SpanRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]SpanRecord, 0, len(r.spans))
+	for _, s := range r.spans {
+		rec := s.record
+		rec.Attrs = make(map[string]any, len(s.record.Attrs))
+		for k, v := range s.record.Attrs {
+			rec.Attrs[k] = v
+		}
+		rec.Errors = append([]error(nil), s.record.Errors...)
+		out = append(out, rec)
+	}
+	return out
+}
+
+// Named returns the recorded spans called name, in start order.
+func (r *Recorder) Named(name string) []SpanRecord {
+	var out []SpanRecord
+	for _, s := range r.Spans() {
+		if s.Name == name {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Reset drops all recorded spans.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
+
+var _ ginka_ecs_go.Tracer = (*Recorder)(nil)
+var _ ginka_ecs_go.Span = (*span)(nil)
@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ginka_ecs_go "github.com/Shigure42/ginka-ecs-go"
+)
+
+type testSystem struct{}
+
+func (testSystem) Name() string { return "movement" }
+
+type testComponent struct {
+	ginka_ecs_go.DataComponentCore
+	X int `json:"x"`
+}
+
+func (c *testComponent) StorageKey() string { return "test" }
+
+type memoryStore struct {
+	saved int
+}
+
+func (s *memoryStore) SaveComponent(ctx context.Context, rec ginka_ecs_go.ComponentRecord) error {
+	s.saved++
+	return nil
+}
+
+func TestRecorder_TracesSystemsTransactionsAndFlush(t *testing.T) {
+	rec := NewRecorder()
+	ginka_ecs_go.SetDefaultTracer(rec)
+	t.Cleanup(func() { ginka_ecs_go.SetDefaultTracer(nil) })
+
+	ctx := context.Background()
+	m := ginka_ecs_go.NewEntityManager(func(id string, name string, typ ginka_ecs_go.EntityType, tags ...ginka_ecs_go.Tag) (ginka_ecs_go.DataEntity, error) {
+		return ginka_ecs_go.NewDataEntityCore(id, name, typ, tags...), nil
+	}, 2)
+	ent, err := m.Create(ctx, "e1", "e1", 3)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_ = ent.Add(&testComponent{DataComponentCore: ginka_ecs_go.NewDataComponentCore(1)})
+
+	boom := errors.New("boom")
+	err = ginka_ecs_go.RunSystem(ctx, testSystem{}, func(ctx context.Context) error {
+		return ginka_ecs_go.TxContext(ctx, ent, func(ctx context.Context, tx ginka_ecs_go.DataEntity) error {
+			c, _ := ginka_ecs_go.GetForUpdate[*testComponent](tx, 1)
+			c.X++
+			return boom
+		})
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+
+	system := rec.Named(ginka_ecs_go.SpanSystem)
+	tx := rec.Named(ginka_ecs_go.SpanTx)
+	if len(system) != 1 || len(tx) != 1 {
+		t.Fatalf("spans = %+v", rec.Spans())
+	}
+	if system[0].Attrs[ginka_ecs_go.AttrSystem] != "movement" || len(system[0].Errors) != 1 || !system[0].Ended {
+		t.Fatalf("system span = %+v", system[0])
+	}
+	if tx[0].ParentId != system[0].Id || tx[0].Attrs[ginka_ecs_go.AttrEntityId] != "e1" || tx[0].Attrs[ginka_ecs_go.AttrEntityType] != 3 {
+		t.Fatalf("tx span = %+v", tx[0])
+	}
+	if _, ok := tx[0].Attrs[ginka_ecs_go.AttrTxWaitSeconds]; !ok {
+		t.Fatalf("tx span has no wait time: %+v", tx[0])
+	}
+
+	create := rec.Named(ginka_ecs_go.SpanCreateEntity)
+	add := rec.Named(ginka_ecs_go.SpanAddEntity)
+	if len(create) != 1 || len(add) != 1 || add[0].ParentId != create[0].Id {
+		t.Fatalf("create spans = %+v add spans = %+v", create, add)
+	}
+
+	rec.Reset()
+	store := &memoryStore{}
+	if _, err := ginka_ecs_go.FlushAll(ctx, m, ginka_ecs_go.NewPersister(store)); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	flush := rec.Named(ginka_ecs_go.SpanFlush)
+	forEach := rec.Named(ginka_ecs_go.SpanForEach)
+	flushEntity := rec.Named(ginka_ecs_go.SpanFlushEntity)
+	if len(flush) != 1 || len(forEach) != 1 || len(flushEntity) != 1 {
+		t.Fatalf("flush spans = %+v", rec.Spans())
+	}
+	if forEach[0].ParentId != flush[0].Id || flushEntity[0].ParentId != flush[0].Id {
+		t.Fatalf("flush span tree = %+v", rec.Spans())
+	}
+	if flush[0].Attrs[ginka_ecs_go.AttrFlushFull] != 1 || store.saved != 1 {
+		t.Fatalf("flush attrs = %+v", flush[0].Attrs)
+	}
+	for _, s := range rec.Named(ginka_ecs_go.SpanTx) {
+		if s.ParentId != flushEntity[0].Id {
+			t.Fatalf("flush tx span parent = %d", s.ParentId)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package ginka_ecs_go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// TxObserver receives the changes made by transactions run with TxContext
+// under a context returned by WithTxObserver, and the components attached
+// by Spawn under such a context.
+type TxObserver interface {
+	// ObserveTx is called after the transaction, outside the entity lock,
+	// with the context passed to TxContext. It is only called when the
+	// transaction changed something. change must not be modified.
+	ObserveTx(ctx context.Context, change *TxChange)
+}
+
+// TxChange is what one transaction changed on an entity. Only changes made
+// through the tx view are seen: component updates must go through
+// GetForUpdate, which is also what marks them dirty.
+type TxChange struct {
+	EntityId   string
+	EntityType EntityType
+	// Components lists changed components in the order they were first touched.
+	Components []ComponentChange
+	// TagsChanged reports whether the entity tags changed; TagsBefore and
+	// TagsAfter are the sorted tag sets.
+	TagsChanged bool
+	TagsBefore  []Tag
+	TagsAfter   []Tag
+	// EnabledChanged reports whether the entity enabled flag changed.
+	EnabledChanged bool
+	EnabledBefore  bool
+	EnabledAfter   bool
+	// Registry encoded the component images.
+	Registry *ComponentRegistry
+	// Err is the error the transaction returned. Changes made before it
+	// stay applied, so they are reported too.
+	Err error
+	// ImageErr joins the errors of components whose images could not be
+	// encoded. Those components are left out of Components.
+	ImageErr error
+}
+
+// ComponentChange is one component changed by a transaction.
+type ComponentChange struct {
+	Type ComponentType
+	// Before is nil if the transaction added the component.
+	Before *ComponentImage
+	// After is nil if the transaction removed the component.
+	After *ComponentImage
+}
+
+// Added reports whether the transaction attached the component.
+func (c ComponentChange) Added() bool {
+	return c.Before == nil
+}
+
+// Removed reports whether the transaction detached the component.
+func (c ComponentChange) Removed() bool {
+	return c.After == nil
+}
+
+// ComponentImage is the state of a component at the start or end of a
+// transaction.
+type ComponentImage struct {
+	Type ComponentType
+	// Payload is the component encoded by TxChange.Registry.
+	Payload []byte
+	// Version is zero for components that are not DataComponents.
+	Version uint64
+	Enabled bool
+	Tags    []Tag
+}
+
+// Decode returns a new component holding the image, with its version,
+// enabled flag and tags. The type must be registered in reg.
+func (img *ComponentImage) Decode(reg *ComponentRegistry) (Component, error) {
+	if reg == nil {
+		reg = DefaultComponentRegistry
+	}
+	c, err := reg.DecodeComponent(img.Type, img.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if dc, ok := c.(DataComponent); ok {
+		dc.SetVersion(img.Version)
+	}
+	c.SetEnabled(img.Enabled)
+	for _, tag := range img.Tags {
+		c.AddTag(tag)
+	}
+	return c, nil
+}
+
+// JSON returns the image payload as JSON, decoding payloads of other codecs
+// with reg first. A nil reg uses DefaultComponentRegistry.
+func (img *ComponentImage) JSON(reg *ComponentRegistry) ([]byte, error) {
+	if reg == nil {
+		reg = DefaultComponentRegistry
+	}
+	_, body, err := payloadJSON(reg, img.Type, img.Payload)
+	return body, err
+}
+
+func (img *ComponentImage) equal(other *ComponentImage) bool {
+	if img == nil || other == nil {
+		return img == other
+	}
+	return img.Version == other.Version && img.Enabled == other.Enabled &&
+		bytes.Equal(img.Payload, other.Payload) && slices.Equal(img.Tags, other.Tags)
+}
+
+type txObserversKey struct{}
+
+type txObservers struct {
+	registry  *ComponentRegistry
+	observers []TxObserver
+}
+
+// WithTxObserver returns a context under which TxContext reports every
+// transaction's changes to o, in addition to observers already on ctx.
+// Component images are encoded with reg; observers of one context share the
+// most recent non-nil reg. A nil reg uses DefaultComponentRegistry.
+//
+// Entities must embed *DataEntityCore to be observed; other DataEntities
+// run their transactions unobserved.
+func WithTxObserver(ctx context.Context, reg *ComponentRegistry, o TxObserver) context.Context {
+	next := &txObservers{registry: reg}
+	if prev, ok := ctx.Value(txObserversKey{}).(*txObservers); ok {
+		if next.registry == nil {
+			next.registry = prev.registry
+		}
+		next.observers = append(next.observers, prev.observers...)
+	}
+	next.observers = append(next.observers, o)
+	return context.WithValue(ctx, txObserversKey{}, next)
+}
+
+// journaledEntity is implemented by entities embedding *DataEntityCore.
+type journaledEntity interface {
+	journaledTx(reg *ComponentRegistry, fn func(tx DataEntity) error) (*TxChange, error)
+}
+
+// observedTx runs fn inside ent.Tx and reports the changes to the
+// observers on ctx.
+func observedTx(ctx context.Context, ent DataEntity, fn func(tx DataEntity) error) error {
+	change, err := journalTx(ctx, ent, fn)
+	reportTx(ctx, change)
+	return err
+}
+
+// journalTx runs fn inside ent.Tx and returns what it changed without
+// reporting it. The change is nil if ctx has no observers or ent cannot be
+// observed.
+func journalTx(ctx context.Context, ent DataEntity, fn func(tx DataEntity) error) (*TxChange, error) {
+	obs, ok := ctx.Value(txObserversKey{}).(*txObservers)
+	je, journaled := ent.(journaledEntity)
+	if !ok || !journaled {
+		return nil, ent.Tx(fn)
+	}
+	return je.journaledTx(obs.registry, fn)
+}
+
+// reportTx hands change to the observers on ctx. A nil change is ignored.
+func reportTx(ctx context.Context, change *TxChange) {
+	if change == nil {
+		return
+	}
+	obs, ok := ctx.Value(txObserversKey{}).(*txObservers)
+	if !ok {
+		return
+	}
+	for _, o := range obs.observers {
+		o.ObserveTx(ctx, change)
+	}
+}
+
+// txJournal records the state a transaction touched before it first
+// touched it.
+type txJournal struct {
+	registry *ComponentRegistry
+
+	tagsSaved    bool
+	tagsBefore   []Tag
+	enabledSaved bool
+	enabled      bool
+
+	before map[ComponentType]*ComponentImage
+	order  []ComponentType
+	// broken holds types whose before image could not be encoded.
+	broken map[ComponentType]struct{}
+	errs   []error
+}
+
+func newTxJournal(reg *ComponentRegistry) *txJournal {
+	if reg == nil {
+		reg = DefaultComponentRegistry
+	}
+	return &txJournal{registry: reg}
+}
+
+func (j *txJournal) saveTags(e *DataEntityCore) {
+	if j.tagsSaved {
+		return
+	}
+	j.tagsSaved = true
+	j.tagsBefore = sortTags(e.tagsUnlocked())
+}
+
+func (j *txJournal) saveEnabled(e *DataEntityCore) {
+	if j.enabledSaved {
+		return
+	}
+	j.enabledSaved = true
+	j.enabled = e.enabledUnlocked()
+}
+
+func (j *txJournal) saveComponent(e *DataEntityCore, t ComponentType) {
+	if _, ok := j.before[t]; ok {
+		return
+	}
+	if j.before == nil {
+		j.before = make(map[ComponentType]*ComponentImage)
+	}
+	j.order = append(j.order, t)
+	c, ok := e.getComponentUnlocked(t)
+	if !ok {
+		j.before[t] = nil
+		return
+	}
+	img := j.image(c)
+	if img == nil {
+		if j.broken == nil {
+			j.broken = make(map[ComponentType]struct{})
+		}
+		j.broken[t] = struct{}{}
+	}
+	j.before[t] = img
+}
+
+// image encodes c, recording the error and returning nil on failure.
+func (j *txJournal) image(c Component) *ComponentImage {
+	payload, err := j.registry.EncodeComponent(c)
+	if err != nil {
+		j.errs = append(j.errs, fmt.Errorf("component %d: %w", c.ComponentType(), err))
+		return nil
+	}
+	img := &ComponentImage{Type: c.ComponentType(), Payload: payload, Enabled: c.Enabled(), Tags: sortTags(c.Tags())}
+	if dc, ok := c.(DataComponent); ok {
+		img.Version = dc.Version()
+	}
+	return img
+}
+
+// finish compares the saved state with the current state. It returns nil
+// if nothing changed.
+func (j *txJournal) finish(e *DataEntityCore, txErr error) *TxChange {
+	change := &TxChange{EntityId: e.id, EntityType: e.typ, Registry: j.registry, Err: txErr}
+	if j.tagsSaved {
+		after := sortTags(e.tagsUnlocked())
+		if !slices.Equal(j.tagsBefore, after) {
+			change.TagsChanged, change.TagsBefore, change.TagsAfter = true, j.tagsBefore, after
+		}
+	}
+	if j.enabledSaved && j.enabled != e.enabledUnlocked() {
+		change.EnabledChanged, change.EnabledBefore, change.EnabledAfter = true, j.enabled, !j.enabled
+	}
+	for _, t := range j.order {
+		if _, ok := j.broken[t]; ok {
+			continue
+		}
+		before := j.before[t]
+		var after *ComponentImage
+		if c, ok := e.getComponentUnlocked(t); ok {
+			if after = j.image(c); after == nil {
+				continue
+			}
+		}
+		if before.equal(after) {
+			continue
+		}
+		change.Components = append(change.Components, ComponentChange{Type: t, Before: before, After: after})
+	}
+	change.ImageErr = errors.Join(j.errs...)
+	if len(change.Components) == 0 && !change.TagsChanged && !change.EnabledChanged && change.ImageErr == nil {
+		return nil
+	}
+	return change
+}
+
+func sortTags(tags []Tag) []Tag {
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+	return tags
+}
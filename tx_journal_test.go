@@ -0,0 +1,78 @@
+package ginka_ecs_go
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type recordingTxObserver struct {
+	changes []*TxChange
+}
+
+func (o *recordingTxObserver) ObserveTx(ctx context.Context, change *TxChange) {
+	o.changes = append(o.changes, change)
+}
+
+func TestTxContext_ReportsChangesToObservers(t *testing.T) {
+	reg := NewComponentRegistry()
+	_ = reg.Register(ComponentSpec{Name: "label", Type: testLabelComponentType, New: func() Component { return newTestLabelComponent("") }})
+	obs := &recordingTxObserver{}
+	ctx := WithTxObserver(context.Background(), reg, obs)
+
+	ent := NewDataEntityCore("p1", "p1", 1, "player")
+	_ = ent.Add(newTestLabelComponent("hero"))
+
+	err := TxContext(ctx, ent, func(ctx context.Context, tx DataEntity) error {
+		label, _ := GetForUpdate[*testLabelComponent](tx, testLabelComponentType)
+		label.Label = "villain"
+		tx.AddTag("vip")
+		_ = tx.Add(newTestDataComponent())
+		tx.RemoveComponent(testDataComponentType)
+		return nil
+	})
+	if err != nil || len(obs.changes) != 1 {
+		t.Fatalf("err = %v, changes = %d", err, len(obs.changes))
+	}
+	change := obs.changes[0]
+	if change.EntityId != "p1" || !change.TagsChanged || strings.Join(tagStrings(change.TagsAfter), ",") != "player,vip" || change.EnabledChanged {
+		t.Fatalf("change = %+v", change)
+	}
+	if len(change.Components) != 1 {
+		t.Fatalf("components = %+v", change.Components)
+	}
+	cc := change.Components[0]
+	if cc.Added() || cc.Removed() || cc.Before.Version != 0 || cc.After.Version != 1 {
+		t.Fatalf("component change = %+v", cc)
+	}
+	before, err := cc.Before.Decode(change.Registry)
+	if err != nil || before.(*testLabelComponent).Label != "hero" {
+		t.Fatalf("before = %v, %v", before, err)
+	}
+
+	// Reads report nothing; a failed transaction reports what it changed.
+	_ = TxContext(ctx, ent, func(ctx context.Context, tx DataEntity) error {
+		tx.Get(testLabelComponentType)
+		return nil
+	})
+	boom := errors.New("boom")
+	err = TxContext(ctx, ent, func(ctx context.Context, tx DataEntity) error {
+		tx.SetEnabled(false)
+		return boom
+	})
+	if !errors.Is(err, boom) || len(obs.changes) != 2 {
+		t.Fatalf("err = %v, changes = %d", err, len(obs.changes))
+	}
+	if change := obs.changes[1]; !errors.Is(change.Err, boom) || !change.EnabledChanged || change.EnabledAfter {
+		t.Fatalf("failed change = %+v", change)
+	}
+}
+
+func tagStrings(tags []Tag) []string {
+	out := make([]string, len(tags))
+	for i, tag := range tags {
+		out[i] = string(tag)
+	}
+	return out
+}
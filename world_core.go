@@ -1,7 +1,10 @@
 package ginka_ecs_go
 
 import (
+	"context"
+	"log/slog"
 	"sync"
+	"time"
 )
 
 // CoreWorld manages world runtime lifecycle.
@@ -15,6 +18,12 @@ type CoreWorld struct {
 	stopOnce   sync.Once
 	stopChan   chan struct{}
 	stopAwait  chan struct{}
+
+	resources     *Resources
+	timers        *Timers
+	timerInterval time.Duration
+	metrics       Metrics
+	logger        *slog.Logger
 }
 
 // NewCoreWorld creates a new CoreWorld.
@@ -23,6 +32,8 @@ func NewCoreWorld(name string) *CoreWorld {
 		name:      name,
 		stopChan:  make(chan struct{}),
 		stopAwait: make(chan struct{}),
+		resources: NewResources(),
+		timers:    NewTimers(DefaultTimerResolution, time.Now()),
 	}
 	return w
 }
@@ -35,13 +46,24 @@ func (w *CoreWorld) Run() error {
 	}
 	w.running = true
 	w.started = true
+	metrics := metricsOrDefault(w.metrics)
+	metrics.SetGauge(MetricWorldRunning, 1, "world", w.name)
+	logger := loggerOrDefault(w.logger)
+	interval := w.timerInterval
 	w.mu.Unlock()
+	logger.LogAttrs(context.Background(), slog.LevelInfo, "world running", slog.String(LogKeyWorld, w.name))
 
-	<-w.stopChan
+	if interval > 0 {
+		w.driveTimers(interval, logger)
+	} else {
+		<-w.stopChan
+	}
 
 	w.mu.Lock()
 	w.running = false
+	metrics.SetGauge(MetricWorldRunning, 0, "world", w.name)
 	w.mu.Unlock()
+	logger.LogAttrs(context.Background(), slog.LevelInfo, "world stopped", slog.String(LogKeyWorld, w.name))
 
 	close(w.stopAwait)
 	return nil
@@ -52,6 +74,7 @@ func (w *CoreWorld) Stop() error {
 	started := w.started
 	running := w.running
 	stopAwait := w.stopAwait
+	logger := loggerOrDefault(w.logger)
 	w.mu.RUnlock()
 	if !started {
 		return nil
@@ -59,6 +82,7 @@ func (w *CoreWorld) Stop() error {
 
 	if running {
 		w.stopOnce.Do(func() {
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "world stopping", slog.String(LogKeyWorld, w.name))
 			close(w.stopChan)
 		})
 	}
@@ -66,6 +90,30 @@ func (w *CoreWorld) Stop() error {
 	return nil
 }
 
+// SetMetrics sets where the world reports its running state.
+// Nil uses DefaultMetrics.
+func (w *CoreWorld) SetMetrics(m Metrics) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.metrics = m
+}
+
+// SetLogger sets the logger for the world's lifecycle transitions.
+// Nil uses DefaultLogger.
+func (w *CoreWorld) SetLogger(l *slog.Logger) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.logger = l
+}
+
+// Logger returns the world's logger with the world attribute attached, for
+// systems that log on behalf of the world.
+func (w *CoreWorld) Logger() *slog.Logger {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return loggerOrDefault(w.logger).With(slog.String(LogKeyWorld, w.name))
+}
+
 func (w *CoreWorld) GetName() string {
 	return w.name
 }
@@ -88,4 +136,43 @@ func (w *CoreWorld) SetStopWeight(weight int64) {
 	w.stopWeight = weight
 }
 
+// driveTimers advances the world's timers to the current time every
+// interval until the world stops.
+func (w *CoreWorld) driveTimers(interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case now := <-ticker.C:
+			if _, err := w.timers.AdvanceTo(context.Background(), now); err != nil {
+				logger.LogAttrs(context.Background(), slog.LevelError, "timers failed", slog.String(LogKeyWorld, w.name), slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// SetTimerInterval sets how often Run advances the world's timers to the
+// current time; DefaultTimerResolution matches the wheel. The default, 0,
+// leaves them to the caller, which then calls Step or AdvanceTo itself, so
+// worlds without timers never wake. It takes effect on the next Run.
+func (w *CoreWorld) SetTimerInterval(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timerInterval = max(d, 0)
+}
+
+// Timers returns the world's timer wheel. Its ticks start when the world
+// is created and last DefaultTimerResolution.
+func (w *CoreWorld) Timers() *Timers {
+	return w.timers
+}
+
+// Resources returns the world's singleton resources.
+func (w *CoreWorld) Resources() *Resources {
+	return w.resources
+}
+
 var _ World = (*CoreWorld)(nil)
+var _ ResourceHolder = (*CoreWorld)(nil)